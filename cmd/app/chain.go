@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/app"
+	"github.com/lunn06/wallet/internal/config"
+)
+
+// errChainBroken makes process exit with non-zero code, report is already printed
+var errChainBroken = errors.New("chain is broken")
+
+// verifyChain walks transactions hash chain and writes report
+// to stdout in json. Broken chain is reported as error
+func verifyChain(cfg config.Config) error {
+	// only warnings go to stderr to keep stdout clean for report
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	// http server isn't started, so gin's route dump is just noise
+	gin.SetMode(gin.ReleaseMode)
+
+	provider, err := app.NewProvider(cfg, logger, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+		defer cancel()
+		_ = provider.Close(ctx)
+	}()
+
+	report, err := provider.Chain().Verify(context.Background())
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		return errChainBroken
+	}
+
+	return nil
+}
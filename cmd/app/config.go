@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lunn06/wallet/internal/config"
+)
+
+// printConfig writes effective config (file merged with environment)
+// to stdout in yaml with secrets redacted
+func printConfig(cfg config.Config) error {
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+
+	return encoder.Encode(config.Redacted(cfg))
+}
@@ -1,53 +1,72 @@
 // cmd/app is app entry point that init a logger,
 // read config startup and shutdown app
+//
+// Usage:
+//
+//	app [--config path] [command]
+//
+// Commands:
+//
+//	serve          start http server (default)
+//	config print   print effective config with secrets redacted
+//	chain verify   verify transactions hash chain, exit with 1 if it's broken
 package main
 
 import (
-	"context"
+	"flag"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/lunn06/wallet/internal/app"
 	"github.com/lunn06/wallet/internal/config"
-	"github.com/lunn06/wallet/pkg/zapslog"
 )
 
-func main() {
-	debug := os.Getenv("LOG_LEVEL") == "debug"
-	logger, sync := zapslog.Init(debug)
-	defer sync()
+const defaultConfigPath = "configs/main.yaml"
 
-	cfg, err := config.ReadConfig("configs/main.yaml")
-	if err != nil {
-		logger.Error("can't open config file", "err", err)
-		return
+func main() {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	configPath := flags.String(
+		"config",
+		envOr("CONFIG_PATH", defaultConfigPath),
+		"path to yaml config, empty value means config from environment only",
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [--config path] [serve | config print | chain verify]\n", os.Args[0])
+		flags.PrintDefaults()
 	}
+	_ = flags.Parse(os.Args[1:])
 
-	a, err := app.New(cfg, logger)
+	cfg, err := config.ReadConfig(*configPath)
 	if err != nil {
-		logger.Error("can't init app", "err", err)
-		return
+		fmt.Fprintf(os.Stderr, "can't read config %q: %v\n", *configPath, err)
+		os.Exit(1)
 	}
 
-	logger.Info("Starting app")
-	go func() {
-		if err := a.Run(); err != nil {
-			logger.Error("can't start app", "err", err)
-		}
-	}()
-
-	quit := make(chan os.Signal)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	<-quit // wait for SIGINT or SIGTERM signal
+	args := flags.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
 
-	logger.Info("Shutting down app")
+	switch {
+	case args[0] == "serve":
+		err = serve(cfg)
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		err = printConfig(cfg)
+	case len(args) == 2 && args[0] == "chain" && args[1] == "verify":
+		err = verifyChain(cfg)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
 
-	ctx := context.Background()
-	if err := a.Close(ctx); err != nil {
-		logger.Error("can't close app", "err", err)
-		return
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+}
 
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lunn06/wallet/internal/app"
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/tracing"
+	"github.com/lunn06/wallet/pkg/zapslog"
+)
+
+// serve starts app and blocks until SIGINT or SIGTERM
+func serve(cfg config.Config) error {
+	// level is changed at runtime by admin endpoint
+	level := new(slog.LevelVar)
+	if err := level.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		return err
+	}
+
+	logger, sync, err := zapslog.Init(zapslog.Config{
+		Level:   level,
+		Format:  cfg.Log.Format,
+		Outputs: cfg.Log.Outputs,
+		Rotation: zapslog.Rotation{
+			MaxSize: cfg.Log.Rotation.MaxSize,
+			// zero values keep rotated files forever in config
+			MaxAge:     cmp.Or(cfg.Log.Rotation.MaxAge, -1),
+			MaxBackups: cmp.Or(cfg.Log.Rotation.MaxBackups, -1),
+		},
+		Sampling: zapslog.Sampling{
+			Initial:    cfg.Log.Sampling.Initial,
+			Thereafter: cfg.Log.Sampling.Thereafter,
+			Tick:       cfg.Log.Sampling.Tick,
+		},
+		Redact: cfg.Log.Redact,
+	})
+	if err != nil {
+		return err
+	}
+	defer sync()
+
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
+	if err != nil {
+		logger.Error("can't init tracing", "err", err)
+		return err
+	}
+	// add trace and span ids of request to log records
+	logger = slog.New(tracing.NewLogHandler(logger.Handler()))
+
+	a, err := app.New(cfg, logger, level)
+	if err != nil {
+		logger.Error("can't init app", "err", err)
+		return err
+	}
+
+	logger.Info("Starting app")
+	go func() {
+		if err := a.Run(); err != nil {
+			logger.Error("can't start app", "err", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	<-quit // wait for SIGINT or SIGTERM signal
+
+	logger.Info("Shutting down app")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	defer cancel()
+
+	if err := a.Close(ctx); err != nil {
+		logger.Error("can't close app", "err", err)
+		return err
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("can't shutdown tracing", "err", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/usecase/admin"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// errMismatches makes process exit with non-zero code, report is already printed
+var errMismatches = errors.New("stored balances differ from ledger")
+
+// command runs subcommands via admin usecase and prints their results
+type command struct {
+	admin admin.Usecase
+	out   printer
+}
+
+func (c command) wallet(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch name, args := args[0], args[1:]; name {
+	case "create":
+		if len(args) > 1 {
+			return errUsage
+		}
+		address := ""
+		if len(args) == 1 {
+			address = args[0]
+		}
+		wallet, err := c.admin.CreateWallet(ctx, address)
+		if err != nil {
+			return err
+		}
+		return c.out.wallets(wallet)
+	case "list":
+		if len(args) != 0 {
+			return errUsage
+		}
+		wallets, err := c.admin.ListWallets(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.wallets(wallets...)
+	case "show":
+		if len(args) != 1 {
+			return errUsage
+		}
+		wallet, err := c.admin.GetWallet(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return c.out.wallets(wallet)
+	case "history":
+		return c.history(ctx, args)
+	case "freeze", "unfreeze":
+		if len(args) != 1 {
+			return errUsage
+		}
+		if err := c.admin.SetFrozen(ctx, args[0], name == "freeze"); err != nil {
+			return err
+		}
+		wallet, err := c.admin.GetWallet(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return c.out.wallets(wallet)
+	default:
+		return errUsage
+	}
+}
+
+func (c command) history(ctx context.Context, args []string) error {
+	var from, to timeFlag
+	fs := newFlagSet("wallet history")
+	fs.Var(&from, "from", "list transactions after this RFC 3339 time")
+	fs.Var(&to, "to", "list transactions up to this RFC 3339 time, now by default")
+
+	positional, err := parseInterleaved(fs, args)
+	if err != nil || len(positional) != 1 {
+		return errUsage
+	}
+
+	transactions, err := c.admin.History(ctx, dtos.HistoryRequest{
+		Address: positional[0],
+		From:    time.Time(from),
+		To:      time.Time(to),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.out.transactions(transactions)
+}
+
+func (c command) treasury(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch name, args := args[0], args[1:]; name {
+	case "mint", "burn":
+		fs := newFlagSet("treasury " + name)
+		reason := fs.String("reason", "", "reason of operation, required")
+
+		positional, err := parseInterleaved(fs, args)
+		if err != nil || len(positional) != 1 {
+			return errUsage
+		}
+
+		apply := c.admin.Mint
+		if name == "burn" {
+			apply = c.admin.Burn
+		}
+		operation, err := apply(ctx, dtos.TreasuryRequest{Amount: positional[0], Reason: *reason})
+		if err != nil {
+			return err
+		}
+		return c.out.treasuryOperations(operation)
+	case "history":
+		if len(args) != 0 {
+			return errUsage
+		}
+		operations, err := c.admin.TreasuryHistory(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.treasuryOperations(operations...)
+	default:
+		return errUsage
+	}
+}
+
+func (c command) reconcile(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	report, err := c.admin.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.out.reconcileReport(report); err != nil {
+		return err
+	}
+
+	if len(report.Mismatches) > 0 {
+		return errMismatches
+	}
+
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	// usage of whole CLI is printed instead
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseInterleaved parses flags placed before, between or after
+// positional arguments, flag package stops at the first positional one
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// timeFlag is flag.Value of RFC 3339 time
+type timeFlag time.Time
+
+func (t *timeFlag) String() string {
+	if t == nil || time.Time(*t).IsZero() {
+		return ""
+	}
+	return time.Time(*t).Format(time.RFC3339)
+}
+
+func (t *timeFlag) Set(value string) error {
+	parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	*t = timeFlag(parsed)
+	return nil
+}
@@ -0,0 +1,131 @@
+// cmd/walletctl is admin CLI, that works with storage configured
+// for app directly, so it doesn't need running http server
+//
+// Usage:
+//
+//	walletctl [--config path] [--output table|json] command [args]
+//
+// Commands:
+//
+//	wallet create [address]                          create wallet with zero balance, random address by default
+//	wallet list                                      list all wallets
+//	wallet show <address>                            show wallet balance
+//	wallet history <address> [--from t] [--to t]     list successful transactions, t is RFC 3339 time
+//	wallet freeze <address>                          forbid wallet to send and receive transfers
+//	wallet unfreeze <address>                        allow frozen wallet to send and receive transfers
+//	treasury mint <amount> --reason text             issue funds into treasury wallet
+//	treasury burn <amount> --reason text             retire funds from treasury wallet
+//	treasury history                                 list mint and burn operations
+//	reconcile                                        compare stored balances with ledger, exit with 1 on mismatches
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/app"
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+)
+
+const defaultConfigPath = "configs/main.yaml"
+
+// errUsage makes process exit with code 2 after usage is printed
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	configPath := flags.String(
+		"config",
+		envOr("CONFIG_PATH", defaultConfigPath),
+		"path to yaml config, empty value means config from environment only",
+	)
+	output := flags.String("output", "table", "output format, table or json")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [--config path] [--output table|json] <wallet | treasury | reconcile> ...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	out, err := newPrinter(*output, os.Stdout)
+	if err != nil || flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.ReadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't read config %q: %v\n", *configPath, err)
+		os.Exit(1)
+	}
+
+	err = run(cfg, out, flags.Args())
+	switch {
+	case errors.Is(err, errUsage):
+		flags.Usage()
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg config.Config, out printer, args []string) error {
+	// only warnings go to stderr to keep stdout clean for output
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	// http server isn't started, so gin's route dump is just noise
+	gin.SetMode(gin.ReleaseMode)
+
+	// memory storage is private to process, so changes wouldn't reach server
+	if cfg.Storage.Driver == config.DriverMemory {
+		return fmt.Errorf("walletctl can't use %q storage driver, its data isn't shared with server", config.DriverMemory)
+	}
+
+	provider, err := app.NewProvider(cfg, logger, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+		defer cancel()
+		_ = provider.Close(ctx)
+	}()
+
+	cmd := command{admin: provider.Admin(), out: out}
+	ctx := usecase.WithCaller(context.Background(), actor())
+
+	switch args[0] {
+	case "wallet":
+		return cmd.wallet(ctx, args[1:])
+	case "treasury":
+		return cmd.treasury(ctx, args[1:])
+	case "reconcile":
+		return cmd.reconcile(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+// actor identifies operator in audit records by name of os user
+func actor() string {
+	name := "unknown"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	return "walletctl:" + name
+}
+
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
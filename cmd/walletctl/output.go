@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// printer writes command results as aligned table or as json
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return printer{w: w}, nil
+	case "json":
+		return printer{w: w, json: true}, nil
+	default:
+		return printer{}, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func (p printer) wallets(wallets ...dtos.AdminWallet) error {
+	if p.json {
+		return p.encode(nonNil(wallets))
+	}
+
+	rows := make([][]string, 0, len(wallets))
+	for _, w := range wallets {
+		rows = append(rows, []string{strconv.Itoa(w.ID), w.Address, w.Balance, strconv.FormatBool(w.Frozen)})
+	}
+	return p.table([]string{"ID", "ADDRESS", "BALANCE", "FROZEN"}, rows)
+}
+
+func (p printer) transactions(transactions []dtos.Transaction) error {
+	if p.json {
+		return p.encode(nonNil(transactions))
+	}
+
+	rows := make([][]string, 0, len(transactions))
+	for _, t := range transactions {
+		rows = append(rows, []string{strconv.Itoa(t.ID), t.Timestamp.Format(time.RFC3339), t.FromAddress, t.ToAddress, t.Amount})
+	}
+	return p.table([]string{"ID", "TIMESTAMP", "FROM", "TO", "AMOUNT"}, rows)
+}
+
+func (p printer) treasuryOperations(operations ...dtos.TreasuryOperation) error {
+	if p.json {
+		return p.encode(nonNil(operations))
+	}
+
+	rows := make([][]string, 0, len(operations))
+	for _, o := range operations {
+		rows = append(rows, []string{strconv.Itoa(o.ID), o.Timestamp.Format(time.RFC3339), o.Kind, o.Amount, o.Balance, o.Reason})
+	}
+	return p.table([]string{"ID", "TIMESTAMP", "KIND", "AMOUNT", "BALANCE", "REASON"}, rows)
+}
+
+func (p printer) reconcileReport(report dtos.ReconcileReport) error {
+	if p.json {
+		return p.encode(report)
+	}
+
+	if len(report.Mismatches) == 0 {
+		_, err := fmt.Fprintf(p.w, "checked %d wallets, no mismatches\n", report.Checked)
+		return err
+	}
+
+	rows := make([][]string, 0, len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		rows = append(rows, []string{m.Address, m.Stored, m.Ledger, m.Error})
+	}
+	if err := p.table([]string{"ADDRESS", "STORED", "LEDGER", "ERROR"}, rows); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(p.w, "checked %d wallets, %d mismatches\n", report.Checked, len(report.Mismatches))
+	return err
+}
+
+// nonNil makes empty list encoded as [] instead of null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func (p printer) encode(v any) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (p printer) table(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/lunn06/wallet/internal/config"
 )
@@ -18,12 +19,15 @@ type App struct {
 	initializer Initializer
 
 	controller Controller
+	// level of logger, that is changed at runtime by admin endpoint
+	level *slog.LevelVar
 }
 
-func New(config config.Config, logger *slog.Logger) (*App, error) {
+func New(config config.Config, logger *slog.Logger, level *slog.LevelVar) (*App, error) {
 	app := App{
 		config: config,
 		logger: logger,
+		level:  level,
 	}
 
 	if err := app.init(); err != nil {
@@ -34,6 +38,8 @@ func New(config config.Config, logger *slog.Logger) (*App, error) {
 }
 
 func (app *App) Run() error {
+	app.provider.StartWorkers()
+
 	if err := app.controller.Run(); err != nil {
 		return err
 	}
@@ -44,6 +50,16 @@ func (app *App) Run() error {
 // Close method initiate graceful shutdown for all
 // components via Provider
 func (app *App) Close(ctx context.Context) error {
+	// report not ready first and give load balancers
+	// time to notice it and stop sending new requests
+	app.provider.probe.Shutdown()
+
+	select {
+	case <-time.After(app.config.Shutdown.DrainDelay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
 	err := app.provider.Close(ctx)
 	if err != nil {
 		return err
@@ -73,7 +89,7 @@ func (app *App) init() error {
 }
 
 func (app *App) initProvider() error {
-	provider, err := NewProvider(app.config, app.logger)
+	provider, err := NewProvider(app.config, app.logger, app.level)
 	if err != nil {
 		return err
 	}
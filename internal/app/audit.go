@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/pkg/zapslog"
+)
+
+// closerFunc adapts function to Closer
+type closerFunc func(ctx context.Context) error
+
+func (f closerFunc) Close(ctx context.Context) error {
+	return f(ctx)
+}
+
+// newAuditSink creates sink mirroring audit records to rotating file,
+// sink is nil if file isn't configured
+func newAuditSink(cfg config.Audit) (*slog.Logger, Closer) {
+	if cfg.File == "" {
+		return nil, closerFunc(func(context.Context) error { return nil })
+	}
+
+	rotation := zapslog.Rotation{
+		MaxSize: cfg.MaxSize,
+		MaxAge:  cfg.MaxAge,
+		// audit files are only removed by age
+		MaxBackups: -1,
+	}
+	// zero age keeps files forever
+	if rotation.MaxAge == 0 {
+		rotation.MaxAge = -1
+	}
+
+	sink, closeSink := zapslog.NewFileSink(cfg.File, rotation)
+
+	return sink, closerFunc(func(context.Context) error { return closeSink() })
+}
@@ -3,14 +3,20 @@ package app
 import (
 	"context"
 	"log/slog"
-	"strconv"
 
 	"github.com/lunn06/wallet/internal/config"
 	gincontroller "github.com/lunn06/wallet/internal/delivery/gin"
+	"github.com/lunn06/wallet/internal/domain/usecase/admin"
+	"github.com/lunn06/wallet/internal/domain/usecase/audit"
+	"github.com/lunn06/wallet/internal/domain/usecase/chain"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/domain/usecase/statement"
 	"github.com/lunn06/wallet/internal/domain/usecase/transation"
 	"github.com/lunn06/wallet/internal/domain/usecase/wallet"
-	"github.com/lunn06/wallet/internal/storage/pgx"
-	"github.com/lunn06/wallet/internal/utils/pgsql"
+	"github.com/lunn06/wallet/internal/health"
+	"github.com/lunn06/wallet/internal/metrics"
+	"github.com/lunn06/wallet/internal/worker"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 // Provider is DI container that initialize
@@ -19,45 +25,117 @@ type Provider struct {
 	logger      *slog.Logger
 	controller  Controller
 	initializer Initializer
+	probe       *health.Probe
 	graceful    *Graceful
+	workers     []*worker.Worker
+	chainUc     chain.Usecase
+	adminUc     admin.Usecase
 }
 
-func NewProvider(cfg config.Config, logger *slog.Logger) (*Provider, error) {
-	dns := pgsql.BuildDns(
-		cfg.Database.Host,
-		strconv.Itoa(int(cfg.Database.Port)),
-		cfg.Database.SSLMode,
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Name,
-	)
-	storage, err := pgx.NewStorage(dns, logger)
+// NewProvider creates components of app. Level is level of logger, that is
+// changed at runtime by admin endpoint, nil means level of logger is fixed
+func NewProvider(cfg config.Config, logger *slog.Logger, level *slog.LevelVar) (*Provider, error) {
+	// CLI commands don't serve http, so their level is never changed
+	if level == nil {
+		level = new(slog.LevelVar)
+	}
+
+	currency, err := money.NewCurrency(cfg.Transfer.Currency, cfg.Transfer.MaxScale)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := signingKey(cfg.Chain)
 	if err != nil {
 		return nil, err
 	}
-	walletStorage := pgx.WalletStorage{storage}
-	transactionStorage := pgx.TransactionStorage{storage}
 
-	walletUc := wallet.NewUsecase(walletStorage, logger)
-	transactionUc := transation.NewUsecase(transactionStorage, walletStorage)
+	storages, err := NewStorages(cfg, currency, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// storages are opened, so they are closed on failure of the rest
+	appMetrics := metrics.New()
+	if err := appMetrics.Register(storages.Collectors...); err != nil {
+		storages.Closer.Close(context.Background())
+		return nil, err
+	}
+
+	probe := health.NewProbe(logger)
+	for name, check := range storages.Checks {
+		probe.AddCheck(name, check)
+	}
+
+	auditSink, closeAuditSink := newAuditSink(cfg.Audit)
+	auditUc := audit.NewUsecase(storages.Audit, auditSink, logger)
+
+	snapshotUc := snapshot.NewUsecase(storages.Wallet, storages.Transaction, storages.Snapshot, logger)
+	walletUc := wallet.NewUsecase(storages.Wallet, snapshotUc, auditUc, currency, logger)
+	transactionUc := transation.NewUsecase(storages.Transaction, storages.Wallet, appMetrics, auditUc, currency)
+	statementUc := statement.NewUsecase(storages.Transaction, snapshotUc, logger)
+
+	chainUc := chain.NewUsecase(storages.Transaction, storages.Checkpoint, key, logger)
+	adminUc := admin.NewUsecase(storages.Wallet, storages.Transaction, storages.Treasury, snapshotUc, auditUc, currency, logger)
 
 	controller := gincontroller.New(
 		cfg,
 		logger,
+		appMetrics,
+		probe,
 		walletUc,
 		transactionUc,
+		statementUc,
+		chainUc,
+		auditUc,
+		level,
 	)
 
-	graceful := NewGraceful(controller, storage)
+	snapshotWorker := newSnapshotWorker(cfg.Snapshot, snapshotUc, appMetrics, logger)
+	probe.AddReporter("snapshot", snapshotWorker.Report)
+
+	workers := []*worker.Worker{snapshotWorker}
+	closers := []Closer{controller, snapshotWorker, storages.Closer, closeAuditSink}
+
+	// checkpoints can't be signed without key
+	if key != nil {
+		checkpointWorker := newCheckpointWorker(cfg.Chain, chainUc, appMetrics, logger)
+		probe.AddReporter("chain_checkpoint", checkpointWorker.Report)
+		workers = append(workers, checkpointWorker)
+		closers = append(closers, checkpointWorker)
+	}
+
+	graceful := NewGraceful(closers...)
 
 	return &Provider{
-		logger,
-		controller,
-		&walletUc,
-		graceful,
+		logger:      logger,
+		controller:  controller,
+		initializer: seeder{cfg: cfg.Seed, walletUc: walletUc, logger: logger},
+		probe:       probe,
+		graceful:    graceful,
+		workers:     workers,
+		chainUc:     chainUc,
+		adminUc:     adminUc,
 	}, nil
 }
 
+// Chain returns usecase of transactions hash chain, e.g. for verification from CLI
+func (p *Provider) Chain() chain.Usecase {
+	return p.chainUc
+}
+
+// Admin returns usecase of operator actions, e.g. for walletctl
+func (p *Provider) Admin() admin.Usecase {
+	return p.adminUc
+}
+
+// StartWorkers runs background workers, they are stopped on Close
+func (p *Provider) StartWorkers() {
+	for _, w := range p.workers {
+		w.Start()
+	}
+}
+
 func (p *Provider) Close(ctx context.Context) error {
 	if err := p.graceful.Shutdown(ctx); err != nil {
 		p.logger.Info("failed to shutdown gracefully")
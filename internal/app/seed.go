@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/domain/usecase/wallet"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// seeder implements Initializer, that seeds wallets on startup as configured
+type seeder struct {
+	cfg      config.Seed
+	walletUc wallet.Usecase
+	logger   *slog.Logger
+}
+
+// seedFile is format of seed file, yaml or json
+type seedFile struct {
+	Wallets []dtos.SeedWallet `yaml:"wallets"`
+}
+
+// seedActor identifies seeder in audit log
+const seedActor = "system:seed"
+
+func (s seeder) Initialize(ctx context.Context) error {
+	ctx = usecase.WithCaller(ctx, seedActor)
+
+	switch s.cfg.Mode {
+	case config.SeedFile:
+		return s.seedFromFile(ctx)
+	case config.SeedDemo:
+		return s.seedDemo(ctx)
+	default:
+		return nil
+	}
+}
+
+func (s seeder) seedFromFile(ctx context.Context) error {
+	content, err := os.ReadFile(s.cfg.File)
+	if err != nil {
+		return fmt.Errorf("can't read seed file: %w", err)
+	}
+
+	var seed seedFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// typo in field name must not silently seed zero balance
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&seed); err != nil {
+		return fmt.Errorf("can't parse seed file %q: %w", s.cfg.File, err)
+	}
+
+	if err := s.walletUc.Seed(ctx, seed.Wallets, s.cfg.OverwriteBalances); err != nil {
+		return err
+	}
+
+	s.logger.Info("Wallets seeded", "file", s.cfg.File, "count", len(seed.Wallets))
+
+	return nil
+}
+
+// seedDemo creates demo wallets once. Output of previous run means they already
+// exist, unless some of them are missing, e.g. after restart over memory storage
+func (s seeder) seedDemo(ctx context.Context) error {
+	seeded, err := s.demoSeeded(ctx)
+	if err != nil {
+		return err
+	}
+	if seeded {
+		s.logger.Info("Demo wallets already seeded", "file", s.cfg.DemoOutput)
+		return nil
+	}
+
+	wallets, err := s.walletUc.SeedDemo(ctx, s.cfg.DemoCount, s.cfg.DemoBalance)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	for _, w := range wallets {
+		fmt.Fprintln(&output, w.Address)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.cfg.DemoOutput), 0o755); err != nil {
+		return fmt.Errorf("can't create directory of demo output: %w", err)
+	}
+	if err := os.WriteFile(s.cfg.DemoOutput, output.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("can't write demo output: %w", err)
+	}
+
+	s.logger.Info("Demo wallets addresses written", "file", s.cfg.DemoOutput, "count", len(wallets))
+
+	return nil
+}
+
+// demoSeeded reports whether every wallet listed in demo output is stored
+func (s seeder) demoSeeded(ctx context.Context) (bool, error) {
+	content, err := os.ReadFile(s.cfg.DemoOutput)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can't read demo output: %w", err)
+	}
+
+	addresses := strings.Fields(string(content))
+	missing, err := s.walletUc.MissingWallets(ctx, addresses)
+	if err != nil {
+		return false, err
+	}
+	if len(missing) > 0 {
+		s.logger.Warn("Demo wallets are missing, they are seeded again",
+			"file", s.cfg.DemoOutput, "missing", len(missing), "listed", len(addresses))
+		return false, nil
+	}
+
+	return len(addresses) > 0, nil
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/health"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/internal/storage/memory"
+	"github.com/lunn06/wallet/internal/storage/pgx"
+	"github.com/lunn06/wallet/internal/storage/sqlite"
+	"github.com/lunn06/wallet/internal/utils/pgsql"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// Storages contains concrete storages of driver
+// selected in config and their infrastructure hooks
+type Storages struct {
+	Wallet      storageLayer.WalletStorage
+	Transaction storageLayer.TransactionStorage
+	Snapshot    storageLayer.SnapshotStorage
+	Checkpoint  storageLayer.CheckpointStorage
+	Treasury    storageLayer.TreasuryStorage
+	Audit       storageLayer.AuditStorage
+
+	Closer     Closer
+	Checks     map[string]health.Check
+	Collectors []prometheus.Collector
+}
+
+// NewStorages creates storages by cfg.Storage.Driver, balances of them are in currency
+func NewStorages(cfg config.Config, currency money.Currency, logger *slog.Logger) (Storages, error) {
+	switch cfg.Storage.Driver {
+	case config.DriverPgx:
+		return newPgxStorages(cfg.Database, currency, logger)
+	case config.DriverSQLite:
+		return newSQLiteStorages(cfg.SQLite, currency, logger)
+	case config.DriverMemory:
+		return newMemoryStorages(currency, logger), nil
+	default:
+		return Storages{}, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+func newPgxStorages(cfg config.Database, currency money.Currency, logger *slog.Logger) (Storages, error) {
+	dns := pgsql.BuildDns(
+		cfg.Host,
+		strconv.Itoa(int(cfg.Port)),
+		cfg.SSLMode,
+		cfg.User,
+		cfg.Password,
+		cfg.Name,
+	)
+	storage, err := pgx.NewStorage(dns, pgx.PoolConfig{
+		MaxConns:        cfg.Pool.MaxConns,
+		MinConns:        cfg.Pool.MinConns,
+		MaxConnLifetime: cfg.Pool.MaxConnLifetime,
+		MaxConnIdleTime: cfg.Pool.MaxConnIdleTime,
+		AcquireTimeout:  cfg.Pool.AcquireTimeout,
+	}, pgx.ReplicaConfig{
+		DSNs:          cfg.Replicas,
+		CheckInterval: cfg.ReplicaCheckInterval,
+	}, currency, logger)
+	if err != nil {
+		return Storages{}, err
+	}
+	if err := storage.Migrate(context.Background()); err != nil {
+		storage.Close(context.Background())
+		return Storages{}, err
+	}
+
+	return Storages{
+		Wallet:      pgx.WalletStorage{Storage: storage},
+		Transaction: pgx.TransactionStorage{Storage: storage},
+		Snapshot:    pgx.SnapshotStorage{Storage: storage},
+		Checkpoint:  pgx.CheckpointStorage{Storage: storage},
+		Treasury:    pgx.TreasuryStorage{Storage: storage},
+		Audit:       pgx.AuditStorage{Storage: storage},
+		Closer:      storage,
+		Checks: map[string]health.Check{
+			"database":   storage.Ping,
+			"migrations": storage.CheckMigrations,
+		},
+		Collectors: []prometheus.Collector{storage},
+	}, nil
+}
+
+func newSQLiteStorages(cfg config.SQLite, currency money.Currency, logger *slog.Logger) (Storages, error) {
+	storage, err := sqlite.NewStorage(cfg.Path, cfg.BusyTimeout, currency, logger)
+	if err != nil {
+		return Storages{}, err
+	}
+	if err := storage.Migrate(context.Background()); err != nil {
+		storage.Close(context.Background())
+		return Storages{}, err
+	}
+
+	return Storages{
+		Wallet:      sqlite.WalletStorage{Storage: storage},
+		Transaction: sqlite.TransactionStorage{Storage: storage},
+		Snapshot:    sqlite.SnapshotStorage{Storage: storage},
+		Checkpoint:  sqlite.CheckpointStorage{Storage: storage},
+		Treasury:    sqlite.TreasuryStorage{Storage: storage},
+		Audit:       sqlite.AuditStorage{Storage: storage},
+		Closer:      storage,
+		Checks: map[string]health.Check{
+			"database":   storage.Ping,
+			"migrations": storage.CheckMigrations,
+		},
+		Collectors: []prometheus.Collector{storage},
+	}, nil
+}
+
+func newMemoryStorages(currency money.Currency, logger *slog.Logger) Storages {
+	storage := memory.NewStorage(currency)
+
+	logger.Warn("MemoryStorage created, data will be lost on shutdown")
+
+	return Storages{
+		Wallet:      memory.WalletStorage{Storage: storage},
+		Transaction: memory.TransactionStorage{Storage: storage},
+		Snapshot:    memory.SnapshotStorage{Storage: storage},
+		Checkpoint:  memory.CheckpointStorage{Storage: storage},
+		Treasury:    memory.TreasuryStorage{Storage: storage},
+		Audit:       memory.AuditStorage{Storage: storage},
+		Closer:      storage,
+		Checks: map[string]health.Check{
+			"database": storage.Ping,
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/domain/usecase/chain"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/worker"
+)
+
+// newSnapshotWorker creates worker, that periodically snapshots
+// balances as of settle delay ago and prunes outdated snapshots
+func newSnapshotWorker(cfg config.Snapshot, snapshotUc snapshot.Usecase, observer worker.Observer, logger *slog.Logger) *worker.Worker {
+	job := func(ctx context.Context) error {
+		now := time.Now()
+
+		taken, err := snapshotUc.TakeSnapshots(ctx, now.Add(-cfg.SettleDelay))
+		if err != nil {
+			return err
+		}
+
+		deleted := 0
+		if cfg.Retention > 0 {
+			deleted, err = snapshotUc.Prune(ctx, now.Add(-cfg.Retention))
+			if err != nil {
+				return err
+			}
+		}
+
+		logger.Info("Snapshots taken", "taken", taken, "deleted", deleted)
+
+		return nil
+	}
+
+	return worker.New("snapshot", cfg.Interval, job, observer, logger)
+}
+
+// newCheckpointWorker creates worker, that periodically signs head of transactions hash chain
+func newCheckpointWorker(cfg config.Chain, chainUc chain.Usecase, observer worker.Observer, logger *slog.Logger) *worker.Worker {
+	job := func(ctx context.Context) error {
+		taken, err := chainUc.Checkpoint(ctx)
+		if err != nil {
+			return err
+		}
+
+		if taken {
+			logger.Info("Chain checkpoint taken")
+		}
+
+		return nil
+	}
+
+	return worker.New("chain_checkpoint", cfg.CheckpointInterval, job, observer, logger)
+}
+
+// signingKey decodes chain signing key, empty key means that checkpoints are disabled
+func signingKey(cfg config.Chain) (ed25519.PrivateKey, error) {
+	if cfg.SigningKey == "" {
+		return nil, nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.SigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain signing key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid chain signing key: expected %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -2,38 +2,263 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
-// Config and inner structs describe yaml config field
+// Config and inner structs describe yaml config field.
+// Every field can be overridden by environment variable from env tag
 type Config struct {
 	HTTPServer `yaml:"http_server"`
+	Storage    `yaml:"storage"`
 	Database   `yaml:"database"`
+	SQLite     `yaml:"sqlite"`
+	Snapshot   `yaml:"snapshot"`
+	Statement  `yaml:"statement"`
+	Transfer   `yaml:"transfer"`
+	Chain      `yaml:"chain"`
+	Admin      `yaml:"admin"`
+	Audit      `yaml:"audit"`
+	Metrics    `yaml:"metrics"`
+	Seed       `yaml:"seed"`
+	Tracing    `yaml:"tracing"`
+	Log        `yaml:"log"`
+	Shutdown   `yaml:"shutdown"`
 }
 
+// HTTPServer describes listener of http server. Address is host to bind,
+// e.g. 0.0.0.0 for all interfaces. ReadHeaderTimeout bounds reading of
+// headers against slow clients, MaxHeaderBytes and MaxBodyBytes bound
+// sizes of request headers and body. Client ip is taken from X-Forwarded-For
+// only for requests from TrustedProxies, IPs or CIDRs, none are trusted by default
 type HTTPServer struct {
-	Address string `yaml:"address" env-default:"127.0.0.1"`
-	Port    string `yaml:"port" env-default:"8080"`
+	Address           string        `yaml:"address" env:"HTTP_ADDRESS" env-default:"127.0.0.1" validate:"required"`
+	Port              string        `yaml:"port" env:"HTTP_PORT" env-default:"8080" validate:"required,numeric"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" env-default:"10s" validate:"gt=0"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" env-default:"5s" validate:"gt=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" env-default:"10s" validate:"gt=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s" validate:"gt=0"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES" env-default:"1048576" validate:"gt=0"`
+	MaxBodyBytes      int64         `yaml:"max_body_bytes" env:"HTTP_MAX_BODY_BYTES" env-default:"1048576" validate:"gt=0"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" env-separator:"," validate:"dive,ip|cidr"`
+	TLS               TLS           `yaml:"tls"`
+}
+
+// TLS enables https with CertFile and KeyFile, files are reloaded when they
+// change. ClientCAFile enables mutual TLS: clients must present certificate
+// signed by one of its CAs, and subject of certificate identifies caller
+type TLS struct {
+	CertFile       string        `yaml:"cert_file" env:"HTTP_TLS_CERT_FILE" validate:"required_with=KeyFile"`
+	KeyFile        string        `yaml:"key_file" env:"HTTP_TLS_KEY_FILE" validate:"required_with=CertFile"`
+	ClientCAFile   string        `yaml:"client_ca_file" env:"HTTP_TLS_CLIENT_CA_FILE" validate:"excluded_without=CertFile"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"HTTP_TLS_RELOAD_INTERVAL" env-default:"10s" validate:"gt=0"`
+}
+
+// Storage drivers, memory keeps all data in process
+// memory and loses it on restart, so it's only for demos and tests
+const (
+	DriverPgx    = "pgx"
+	DriverSQLite = "sqlite"
+	DriverMemory = "memory"
+)
+
+// Storage selects storage backend. Database section is only used
+// and validated with pgx driver, SQLite section with sqlite one
+type Storage struct {
+	Driver string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"pgx" validate:"oneof=pgx sqlite memory"`
 }
 
 type Database struct {
-	Host     string `yaml:"host"`
-	Port     uint   `yaml:"port"`
-	User     string `yaml:"user"`
-	Name     string `yaml:"name"`
-	Password string `yaml:"password"`
-	SSLMode  string `yaml:"ssl_mode"`
+	Host     string `yaml:"host" env:"DB_HOST" validate:"required"`
+	Port     uint   `yaml:"port" env:"DB_PORT" env-default:"5432" validate:"required,lte=65535"`
+	User     string `yaml:"user" env:"DB_USER" validate:"required"`
+	Name     string `yaml:"name" env:"DB_NAME" validate:"required"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSL_MODE" env-default:"disable" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	Pool     Pool   `yaml:"pool"`
+
+	// Replicas are DSNs of read-only streaming replicas, balance
+	// and history reads are routed to them while they are healthy
+	Replicas             []string      `yaml:"replicas" env:"DB_REPLICAS" env-separator:"," secret:"true"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL" env-default:"5s" validate:"gt=0"`
+}
+
+// SQLite describes database file of sqlite driver. BusyTimeout
+// bounds waiting for write lock held by concurrent transaction
+type SQLite struct {
+	Path        string        `yaml:"path" env:"SQLITE_PATH" env-default:"data/wallet.db" validate:"required"`
+	BusyTimeout time.Duration `yaml:"busy_timeout" env:"SQLITE_BUSY_TIMEOUT" env-default:"5s" validate:"gt=0"`
+}
+
+// Pool describes database connection pool. Zero values mean pgxpool
+// defaults, zero MaxConns means the greater of 4 and count of CPUs.
+// AcquireTimeout bounds waiting for free connection, in addition to request deadline
+type Pool struct {
+	MaxConns        int32         `yaml:"max_conns" env:"DB_POOL_MAX_CONNS" validate:"gte=0"`
+	MinConns        int32         `yaml:"min_conns" env:"DB_POOL_MIN_CONNS" validate:"gte=0"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"DB_POOL_MAX_CONN_LIFETIME" env-default:"1h" validate:"gte=0"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" env:"DB_POOL_MAX_CONN_IDLE_TIME" env-default:"30m" validate:"gte=0"`
+	AcquireTimeout  time.Duration `yaml:"acquire_timeout" env:"DB_POOL_ACQUIRE_TIMEOUT" env-default:"5s" validate:"gte=0"`
+}
+
+// Snapshot describes periodic wallet balance snapshots. Balance is
+// snapshotted as of SettleDelay ago to include slowly committed transfers.
+// Snapshots older than Retention are deleted, zero Retention keeps them forever
+type Snapshot struct {
+	Interval    time.Duration `yaml:"interval" env:"SNAPSHOT_INTERVAL" env-default:"1h" validate:"gt=0"`
+	SettleDelay time.Duration `yaml:"settle_delay" env:"SNAPSHOT_SETTLE_DELAY" env-default:"1m" validate:"gte=0"`
+	Retention   time.Duration `yaml:"retention" env:"SNAPSHOT_RETENTION" env-default:"2160h" validate:"gte=0"`
+}
+
+// Statement limits downloads of statements, each of them holds database
+// connection until the last row is sent. Downloads over MaxConcurrent are
+// rejected as unavailable, Timeout bounds the whole download
+type Statement struct {
+	MaxConcurrent int           `yaml:"max_concurrent" env:"STATEMENT_MAX_CONCURRENT" env-default:"4" validate:"gt=0"`
+	Timeout       time.Duration `yaml:"timeout" env:"STATEMENT_TIMEOUT" env-default:"5m" validate:"gt=0"`
+}
+
+// Transfer describes wallet currency and limits amount of single transfer.
+// MaxScale is count of minor unit digits of Currency, amounts of transfers,
+// treasury operations and seeded balances with more decimal places are
+// rejected. MaxAmount is inclusive upper bound of transfer, positive decimal
+type Transfer struct {
+	Currency  string `yaml:"currency" env:"TRANSFER_CURRENCY" env-default:"USD" validate:"iso4217"`
+	MaxScale  int32  `yaml:"max_scale" env:"TRANSFER_MAX_SCALE" env-default:"2" validate:"gte=0,lte=18"`
+	MaxAmount string `yaml:"max_amount" env:"TRANSFER_MAX_AMOUNT" env-default:"1000000000" validate:"positive_decimal"`
+}
+
+// Chain describes signed checkpoints of transactions hash chain. SigningKey
+// is base64 encoded ed25519 seed, checkpoints are neither taken nor verified without it
+type Chain struct {
+	SigningKey         string        `yaml:"signing_key" env:"CHAIN_SIGNING_KEY" secret:"true" validate:"omitempty,ed25519_seed"`
+	CheckpointInterval time.Duration `yaml:"checkpoint_interval" env:"CHAIN_CHECKPOINT_INTERVAL" env-default:"1h" validate:"gt=0"`
+}
+
+// Admin describes admin endpoints, that require Token as bearer token in
+// Authorization header or mutual TLS client certificate with one of
+// ClientSubjects (e.g. "CN=ops,O=Wallet"). Admin endpoints are disabled without both.
+// Clients with AuditorSubjects may only query audit log
+type Admin struct {
+	Token           string   `yaml:"token" env:"ADMIN_TOKEN" secret:"true"`
+	ClientSubjects  []string `yaml:"client_subjects" env:"ADMIN_CLIENT_SUBJECTS" env-separator:";"`
+	AuditorSubjects []string `yaml:"auditor_subjects" env:"ADMIN_AUDITOR_SUBJECTS" env-separator:";"`
+}
+
+// Metrics describes /metrics endpoint, which is public unless Token
+// is set. Then Token is required as bearer token in Authorization header
+type Metrics struct {
+	Token string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
 }
 
-// ReadConfig parse config from path
+// Audit describes audit log of state-changing actions. Records are stored in
+// database and mirrored as json lines to File, if it's set. File is rotated at
+// MaxSize megabytes, rotated files are kept for MaxAge days, zero keeps them forever
+type Audit struct {
+	File    string `yaml:"file" env:"AUDIT_FILE"`
+	MaxSize int    `yaml:"max_size" env:"AUDIT_MAX_SIZE" env-default:"100" validate:"gt=0"`
+	MaxAge  int    `yaml:"max_age" env:"AUDIT_MAX_AGE" env-default:"365" validate:"gte=0"`
+}
+
+// Seeding modes of wallets on startup
+const (
+	SeedNone = "none"
+	SeedFile = "file"
+	SeedDemo = "demo"
+)
+
+// Seed describes wallets created on startup. File mode inserts missing wallets
+// listed in File by address and keeps existing ones, so it is safe on every start.
+// With OverwriteBalances it upserts them by address, setting balances of existing
+// wallets to listed ones on every start, that undoes their transfers since then.
+// Demo mode creates DemoCount new random wallets with DemoBalance and writes their
+// addresses to DemoOutput, it is skipped when all wallets listed in DemoOutput exist
+type Seed struct {
+	Mode              string `yaml:"mode" env:"SEED_MODE" env-default:"none" validate:"oneof=none file demo"`
+	File              string `yaml:"file" env:"SEED_FILE" validate:"required_if=Mode file"`
+	OverwriteBalances bool   `yaml:"overwrite_balances" env:"SEED_OVERWRITE_BALANCES"`
+	DemoCount         int    `yaml:"demo_count" env:"SEED_DEMO_COUNT" env-default:"10" validate:"gt=0"`
+	DemoBalance       string `yaml:"demo_balance" env:"SEED_DEMO_BALANCE" env-default:"100" validate:"numeric"`
+	DemoOutput        string `yaml:"demo_output" env:"SEED_DEMO_OUTPUT" env-default:"data/demo-wallets.txt" validate:"required_if=Mode demo"`
+}
+
+// Tracing describes export of OpenTelemetry spans.
+// Exporter is one of "none", "otlp", "stdout" or "file"
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none" validate:"oneof=none otlp stdout file"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" env-default:"localhost:4318" validate:"required_if=Exporter otlp"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	FilePath    string  `yaml:"file_path" env:"TRACING_FILE_PATH" env-default:"logs/traces.json" validate:"required_if=Exporter file"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"wallet-backend" validate:"required"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1" validate:"gte=0,lte=1"`
+}
+
+// Log describes app logger. Level is initial level, it can be changed at
+// runtime by admin endpoint. Outputs are "stdout", "stderr" or paths of files,
+// Redact masks wallet addresses and credentials in records
+type Log struct {
+	Level    string      `yaml:"level" env:"LOG_LEVEL" env-default:"info" validate:"oneof=debug info warn error"`
+	Format   string      `yaml:"format" env:"LOG_FORMAT" env-default:"json" validate:"oneof=json console"`
+	Outputs  []string    `yaml:"outputs" env:"LOG_OUTPUTS" env-separator:"," env-default:"logs/app.log" validate:"min=1,dive,required"`
+	Redact   bool        `yaml:"redact" env:"LOG_REDACT" env-default:"true"`
+	Rotation LogRotation `yaml:"rotation"`
+	Sampling LogSampling `yaml:"sampling"`
+	Requests RequestLog  `yaml:"requests"`
+}
+
+// LogRotation describes rotation of log files. File is rotated at MaxSize
+// megabytes, MaxBackups rotated files are kept for MaxAge days, zero keeps them forever
+type LogRotation struct {
+	MaxSize    int `yaml:"max_size" env:"LOG_ROTATION_MAX_SIZE" env-default:"10" validate:"gt=0"`
+	MaxAge     int `yaml:"max_age" env:"LOG_ROTATION_MAX_AGE" env-default:"7" validate:"gte=0"`
+	MaxBackups int `yaml:"max_backups" env:"LOG_ROTATION_MAX_BACKUPS" env-default:"3" validate:"gte=0"`
+}
+
+// LogSampling limits records with the same level and message per Tick:
+// the first Initial ones are logged, then every Thereafter one.
+// Zero Initial disables sampling, zero Thereafter drops the rest
+type LogSampling struct {
+	Initial    int           `yaml:"initial" env:"LOG_SAMPLING_INITIAL" validate:"gte=0"`
+	Thereafter int           `yaml:"thereafter" env:"LOG_SAMPLING_THEREAFTER" validate:"gte=0"`
+	Tick       time.Duration `yaml:"tick" env:"LOG_SAMPLING_TICK" env-default:"1s" validate:"gt=0"`
+}
+
+// RequestLog describes log of served requests. Level is level of successful
+// requests, e.g. debug hides them from logger with info level. Successful
+// requests to SkipPaths (e.g. probes and metrics) aren't logged at all
+type RequestLog struct {
+	Level     string   `yaml:"level" env:"LOG_REQUESTS_LEVEL" env-default:"info" validate:"oneof=debug info warn error"`
+	SkipPaths []string `yaml:"skip_paths" env:"LOG_REQUESTS_SKIP_PATHS" env-separator:"," env-default:"/healthz,/readyz,/metrics"`
+}
+
+// Shutdown describes graceful shutdown. DrainDelay is time between
+// readiness probe turning to not ready and closing of http server
+type Shutdown struct {
+	Timeout    time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT" env-default:"15s" validate:"gt=0"`
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY" validate:"gte=0,ltfield=Timeout"`
+}
+
+// ReadConfig parse config from path, overrides it with environment
+// variables and validates result. Empty path means config from environment only
 func ReadConfig(configPath string) (Config, error) {
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return Config{}, err
-	}
 	var cfg Config
 
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+	if configPath == "" {
+		if err := cleanenv.ReadEnv(&cfg); err != nil {
+			return Config{}, err
+		}
+	} else {
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			return Config{}, err
+		}
+
+		if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := Validate(cfg); err != nil {
 		return Config{}, err
 	}
 
@@ -0,0 +1,147 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/config"
+)
+
+const validConfig = `
+database:
+  host: "localhost"
+  user: "wallet-user"
+  name: "wallet-db"
+  password: "file-password"
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "main.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestReadConfig(t *testing.T) {
+	t.Run("defaults applied", func(t *testing.T) {
+		cfg, err := config.ReadConfig(writeConfig(t, validConfig))
+		require.NoError(t, err)
+
+		assert.Equal(t, "8080", cfg.HTTPServer.Port)
+		assert.Equal(t, 5*time.Second, cfg.HTTPServer.ReadHeaderTimeout)
+		assert.Equal(t, int64(1<<20), cfg.HTTPServer.MaxBodyBytes)
+		assert.Equal(t, uint(5432), cfg.Database.Port)
+		assert.Equal(t, "disable", cfg.Database.SSLMode)
+		assert.Equal(t, "info", cfg.Log.Level)
+		assert.Equal(t, []string{"logs/app.log"}, cfg.Log.Outputs)
+		assert.True(t, cfg.Log.Redact)
+		assert.Equal(t, 10, cfg.Log.Rotation.MaxSize)
+		assert.Equal(t, []string{"/healthz", "/readyz", "/metrics"}, cfg.Log.Requests.SkipPaths)
+		assert.Positive(t, cfg.Shutdown.Timeout)
+		assert.Equal(t, time.Hour, cfg.Snapshot.Interval)
+		assert.Positive(t, cfg.Snapshot.Retention)
+		assert.Equal(t, 4, cfg.Statement.MaxConcurrent)
+		assert.Equal(t, 5*time.Minute, cfg.Statement.Timeout)
+		assert.Equal(t, config.SeedNone, cfg.Seed.Mode)
+		assert.Equal(t, int32(2), cfg.Transfer.MaxScale)
+		assert.Equal(t, "USD", cfg.Transfer.Currency)
+	})
+	t.Run("env overrides file", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD", "env-password")
+		t.Setenv("HTTP_PORT", "9090")
+
+		cfg, err := config.ReadConfig(writeConfig(t, validConfig))
+		require.NoError(t, err)
+
+		assert.Equal(t, "env-password", cfg.Database.Password)
+		assert.Equal(t, "9090", cfg.HTTPServer.Port)
+	})
+	t.Run("env only", func(t *testing.T) {
+		t.Setenv("DB_HOST", "postgres")
+		t.Setenv("DB_USER", "wallet-user")
+		t.Setenv("DB_NAME", "wallet-db")
+
+		cfg, err := config.ReadConfig("")
+		require.NoError(t, err)
+
+		assert.Equal(t, "postgres", cfg.Database.Host)
+	})
+	t.Run("missing file", func(t *testing.T) {
+		_, err := config.ReadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.Error(t, err)
+	})
+	t.Run("readable validation errors", func(t *testing.T) {
+		t.Setenv("TRACING_EXPORTER", "unknown")
+		t.Setenv("LOG_LEVEL", "verbose")
+		t.Setenv("LOG_FORMAT", "text")
+		t.Setenv("SNAPSHOT_INTERVAL", "0s")
+		t.Setenv("CHAIN_SIGNING_KEY", "c2VjcmV0LXZhbHVl")
+		t.Setenv("SEED_MODE", "file")
+		t.Setenv("TRANSFER_MAX_AMOUNT", "1e9")
+		t.Setenv("TRANSFER_CURRENCY", "usd")
+		t.Setenv("HTTP_TLS_CERT_FILE", "server.crt")
+		t.Setenv("HTTP_TRUSTED_PROXIES", "10.0.0.0/8,proxy")
+
+		_, err := config.ReadConfig(writeConfig(t, "database:\n  port: 70000\n"))
+		require.Error(t, err)
+
+		assert.ErrorContains(t, err, "database.host is required")
+		assert.ErrorContains(t, err, "database.port must be less than or equal to 65535")
+		assert.ErrorContains(t, err, `tracing.exporter must be one of [none otlp stdout file], got "unknown"`)
+		assert.ErrorContains(t, err, `log.level must be one of`)
+		assert.ErrorContains(t, err, `log.format must be one of [json console], got "text"`)
+		assert.ErrorContains(t, err, "snapshot.interval must be greater than 0")
+		assert.ErrorContains(t, err, "chain.signing_key must be base64 encoded 32 byte ed25519 seed")
+		assert.ErrorContains(t, err, "seed.file is required when Mode file")
+		assert.ErrorContains(t, err, `transfer.max_amount must be positive decimal number without sign and exponent, got "1e9"`)
+		assert.ErrorContains(t, err, `transfer.currency must be ISO 4217 currency code, got "usd"`)
+		assert.ErrorContains(t, err, "http_server.tls.key_file is required with CertFile")
+		assert.ErrorContains(t, err, `http_server.trusted_proxies[1] must be IP address or CIDR, got "proxy"`)
+		assert.NotContains(t, err.Error(), "c2VjcmV0LXZhbHVl")
+	})
+	t.Run("max amount must be positive", func(t *testing.T) {
+		for _, amount := range []string{"0", "-5", "0.00"} {
+			t.Setenv("TRANSFER_MAX_AMOUNT", amount)
+
+			_, err := config.ReadConfig(writeConfig(t, validConfig))
+			assert.ErrorContains(t, err, "transfer.max_amount must be positive decimal number", amount)
+		}
+	})
+	t.Run("database not required by memory driver", func(t *testing.T) {
+		t.Setenv("STORAGE_DRIVER", "memory")
+
+		cfg, err := config.ReadConfig(writeConfig(t, "database:\n  port: 70000\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, config.DriverMemory, cfg.Storage.Driver)
+	})
+	t.Run("sqlite driver validates its section", func(t *testing.T) {
+		t.Setenv("STORAGE_DRIVER", "sqlite")
+		t.Setenv("SQLITE_BUSY_TIMEOUT", "0s")
+
+		_, err := config.ReadConfig(writeConfig(t, "database:\n  port: 70000\n"))
+		require.Error(t, err)
+
+		assert.ErrorContains(t, err, "sqlite.busy_timeout must be greater than 0")
+		assert.NotContains(t, err.Error(), "database.")
+	})
+}
+
+func TestRedacted(t *testing.T) {
+	cfg, err := config.ReadConfig(writeConfig(t, validConfig))
+	require.NoError(t, err)
+
+	redacted := config.Redacted(cfg)
+
+	assert.NotEqual(t, cfg.Database.Password, redacted.Database.Password)
+	assert.NotContains(t, redacted.Database.Password, "file-password")
+	assert.Equal(t, "file-password", cfg.Database.Password, "original config must stay intact")
+	assert.Equal(t, cfg.Database.Host, redacted.Database.Host)
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"reflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns copy of config, where non-empty string fields
+// (or elements of string slices) tagged `secret:"true"` are masked
+func Redacted(cfg Config) Config {
+	redactValue(reflect.ValueOf(&cfg).Elem(), false)
+	return cfg
+}
+
+func redactValue(v reflect.Value, secret bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if secret && v.String() != "" {
+			v.SetString(redacted)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			redactValue(v.Field(i), field.Tag.Get("secret") == "true")
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+
+		// copy slice to not modify backing array of original config
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(cp, v)
+		v.Set(cp)
+
+		for i := 0; i < v.Len(); i++ {
+			redactValue(v.Index(i), secret)
+		}
+	}
+}
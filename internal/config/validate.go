@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/lunn06/wallet/internal/utils/validation"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// report fields by yaml names, as user see them in config file
+	v.RegisterTagNameFunc(validation.TagName("yaml"))
+
+	_ = v.RegisterValidation("ed25519_seed", func(fl validator.FieldLevel) bool {
+		seed, err := base64.StdEncoding.DecodeString(fl.Field().String())
+		return err == nil && len(seed) == ed25519.SeedSize
+	})
+	_ = v.RegisterValidation("positive_decimal", validation.PositiveDecimal)
+
+	return v
+}
+
+// Validate checks config values and joins all violations
+// into one error with human-readable messages
+func Validate(cfg Config) error {
+	// sections of not selected storage drivers aren't used
+	skipped := make([][]byte, 0, 2)
+	if cfg.Storage.Driver != DriverPgx {
+		skipped = append(skipped, []byte("Config.Database"))
+	}
+	if cfg.Storage.Driver != DriverSQLite {
+		skipped = append(skipped, []byte("Config.SQLite"))
+	}
+
+	err := validate.StructFiltered(cfg, func(ns []byte) bool {
+		return slices.ContainsFunc(skipped, func(prefix []byte) bool {
+			return bytes.HasPrefix(ns, prefix)
+		})
+	})
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	messages := make([]string, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		messages[i] = fmt.Sprintf("%s %s", validation.FieldPath(fieldErr), translator.Describe(fieldErr))
+	}
+
+	return fmt.Errorf("invalid config:\n\t%s", strings.Join(messages, "\n\t"))
+}
+
+// translator describes failed rules of config, values aren't secret besides
+// the ones of custom rules, so they are shown to be found in config
+var translator = validation.Translator{
+	ShowValues: true,
+	Custom: map[string]func(validator.FieldError) string{
+		"ed25519_seed": func(validator.FieldError) string {
+			// value is secret, so it isn't printed
+			return fmt.Sprintf("must be base64 encoded %d byte ed25519 seed", ed25519.SeedSize)
+		},
+	},
+}
@@ -0,0 +1,134 @@
+package gin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// adminPath is relative to path of api version
+const adminPath = "/admin"
+
+// TokenActor is caller of requests authorized by admin token
+const TokenActor = "admin:token"
+
+// adminMiddleware aborts requests, that have neither admin token as bearer
+// token nor client certificate with one of admin subjects, or auditor subjects
+// if auditors are allowed. Requests authorized by token are audited as TokenActor
+func adminMiddleware(cfg config.Admin, auditors bool) gin.HandlerFunc {
+	subjects := slices.Clone(cfg.ClientSubjects)
+	if auditors {
+		subjects = append(subjects, cfg.AuditorSubjects...)
+	}
+
+	return func(c *gin.Context) {
+		if caller, ok := usecase.CallerFrom(c.Request.Context()); ok && slices.Contains(subjects, caller) {
+			c.Next()
+			return
+		}
+
+		if !hasBearer(c, cfg.Token) {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			writeProblem(c, newProblem(codeUnauthorized, ""))
+			return
+		}
+
+		c.Request = c.Request.WithContext(usecase.WithCaller(c.Request.Context(), TokenActor))
+		c.Next()
+	}
+}
+
+// hasBearer reports whether request has non-empty token as bearer token.
+// Tokens are compared in constant time to not leak them via timing
+func hasBearer(c *gin.Context, token string) bool {
+	bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return token != "" && ok && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
+// routeEnabled reports whether route can be authorized somehow,
+// admin routes are disabled without token and subjects allowed to call them
+func (gc *Controller) routeEnabled(r route) bool {
+	admin := gc.config.Admin
+	switch {
+	case !r.admin:
+		return true
+	case r.auditors && len(admin.AuditorSubjects) > 0:
+		return true
+	default:
+		return admin.Token != "" || len(admin.ClientSubjects) > 0
+	}
+}
+
+// VerifyChain walks transactions hash chain and responds with report,
+// broken chain is reported with 409 status
+func (gc *Controller) VerifyChain(c *gin.Context) {
+	report, err := gc.chainUc.Verify(c)
+	if err != nil {
+		gc.writeErr(c, err)
+		return
+	}
+
+	if !report.Valid {
+		gc.logger.WarnContext(c, "Chain is broken", "broken_link", report.BrokenLink)
+		c.JSON(http.StatusConflict, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// QueryAudit responds with page of audit records, that match query params
+func (gc *Controller) QueryAudit(c *gin.Context) {
+	dto := dtos.AuditQuery{
+		Actor:  c.Query("actor"),
+		Wallet: c.Query("wallet"),
+	}
+
+	// period bounds in RFC 3339 format, both are optional
+	for field, dst := range map[string]*time.Time{"from": &dto.From, "to": &dto.To} {
+		value, ok := c.GetQuery(field)
+		if !ok {
+			continue
+		}
+		moment, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			writeInvalidRequest(c, newTimeParamError(field))
+			return
+		}
+		*dst = moment
+	}
+	for field, dst := range map[string]*int{"after_id": &dto.AfterID, "limit": &dto.Limit} {
+		value, ok := c.GetQuery(field)
+		if !ok {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			writeInvalidRequest(c, newParamError(field, "integer", "must be integer"))
+			return
+		}
+		*dst = number
+	}
+
+	if err := gc.validator.ValidateStruct(dto); err != nil {
+		writeInvalidRequest(c, err)
+		return
+	}
+
+	page, err := gc.auditUc.Query(c, dto)
+	if err != nil {
+		gc.writeErr(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
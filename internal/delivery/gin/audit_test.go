@@ -0,0 +1,129 @@
+package gin_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/config"
+	gincontroller "github.com/lunn06/wallet/internal/delivery/gin"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_Audit(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	from, to := f.InsertWallet(t, "10"), f.InsertWallet(t, "0")
+	admin := http.Header{"Authorization": {"Bearer " + testutil.AdminToken}}
+
+	body := fmt.Sprintf(`{"from": %q, "to": %q, "amount": "2.5"}`, from.Address, to.Address)
+	rec := f.Do(t, http.MethodPost, "/api/v1/send", body, http.Header{"X-Request-Id": {"req-audit"}})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	t.Run("transfer recorded", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/api/v1/admin/audit?wallet="+to.Address, "", admin)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var page dtos.AuditPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		require.Len(t, page.Records, 1)
+
+		record := page.Records[0]
+		assert.Equal(t, "transfer", record.Action)
+		assert.Equal(t, "anonymous", record.Actor)
+		assert.Equal(t, "req-audit", record.RequestID)
+		assert.NotEmpty(t, record.SourceIP)
+		assert.Equal(t, from.Address, record.Wallet)
+		assert.Equal(t, to.Address, record.Counterparty)
+		assert.Equal(t, "success", record.Outcome)
+		assert.Equal(t, "10", record.Before["from_balance"])
+		assert.Equal(t, "7.5", record.After["from_balance"])
+		assert.Equal(t, "2.5", record.After["to_balance"])
+	})
+	t.Run("forwarded source ip", func(t *testing.T) {
+		for name, tt := range map[string]struct {
+			proxies []string
+			want    string
+		}{
+			"untrusted proxy": {want: "192.0.2.1"},
+			"trusted proxy":   {proxies: []string{"192.0.2.0/24"}, want: "203.0.113.7"},
+		} {
+			f := testutil.NewServer(t, func(cfg *config.Config) {
+				cfg.HTTPServer.TrustedProxies = tt.proxies
+			})
+			from, to := f.InsertWallet(t, "10"), f.InsertWallet(t, "0")
+
+			// httptest requests come from 192.0.2.1
+			body := fmt.Sprintf(`{"from": %q, "to": %q, "amount": "1"}`, from.Address, to.Address)
+			rec := f.Do(t, http.MethodPost, "/api/v1/send", body, http.Header{"X-Forwarded-For": {"203.0.113.7"}})
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			rec = f.Do(t, http.MethodGet, "/api/v1/admin/audit?wallet="+from.Address, "", admin)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var page dtos.AuditPage
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+			require.Len(t, page.Records, 1, name)
+			assert.Equal(t, tt.want, page.Records[0].SourceIP, name)
+		}
+	})
+	t.Run("admin token recorded as actor", func(t *testing.T) {
+		rec := f.Do(t, http.MethodPut, "/api/v1/admin/log/level", `{"level": "debug"}`, admin)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = f.Do(t, http.MethodGet, "/api/v1/admin/audit?actor="+gincontroller.TokenActor, "", admin)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var page dtos.AuditPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		require.Len(t, page.Records, 1)
+		assert.Equal(t, "log.level", page.Records[0].Action)
+		assert.Equal(t, "admin:token", page.Records[0].Actor)
+	})
+	t.Run("filtered out", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/api/v1/admin/audit?actor=CN%3Dops", "", admin)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"records": []}`, rec.Body.String())
+	})
+	t.Run("invalid params", func(t *testing.T) {
+		for _, query := range []string{"limit=ten", "limit=1001", "after_id=-1", "from=yesterday", "wallet=not-uuid"} {
+			rec := f.Do(t, http.MethodGet, "/api/v1/admin/audit?"+query, "", admin)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/api/v1/admin/audit", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestController_AuditorSubjects(t *testing.T) {
+	f := testutil.NewServer(t, func(cfg *config.Config) {
+		cfg.Admin = config.Admin{AuditorSubjects: []string{"CN=auditor"}}
+	})
+
+	call := func(target string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: "auditor"}}
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+
+		rec := httptest.NewRecorder()
+		f.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call("/api/v1/admin/audit"))
+	// auditors can't call the other admin endpoints, that are disabled without admins
+	assert.Equal(t, http.StatusNotFound, call("/api/v1/admin/chain/verify"))
+}
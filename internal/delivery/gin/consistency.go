@@ -0,0 +1,24 @@
+package gin
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/domain/usecase"
+)
+
+// readYourWritesHeader is request header that forces reads to primary database
+const readYourWritesHeader = "X-Read-Your-Writes"
+
+// consistencyMiddleware routes reads of request to primary storage
+// if client asked for it by readYourWritesHeader
+func consistencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if force, _ := strconv.ParseBool(c.GetHeader(readYourWritesHeader)); force {
+			c.Request = c.Request.WithContext(usecase.WithReadYourWrites(c.Request.Context()))
+		}
+
+		c.Next()
+	}
+}
@@ -4,41 +4,96 @@ package gin
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	sloggin "github.com/samber/slog-gin"
 
 	"github.com/lunn06/wallet/internal/config"
+	auditUc "github.com/lunn06/wallet/internal/domain/usecase/audit"
+	chainUc "github.com/lunn06/wallet/internal/domain/usecase/chain"
+	statementUc "github.com/lunn06/wallet/internal/domain/usecase/statement"
 	transactionUc "github.com/lunn06/wallet/internal/domain/usecase/transation"
 	walletUc "github.com/lunn06/wallet/internal/domain/usecase/wallet"
+	"github.com/lunn06/wallet/internal/health"
+	"github.com/lunn06/wallet/internal/metrics"
+	"github.com/lunn06/wallet/pkg/certreload"
+	"github.com/lunn06/wallet/pkg/semaphore"
 )
 
-const basePath = "/api"
+const (
+	basePath    = "/api"
+	metricsPath = "/metrics"
+)
 
 // Controller connect http endpoints with domain usecases
 type Controller struct {
-	logger *slog.Logger
-	server *http.Server
-	config config.Config
+	logger  *slog.Logger
+	server  *http.Server
+	config  config.Config
+	metrics *metrics.Metrics
+	probe   *health.Probe
+
+	metricsHandler http.Handler
+	validator      *defaultValidator
+	// statements bounds concurrent statement downloads
+	statements *semaphore.Semaphore
+
+	// watchCtx bounds reloading of TLS certificate
+	watchCtx  context.Context
+	stopWatch context.CancelFunc
 
 	walletUc      walletUc.Usecase
 	transactionUc transactionUc.Usecase
+	statementUc   statementUc.Usecase
+	chainUc       chainUc.Usecase
+	auditUc       auditUc.Usecase
+
+	// level of app logger, that is changed by admin endpoint
+	level *slog.LevelVar
 }
 
+// Run serves http, or https if TLS certificate is configured
 func (gc *Controller) Run() error {
-	gc.logger.Info("Controller started", slog.String("addr", gc.server.Addr))
-	if err := gc.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	tlsCfg := gc.config.HTTPServer.TLS
+	if tlsCfg.CertFile == "" {
+		gc.logger.Info("Controller started", slog.String("addr", gc.server.Addr))
+		return ignoreServerClosed(gc.server.ListenAndServe())
+	}
+
+	reloader, err := certreload.New(tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.ClientCAFile, gc.logger)
+	if err != nil {
 		return err
 	}
+	gc.server.TLSConfig = reloader.TLSConfig()
+	go reloader.Watch(gc.watchCtx, tlsCfg.ReloadInterval)
+
+	gc.logger.Info(
+		"Controller started",
+		slog.String("addr", gc.server.Addr),
+		slog.Bool("tls", true),
+		slog.Bool("mtls", tlsCfg.ClientCAFile != ""),
+	)
+	// certificate is taken from TLSConfig
+	return ignoreServerClosed(gc.server.ListenAndServeTLS("", ""))
+}
+
+func ignoreServerClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
 
-	return nil
+// Handler returns http handler of all endpoints, e.g. for tests without listening
+func (gc *Controller) Handler() http.Handler {
+	return gc.server.Handler
 }
 
 func (gc *Controller) Close(ctx context.Context) error {
+	gc.stopWatch()
+
 	err := gc.server.Shutdown(ctx)
 	if err == nil {
 		gc.logger.Info("Controller close successfully")
@@ -50,33 +105,73 @@ func (gc *Controller) Close(ctx context.Context) error {
 func New(
 	cfg config.Config,
 	logger *slog.Logger,
+	metrics *metrics.Metrics,
+	probe *health.Probe,
 	walletUc walletUc.Usecase,
 	transactionUc transactionUc.Usecase,
+	statementUc statementUc.Usecase,
+	chainUc chainUc.Usecase,
+	auditUc auditUc.Usecase,
+	level *slog.LevelVar,
 ) *Controller {
 	controller := Controller{
-		logger:        logger,
-		config:        cfg,
-		walletUc:      walletUc,
-		transactionUc: transactionUc,
+		logger:         logger,
+		config:         cfg,
+		metrics:        metrics,
+		probe:          probe,
+		metricsHandler: metrics.Handler(),
+		validator:      newDefaultValidator(cfg.Transfer),
+		statements:     semaphore.New(cfg.Statement.MaxConcurrent),
+		walletUc:       walletUc,
+		transactionUc:  transactionUc,
+		statementUc:    statementUc,
+		chainUc:        chainUc,
+		auditUc:        auditUc,
+		level:          level,
+	}
+	controller.watchCtx, controller.stopWatch = context.WithCancel(context.Background())
+	if err := metrics.RegisterSemaphore("statement", controller.statements); err != nil {
+		logger.Error("Failed to register statement semaphore metrics", "err", err)
 	}
 
 	r := gin.New()
+	// let handlers reach request context values (e.g. trace span) via gin.Context
+	r.ContextWithFallback = true
+	// client ip of audit is taken from X-Forwarded-For only behind trusted proxies,
+	// proxies are validated by config, so error means no proxy is trusted
+	if err := r.SetTrustedProxies(cfg.HTTPServer.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies, X-Forwarded-For is ignored", "err", err)
+		_ = r.SetTrustedProxies(nil)
+	}
 	r.Use(
-		// connect controller's logger to gin handler
-		sloggin.New(logger),
-		gin.Recovery(),
+		// must be first to see original response writer
+		responseControllerMiddleware(),
+		tracingMiddleware(),
+		requestIDMiddleware(),
+		callerMiddleware(),
+		requestLogger(logger, cfg.Log.Requests),
+		// outside of recovery to observe panics as responses with 500 status
+		metricsMiddleware(metrics),
+		controller.recoveryMiddleware(),
+		bodyLimitMiddleware(cfg.HTTPServer.MaxBodyBytes),
+		consistencyMiddleware(),
 	)
 
 	controller.setupDocs(r)
 	controller.setupEndpoints(r)
+	r.NoRoute(func(c *gin.Context) {
+		writeProblem(c, newProblem(codeNotFound, "route doesn't exist"))
+	})
 
-	// connect validator to gin handler
-	binding.Validator = new(defaultValidator)
-
-	addr := fmt.Sprintf(":%v", controller.config.HTTPServer.Port)
+	serverCfg := controller.config.HTTPServer
 	controller.server = &http.Server{
-		Addr:    addr,
-		Handler: r,
+		Addr:              net.JoinHostPort(serverCfg.Address, serverCfg.Port),
+		Handler:           r,
+		ReadTimeout:       serverCfg.ReadTimeout,
+		ReadHeaderTimeout: serverCfg.ReadHeaderTimeout,
+		WriteTimeout:      serverCfg.WriteTimeout,
+		IdleTimeout:       serverCfg.IdleTimeout,
+		MaxHeaderBytes:    serverCfg.MaxHeaderBytes,
 	}
 
 	controller.logger.Info("Controller created")
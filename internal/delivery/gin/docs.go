@@ -1,105 +1,240 @@
 package gin
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/go-swagno/swagno"
-	"github.com/go-swagno/swagno-gin/swagger"
-	"github.com/go-swagno/swagno/components/endpoint"
-	"github.com/go-swagno/swagno/components/http/response"
-	"github.com/go-swagno/swagno/components/mime"
-	"github.com/go-swagno/swagno/components/parameter"
+	swaggerfiles "github.com/go-swagno/swagno-files"
 
 	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/pkg/openapi"
 )
 
+// openAPIPath serves OpenAPI document of all routes
+const openAPIPath = basePath + "/openapi.json"
+
+// adminSecurityScheme is name of bearer admin token scheme in OpenAPI document
+const adminSecurityScheme = "adminToken"
+
+// metricsSecurityScheme is name of bearer metrics token scheme in OpenAPI document
+const metricsSecurityScheme = "metricsToken"
+
+// setupDocs serves OpenAPI document of all routes and swagger ui with
+// document of every api version under its path. Documents are made of
+// the same route definitions as endpoints
 func (gc *Controller) setupDocs(r *gin.Engine) {
-	sw := swagno.New(swagno.Config{
+	r.GET(openAPIPath, jsonHandler(mustMarshal(gc.openAPI(apiVersions, systemRoutes))))
+
+	for _, version := range apiVersions {
+		prefix := version.path + "/swagger"
+		r.GET(prefix+"/*any", swaggerHandler(prefix, mustMarshal(gc.openAPI([]apiVersion{version}, nil))))
+	}
+}
+
+// openAPI builds document of system routes and api routes of versions
+func (gc *Controller) openAPI(versions []apiVersion, system []route) *openapi.Document {
+	doc := openapi.New(openapi.Info{
 		Title:       "Wallet",
-		Version:     "v0.0.0",
 		Description: "Wallet Backend",
-		Host:        gc.config.HTTPServer.Address,
-		Path:        basePath,
-		Contact: &swagno.Contact{
+		Version:     versions[0].name,
+		Contact: &openapi.Contact{
 			Name:  "Егор Титов",
-			Url:   "https://github.com/lunn06/wallet",
+			URL:   "https://github.com/lunn06/wallet",
 			Email: "kuunii06@gmail.com",
 		},
 	})
+	if len(versions) == 1 && !versions[0].deprecatedAt.IsZero() {
+		doc.Info.Description = deprecationNotice(versions[0])
+	}
+
+	for _, route := range system {
+		if !gc.routeEnabled(route) {
+			continue
+		}
+
+		operation := gc.operation(doc, route)
+		operation.OperationID = route.name
+		operation.Tags = []string{"system"}
+		if route.admin {
+			secureOperation(doc, operation)
+		}
+		if route.scraped && gc.config.Metrics.Token != "" {
+			doc.Components.SecuritySchemes[metricsSecurityScheme] = &openapi.SecurityScheme{
+				Type:        "http",
+				Scheme:      "bearer",
+				Description: "Metrics token of config",
+			}
+			operation.Security = []openapi.SecurityRequirement{{metricsSecurityScheme: {}}}
+			addProblems(doc, operation, adminProblems)
+		}
+		doc.AddOperation(route.method, openAPIPathOf(route.path), operation)
+	}
+
+	for _, version := range versions {
+		for _, route := range apiRoutes {
+			if !version.serves(route) || !gc.routeEnabled(route) {
+				continue
+			}
+
+			operation := gc.operation(doc, route)
+			operation.OperationID = version.name + "." + route.name
+			operation.Tags = []string{version.name}
+			if route.admin {
+				secureOperation(doc, operation)
+			}
+			addProblems(doc, operation, commonProblems)
+
+			if !version.deprecatedAt.IsZero() {
+				operation.Deprecated = true
+				operation.Description = deprecationNotice(version)
+				for _, response := range operation.Responses {
+					response.Headers = deprecationHeaders
+				}
+			}
+
+			doc.AddOperation(route.method, openAPIPathOf(version.path+route.path), operation)
+		}
+	}
+
+	return doc
+}
+
+// secureOperation documents admin token required by operation of admin route
+func secureOperation(doc *openapi.Document, operation *openapi.Operation) {
+	doc.Components.SecuritySchemes[adminSecurityScheme] = &openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "Admin token of config, it is not required from mutual TLS clients with admin subjects",
+	}
+	operation.Security = []openapi.SecurityRequirement{{adminSecurityScheme: {}}}
+	addProblems(doc, operation, adminProblems)
+}
+
+func (gc *Controller) operation(doc *openapi.Document, route route) *openapi.Operation {
+	operation := &openapi.Operation{
+		Summary:    route.summary,
+		Parameters: route.params,
+		Responses:  make(map[string]*openapi.Response),
+	}
+
+	if route.body != nil {
+		operation.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{"application/json": {Schema: doc.Schema(route.body)}},
+		}
+	}
+
+	for _, resp := range route.responses {
+		description := resp.description
+		if description == "" {
+			description = http.StatusText(resp.status)
+		}
+
+		contentTypes := resp.contentTypes
+		if len(contentTypes) == 0 {
+			contentTypes = []string{"application/json"}
+		}
+
+		content := make(map[string]openapi.MediaType, len(contentTypes))
+		for _, contentType := range contentTypes {
+			schema := doc.Schema(resp.body)
+			// rows of csv are described by json schema of row in other formats
+			if strings.HasPrefix(contentType, "text/") {
+				schema = &openapi.Schema{Type: "string"}
+			}
+			content[contentType] = openapi.MediaType{Schema: schema}
+		}
 
-	endpoints := []*endpoint.EndPoint{
-		endpoint.New(
-			endpoint.POST,
-			"/send",
-			endpoint.WithBody(dtos.SendRequest{}),
-			endpoint.WithSuccessfulReturns([]response.Response{
-				response.New(dtos.SendResponse{}, "200", ""),
-			}),
-			endpoint.WithErrors([]response.Response{
-				response.New(dtos.ErrorResp{}, "400", "INVALID_REQUEST_BODY"),
-				response.New(dtos.ErrorResp{}, "400", "CLIENT_ERROR"),
-				response.New(dtos.ErrorResp{}, "403", "LACK_OF_CURRENCY"),
-				response.New(dtos.ErrorResp{}, "404", "NOT_FOUND"),
-				response.New(dtos.ErrorResp{}, "500", "INTERNAL_SERVER_ERROR"),
-				response.New(dtos.ErrorResp{}, "501", "NOT_IMPLEMENTED"),
-			}),
-			endpoint.WithConsume([]mime.MIME{mime.JSON}),
-			endpoint.WithProduce([]mime.MIME{mime.JSON}),
-			endpoint.WithSummary("Send balance between wallets"),
-		),
-
-		endpoint.New(
-			endpoint.GET,
-			"/transaction",
-			endpoint.WithParams(
-				parameter.IntParam(
-					"count",
-					parameter.Query,
-					parameter.WithRequired(),
-					parameter.WithDefault(5),
-				),
-			),
-			endpoint.WithSuccessfulReturns([]response.Response{
-				response.New(dtos.GetLastResponse{}, "200", ""),
-			}),
-			endpoint.WithErrors([]response.Response{
-				response.New(dtos.ErrorResp{}, "400", "INVALID_REQUEST_BODY"),
-				response.New(dtos.ErrorResp{}, "400", "CLIENT_ERROR"),
-				response.New(dtos.ErrorResp{}, "404", "NOT_FOUND"),
-				response.New(dtos.ErrorResp{}, "500", "INTERNAL_SERVER_ERROR"),
-				response.New(dtos.ErrorResp{}, "501", "NOT_IMPLEMENTED"),
-			}),
-			endpoint.WithConsume([]mime.MIME{mime.JSON}),
-			endpoint.WithProduce([]mime.MIME{mime.JSON}),
-			endpoint.WithSummary("Get last transactions"),
-		),
-
-		endpoint.New(
-			endpoint.GET,
-			"/wallet/{address}/balance",
-			endpoint.WithParams(
-				parameter.StrParam(
-					"address",
-					parameter.Path,
-					parameter.WithRequired(),
-				),
-			),
-			endpoint.WithSuccessfulReturns([]response.Response{
-				response.New(dtos.GetBalanceResponse{}, "200", ""),
-			}),
-			endpoint.WithErrors([]response.Response{
-				response.New(dtos.ErrorResp{}, "400", "INVALID_REQUEST_BODY"),
-				response.New(dtos.ErrorResp{}, "400", "CLIENT_ERROR"),
-				response.New(dtos.ErrorResp{}, "404", "NOT_FOUND"),
-				response.New(dtos.ErrorResp{}, "500", "INTERNAL_SERVER_ERROR"),
-				response.New(dtos.ErrorResp{}, "501", "NOT_IMPLEMENTED"),
-			}),
-			endpoint.WithConsume([]mime.MIME{mime.JSON}),
-			endpoint.WithProduce([]mime.MIME{mime.JSON}),
-			endpoint.WithSummary("Get wallet balance"),
-		),
+		operation.Responses[strconv.Itoa(resp.status)] = &openapi.Response{
+			Description: description,
+			Content:     content,
+		}
 	}
+	addProblems(doc, operation, route.problems)
+
+	return operation
+}
+
+// addProblems documents problem responses of codes,
+// codes of the same status share response
+func addProblems(doc *openapi.Document, operation *openapi.Operation, codes []string) {
+	for _, code := range codes {
+		status := strconv.Itoa(problemKinds[code].status)
 
-	sw.AddEndpoints(endpoints)
+		response, ok := operation.Responses[status]
+		if !ok {
+			response = &openapi.Response{
+				Content: map[string]openapi.MediaType{problemContentType: {Schema: doc.Schema(dtos.Problem{})}},
+			}
+			operation.Responses[status] = response
+		}
 
-	r.GET(basePath+"/swagger/*any", swagger.SwaggerHandler(sw.MustToJson(), swagger.Config{Prefix: "/api/swagger"}))
+		codes := strings.Split(response.Description, ", ")
+		if response.Description == "" {
+			codes = nil
+		}
+		if !slices.Contains(codes, code) {
+			response.Description = strings.Join(append(codes, code), ", ")
+		}
+	}
+}
+
+var deprecationHeaders = map[string]openapi.Header{
+	"Deprecation": {Description: "Date of deprecation (RFC 9745)", Schema: &openapi.Schema{Type: "string"}},
+	"Sunset":      {Description: "Date of removal (RFC 8594)", Schema: &openapi.Schema{Type: "string"}},
+	"Link":        {Description: "Successor version of route", Schema: &openapi.Schema{Type: "string"}},
+}
+
+func deprecationNotice(version apiVersion) string {
+	return fmt.Sprintf(
+		"Deprecated in favor of %s, will be removed after %s",
+		version.successor, version.sunsetAt.Format(time.DateOnly),
+	)
+}
+
+var ginParam = regexp.MustCompile(`[:*](\w+)`)
+
+// openAPIPathOf converts gin path params to OpenAPI ones, e.g. :address to {address}
+func openAPIPathOf(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+func mustMarshal(doc *openapi.Document) []byte {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func jsonHandler(raw []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", raw)
+	}
+}
+
+// swaggerHandler serves swagger ui and doc under prefix. Unlike handler of
+// swagno-gin it keeps no global state, so every api version has own doc
+func swaggerHandler(prefix string, doc []byte) gin.HandlerFunc {
+	files := swaggerfiles.NewHandler()
+	files.Prefix = prefix
+	serveDoc := jsonHandler(doc)
+
+	return func(c *gin.Context) {
+		switch c.Param("any") {
+		case "", "/":
+			c.Redirect(http.StatusMovedPermanently, prefix+"/index.html")
+		case "/doc.json":
+			serveDoc(c)
+		default:
+			files.ServeHTTP(c.Writer, c.Request)
+		}
+	}
 }
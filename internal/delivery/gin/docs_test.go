@@ -0,0 +1,90 @@
+package gin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+type openAPIDoc struct {
+	OpenAPI string `json:"openapi"`
+	Paths   map[string]map[string]struct {
+		Deprecated bool `json:"deprecated"`
+		Responses  map[string]struct {
+			Description string `json:"description"`
+		} `json:"responses"`
+	} `json:"paths"`
+}
+
+func fetchOpenAPI(t *testing.T, f testutil.Server) openAPIDoc {
+	t.Helper()
+
+	rec := f.Do(t, http.MethodGet, "/api/openapi.json", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc openAPIDoc
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+
+	return doc
+}
+
+var ginParam = regexp.MustCompile(`[:*](\w+)`)
+
+// TestController_OpenAPIMatchesRoutes diffs documented operations against
+// registered gin routes, only routes serving docs themselves are undocumented
+func TestController_OpenAPIMatchesRoutes(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	doc := fetchOpenAPI(t, f)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	var documented []string
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	engine, ok := f.Handler.(*gin.Engine)
+	require.True(t, ok)
+
+	var registered []string
+	for _, route := range engine.Routes() {
+		if route.Path == "/api/openapi.json" || strings.HasSuffix(route.Path, "/swagger/*any") {
+			continue
+		}
+		registered = append(registered, route.Method+" "+ginParam.ReplaceAllString(route.Path, "{$1}"))
+	}
+
+	assert.ElementsMatch(t, registered, documented)
+}
+
+func TestController_OpenAPIResponses(t *testing.T) {
+	doc := fetchOpenAPI(t, testutil.NewServer(t, nil))
+
+	require.Contains(t, doc.Paths, "/api/v1/transactions")
+	assert.NotContains(t, doc.Paths, "/api/v1/transaction")
+
+	verify := doc.Paths["/api/v1/admin/chain/verify"]["get"]
+	assert.Equal(t, "Chain is broken", verify.Responses["409"].Description)
+	assert.Equal(t, "UNAUTHORIZED", verify.Responses["401"].Description)
+
+	send := doc.Paths["/api/v1/send"]["post"]
+	assert.Equal(t, "INVALID_REQUEST_BODY, INVALID_ARGUMENT", send.Responses["400"].Description)
+	assert.Equal(t, "LACK_OF_CURRENCY, WALLET_FROZEN", send.Responses["403"].Description)
+	assert.False(t, send.Deprecated)
+	assert.True(t, doc.Paths["/api/send"]["post"].Deprecated)
+
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			assert.NotContains(t, operation.Responses, "501", "%s %s", method, path)
+		}
+	}
+}
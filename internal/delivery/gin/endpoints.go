@@ -4,10 +4,37 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// setupEndpoints registers system routes and api routes of every version
 func (gc *Controller) setupEndpoints(r *gin.Engine) {
-	base := r.Group(basePath)
+	for _, route := range systemRoutes {
+		gc.handle(r, route)
+	}
 
-	base.POST("/send", gc.Send)
-	base.GET("/transactions", gc.GetLast)
-	base.GET("/wallet/:address/balance", gc.GetBalance)
+	for _, version := range apiVersions {
+		base := r.Group(version.path, versionMiddleware(version))
+
+		for _, route := range apiRoutes {
+			if version.serves(route) {
+				gc.handle(base, route)
+			}
+		}
+	}
+}
+
+// handle registers route in group, admin routes are authorized
+// and registered only if they can be authorized somehow
+func (gc *Controller) handle(group gin.IRoutes, route route) {
+	if route.scraped && gc.config.Metrics.Token != "" {
+		group.Handle(route.method, route.path, metricsTokenMiddleware(gc.config.Metrics.Token), gc.handlerOf(route))
+		return
+	}
+
+	if !route.admin {
+		group.Handle(route.method, route.path, gc.handlerOf(route))
+		return
+	}
+
+	if gc.routeEnabled(route) {
+		group.Handle(route.method, route.path, adminMiddleware(gc.config.Admin, route.auditors), gc.handlerOf(route))
+	}
 }
@@ -1,31 +1,226 @@
 package gin
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joomcode/errorx"
 
 	"github.com/lunn06/wallet/internal/domain/usecase"
 	"github.com/lunn06/wallet/internal/dtos"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
 )
 
-// handleErr cast error type to errorx.Error
-// to handle type of error and return matching http status
-// and dto with error message
-func handleErr(err error) (int, dtos.ErrorResp) {
-	errx := errorx.Cast(err)
+// problemContentType is media type of RFC 7807 error responses
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix is prefix of problem type URIs, suffix is kebab-cased code
+const problemTypePrefix = "urn:wallet:problem:"
+
+// Problem codes, every code has its own status and title
+const (
+	codeInvalidRequest     = "INVALID_REQUEST_BODY"
+	codeInvalidArgument    = "INVALID_ARGUMENT"
+	codeClientError        = "CLIENT_ERROR"
+	codeUnauthorized       = "UNAUTHORIZED"
+	codeLackOfCurrency     = "LACK_OF_CURRENCY"
+	codeWalletFrozen       = "WALLET_FROZEN"
+	codeNotFound           = "NOT_FOUND"
+	codePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	codeDuplicate          = "DUPLICATE_ERROR"
+	codeHistoryUnavailable = "HISTORY_UNAVAILABLE"
+	codeInternal           = "INTERNAL_SERVER_ERROR"
+	codeNotConfigured      = "NOT_CONFIGURED"
+	codeUnavailable        = "SERVICE_UNAVAILABLE"
+)
+
+type problemKind struct {
+	status int
+	title  string
+}
+
+var problemKinds = map[string]problemKind{
+	codeInvalidRequest:     {http.StatusBadRequest, "Request is malformed or has invalid fields"},
+	codeInvalidArgument:    {http.StatusBadRequest, "Request arguments are rejected"},
+	codeClientError:        {http.StatusBadRequest, "Request can't be processed"},
+	codeUnauthorized:       {http.StatusUnauthorized, "Valid bearer token is required"},
+	codeLackOfCurrency:     {http.StatusForbidden, "Insufficient funds"},
+	codeWalletFrozen:       {http.StatusForbidden, "Wallet is frozen"},
+	codeNotFound:           {http.StatusNotFound, "Resource not found"},
+	codeDuplicate:          {http.StatusConflict, "Resource already exists"},
+	codePayloadTooLarge:    {http.StatusRequestEntityTooLarge, "Request body is too large"},
+	codeHistoryUnavailable: {http.StatusUnprocessableEntity, "Balance history is unavailable"},
+	codeInternal:           {http.StatusInternalServerError, "Internal server error"},
+	codeNotConfigured:      {http.StatusNotImplemented, "Feature is not configured"},
+	codeUnavailable:        {http.StatusServiceUnavailable, "Service is temporarily unavailable"},
+}
+
+// newProblem creates problem of code with detail, that must be safe to show to client
+func newProblem(code, detail string) dtos.Problem {
+	kind, ok := problemKinds[code]
+	if !ok {
+		code, kind = codeInternal, problemKinds[codeInternal]
+	}
+
+	return dtos.Problem{
+		Type:   problemTypePrefix + strings.ToLower(strings.ReplaceAll(code, "_", "-")),
+		Title:  kind.title,
+		Status: kind.status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// writeProblem aborts request with problem, that is bound to request path and id
+func writeProblem(c *gin.Context, problem dtos.Problem) {
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = requestID(c)
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// writeErr logs usecase error and aborts request with matching problem
+func (gc *Controller) writeErr(c *gin.Context, err error) {
+	problem := handleErr(err)
+	if problem.Status >= http.StatusInternalServerError {
+		gc.logger.ErrorContext(c, "error", "cause", err.Error())
+	} else {
+		gc.logger.WarnContext(c, "error", "cause", err.Error())
+	}
+
+	writeProblem(c, problem)
+}
+
+// writeInvalidRequest aborts request, which body or parameters can't be
+// decoded or are invalid, with problem that lists invalid fields
+func writeInvalidRequest(c *gin.Context, err error) {
+	problem := newProblem(codeInvalidRequest, "")
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		paramErr  *paramError
+		sizeErr   *http.MaxBytesError
+	)
 	switch {
-	case usecase.IsLackOfCurrencyErr(errx):
-		return http.StatusForbidden, dtos.ErrorResp{Error: "LACK_OF_CURRENCY"}
-	case usecase.IsServerErr(errx):
-		return http.StatusInternalServerError, dtos.ErrorResp{Error: "INTERNAL_SERVER_ERROR"}
-	case usecase.IsNotFoundErr(errx):
-		return http.StatusNotFound, dtos.ErrorResp{Error: "NOT_FOUND"}
-	case usecase.IsDuplicateErr(errx):
-		return http.StatusConflict, dtos.ErrorResp{Error: "DUPLICATE_ERROR"}
-	case usecase.IsClientErr(errx):
-		return http.StatusBadRequest, dtos.ErrorResp{Error: "CLIENT_ERROR"}
+	case errors.As(err, &sizeErr):
+		writeProblem(c, newPayloadTooLarge(sizeErr.Limit))
+		return
+	case errors.As(err, &paramErr):
+		problem.Errors = []dtos.FieldError{paramErr.fieldError}
+	case errors.As(err, &typeErr):
+		problem.Errors = []dtos.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be " + typeErr.Type.String(),
+		}}
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		// encoding/json has no type of this error
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)
+		problem.Errors = []dtos.FieldError{{Field: field, Rule: "unknown", Message: "is unknown field"}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		problem.Detail = "request body is not valid json"
+	case errors.Is(err, io.EOF):
+		problem.Detail = "request body is empty"
 	default:
-		return http.StatusNotImplemented, dtos.ErrorResp{Error: "NOT_IMPLEMENTED"}
+		if fields := fieldErrors(err); len(fields) > 0 {
+			problem.Errors = fields
+		} else {
+			problem.Detail = err.Error()
+		}
+	}
+
+	writeProblem(c, problem)
+}
+
+// unknownFieldPrefix starts error of json decoder, that disallows unknown fields
+const unknownFieldPrefix = "json: unknown field "
+
+// paramError is failure to parse query or path parameter
+type paramError struct {
+	fieldError dtos.FieldError
+}
+
+func newParamError(field, rule, message string) *paramError {
+	return &paramError{fieldError: dtos.FieldError{Field: field, Rule: rule, Message: message}}
+}
+
+func newTimeParamError(field string) *paramError {
+	return newParamError(field, "rfc3339", "must be RFC 3339 time")
+}
+
+func (e *paramError) Error() string {
+	return e.fieldError.Field + " " + e.fieldError.Message
+}
+
+// handleErr walks error and its causes to find
+// matching problem, unknown errors are internal ones
+func handleErr(err error) dtos.Problem {
+	switch {
+	case inChain(err, usecase.ErrLackOfCurrency, storageImpl.ErrInsufficientFunds):
+		return newProblem(codeLackOfCurrency, clientMessage(err))
+	case inChain(err, usecase.ErrFrozen, storageImpl.ErrFrozen):
+		return newProblem(codeWalletFrozen, clientMessage(err))
+	case hasTraitInChain(err, errorx.NotFound()):
+		return newProblem(codeNotFound, clientMessage(err))
+	case hasTraitInChain(err, errorx.Duplicate()):
+		return newProblem(codeDuplicate, clientMessage(err))
+	case inChain(err, usecase.ErrHistoryUnavailable):
+		return newProblem(codeHistoryUnavailable, clientMessage(err))
+	case hasTraitInChain(err, errorx.Timeout()):
+		return newProblem(codeUnavailable, "")
+	case inChain(err, usecase.ErrNotConfigured):
+		return newProblem(codeNotConfigured, "")
+	case inChain(err, storageImpl.ErrInvalid):
+		// arguments are validated by usecases, so storage rejects them on server faults,
+		// e.g. balances in other currency, which details aren't shown to client
+		return newProblem(codeInternal, "")
+	case inChain(err, usecase.ErrInvalid):
+		return newProblem(codeInvalidArgument, clientMessage(err))
+	case hasTraitInChain(err, usecase.Client), hasTraitInChain(err, storageImpl.External):
+		return newProblem(codeClientError, clientMessage(err))
+	default:
+		// usecase.Server and storage.Internal errors, their details aren't shown to client
+		return newProblem(codeInternal, "")
+	}
+}
+
+// inChain reports whether err or any of its causes is of one of types.
+// errorx.Wrap hides cause from type checks, so causes are walked explicitly
+func inChain(err error, types ...*errorx.Type) bool {
+	for x := errorx.Cast(err); x != nil; x = errorx.Cast(x.Cause()) {
+		for _, t := range types {
+			if x.IsOfType(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasTraitInChain reports whether err or any of its causes has trait
+func hasTraitInChain(err error, trait errorx.Trait) bool {
+	for x := errorx.Cast(err); x != nil; x = errorx.Cast(x.Cause()) {
+		if x.HasTrait(trait) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientMessage joins messages of err and its causes without
+// errorx type names, which are implementation details
+func clientMessage(err error) string {
+	var messages []string
+	for x := errorx.Cast(err); x != nil; x = errorx.Cast(x.Cause()) {
+		if message := x.Message(); message != "" {
+			messages = append(messages, message)
+		}
 	}
+	return strings.Join(messages, ": ")
 }
@@ -0,0 +1,177 @@
+package gin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/testutil"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+func decodeProblem(t *testing.T, body []byte) dtos.Problem {
+	t.Helper()
+
+	var problem dtos.Problem
+	require.NoError(t, json.Unmarshal(body, &problem), string(body))
+
+	return problem
+}
+
+func TestController_Problems(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	poor := f.InsertWallet(t, "1")
+	rich := f.InsertWallet(t, "100")
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		header http.Header
+		status int
+		code   string
+		fields []dtos.FieldError
+	}{
+		{
+			name:   "malformed json",
+			method: http.MethodPost,
+			target: "/api/send",
+			body:   `{"from":`,
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+		},
+		{
+			name:   "wrong json type",
+			method: http.MethodPost,
+			target: "/api/send",
+			body:   `{"from":"a","to":"b","amount":1}`,
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+			fields: []dtos.FieldError{{Field: "amount", Rule: "type", Message: "must be string"}},
+		},
+		{
+			name:   "non-numeric count",
+			method: http.MethodGet,
+			target: "/api/transactions?count=ten",
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+			fields: []dtos.FieldError{{Field: "count", Rule: "integer", Message: "must be integer"}},
+		},
+		{
+			name:   "negative count",
+			method: http.MethodGet,
+			target: "/api/transactions?count=-1",
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+			fields: []dtos.FieldError{{Field: "count", Rule: "gte", Message: "must be greater than or equal to 0"}},
+		},
+		{
+			name:   "invalid address",
+			method: http.MethodGet,
+			target: "/api/wallet/not-uuid/balance",
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+			fields: []dtos.FieldError{{Field: "address", Rule: "uuid4", Message: "must be uuid"}},
+		},
+		{
+			name:   "invalid moment",
+			method: http.MethodGet,
+			target: fmt.Sprintf("/api/wallet/%s/balance?at=yesterday", rich.Address),
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+			fields: []dtos.FieldError{{Field: "at", Rule: "rfc3339", Message: "must be RFC 3339 time"}},
+		},
+		{
+			name:   "statement period",
+			method: http.MethodGet,
+			target: fmt.Sprintf("/api/wallet/%s/statement?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z", rich.Address),
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST_BODY",
+			fields: []dtos.FieldError{{Field: "to", Rule: "gtfield", Message: "must be after from"}},
+		},
+		{
+			name:   "wallet not found",
+			method: http.MethodGet,
+			target: fmt.Sprintf("/api/wallet/%s/balance", uuid.NewString()),
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+		},
+		{
+			name:   "lack of currency",
+			method: http.MethodPost,
+			target: "/api/send",
+			body:   fmt.Sprintf(`{"from":%q,"to":%q,"amount":"5"}`, poor.Address, rich.Address),
+			status: http.StatusForbidden,
+			code:   "LACK_OF_CURRENCY",
+		},
+		{
+			name:   "unauthorized admin",
+			method: http.MethodGet,
+			target: "/api/admin/chain/verify",
+			header: http.Header{"Authorization": {"Bearer wrong"}},
+			status: http.StatusUnauthorized,
+			code:   "UNAUTHORIZED",
+		},
+		{
+			name:   "unknown route",
+			method: http.MethodGet,
+			target: "/api/unknown",
+			status: http.StatusNotFound,
+			code:   "NOT_FOUND",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.Do(t, tt.method, tt.target, tt.body, tt.header)
+
+			require.Equal(t, tt.status, rec.Code, rec.Body.String())
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+			problem := decodeProblem(t, rec.Body.Bytes())
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.NotEmpty(t, problem.Title)
+			assert.NotEmpty(t, problem.Type)
+			assert.Equal(t, tt.fields, problem.Errors)
+		})
+	}
+}
+
+func TestController_StorageFaultProblem(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	// balance in other currency makes storage fail to transfer
+	eur, err := models.NewBalanceFromString("100", money.Currency{Code: "EUR", Scale: 2})
+	require.NoError(t, err)
+	from, err := f.Wallet.Insert(context.Background(), models.Wallet{Address: uuid.NewString(), Balance: eur})
+	require.NoError(t, err)
+	to := f.InsertWallet(t, "1")
+
+	rec := f.Do(t, http.MethodPost, "/api/v1/send", fmt.Sprintf(`{"from":%q,"to":%q,"amount":"5"}`, from.Address, to.Address), nil)
+	require.Equal(t, http.StatusInternalServerError, rec.Code, rec.Body.String())
+
+	problem := decodeProblem(t, rec.Body.Bytes())
+	assert.Equal(t, "INTERNAL_SERVER_ERROR", problem.Code)
+	assert.Empty(t, problem.Detail)
+	assert.NotContains(t, rec.Body.String(), "EUR")
+}
+
+func TestController_ProblemRequestID(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+
+	rec := f.Do(t, http.MethodGet, "/api/transactions?count=ten", "", http.Header{"X-Request-Id": {"req-42"}})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	problem := decodeProblem(t, rec.Body.Bytes())
+	assert.Equal(t, "req-42", problem.RequestID)
+	assert.Equal(t, "/api/transactions", problem.Instance)
+	assert.Equal(t, "urn:wallet:problem:invalid-request-body", problem.Type)
+}
@@ -2,9 +2,9 @@ package gin
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 
 	"github.com/lunn06/wallet/internal/dtos"
 )
@@ -15,18 +15,26 @@ func (gc *Controller) GetBalance(c *gin.Context) {
 		Address: address,
 	}
 
-	if err := binding.Validator.ValidateStruct(dto); err != nil {
-		c.JSON(http.StatusBadRequest, dtos.ErrorResp{Error: "INVALID_REQUEST_BODY"})
+	// optional moment of point-in-time balance in RFC 3339 format
+	if at, ok := c.GetQuery("at"); ok {
+		moment, err := time.Parse(time.RFC3339Nano, at)
+		if err != nil {
+			writeInvalidRequest(c, newTimeParamError("at"))
+			return
+		}
+		dto.At = &moment
+	}
+
+	if err := gc.validator.ValidateStruct(dto); err != nil {
+		writeInvalidRequest(c, err)
 		return
 	}
 
 	response, err := gc.walletUc.GetBalance(c, dto)
 	if err != nil {
-		gc.logger.Error("error", "cause", err.Error())
-		code, errDto := handleErr(err)
-		c.JSON(code, errDto)
+		gc.writeErr(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, versionOf(c).mapper.GetBalance(response))
 }
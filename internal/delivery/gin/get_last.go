@@ -5,31 +5,33 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 
 	"github.com/lunn06/wallet/internal/dtos"
 )
 
 func (gc *Controller) GetLast(c *gin.Context) {
-	countStr, _ := c.GetQuery("count")
-	count, _ := strconv.Atoi(countStr)
-
-	dto := dtos.GetLastRequest{
-		Count: count,
+	var dto dtos.GetLastRequest
+
+	// missing count means zero, but malformed one is rejected
+	if countStr, ok := c.GetQuery("count"); ok {
+		count, err := strconv.Atoi(countStr)
+		if err != nil {
+			writeInvalidRequest(c, newParamError("count", "integer", "must be integer"))
+			return
+		}
+		dto.Count = count
 	}
 
-	if err := binding.Validator.ValidateStruct(dto); err != nil {
-		c.JSON(http.StatusBadRequest, dtos.ErrorResp{Error: "INVALID_REQUEST_BODY"})
+	if err := gc.validator.ValidateStruct(dto); err != nil {
+		writeInvalidRequest(c, err)
 		return
 	}
 
 	response, err := gc.transactionUc.GetLast(c, dto)
 	if err != nil {
-		gc.logger.Error("error", "cause", err.Error())
-		code, errDto := handleErr(err)
-		c.JSON(code, errDto)
+		gc.writeErr(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, versionOf(c).mapper.GetLast(response))
 }
@@ -0,0 +1,97 @@
+package gin
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/pkg/zapslog"
+)
+
+// requestIDHeader is header of request id set by client or proxy
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey is key of request id in gin context
+const requestIDKey = "request_id"
+
+// validRequestID restricts echoed request ids, so they are safe in headers and logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// requestIDMiddleware identifies request by request id header if client sent
+// valid one, or by trace id of request span, or by random uuid. Id is returned
+// in response header, problems and logs of request, so request can be found
+// in logs and traces. Must follow tracing middleware
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+			if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+				id = spanContext.TraceID().String()
+			}
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request.id", id))
+		c.Request = c.Request.WithContext(zapslog.ContextWithAttrs(c.Request.Context(), slog.String(requestIDKey, id)))
+
+		c.Next()
+	}
+}
+
+// requestID identifies request in problem
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// bodyLimitMiddleware rejects request bodies larger than limit, non positive limit
+// disables it. Declared length is checked before reading, chunked bodies fail
+// on reading past limit and are reported by writeInvalidRequest
+func bodyLimitMiddleware(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			writeProblem(c, newPayloadTooLarge(limit))
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+func newPayloadTooLarge(limit int64) dtos.Problem {
+	return newProblem(codePayloadTooLarge, fmt.Sprintf("request body must not exceed %d bytes", limit))
+}
+
+// recoveryMiddleware logs panic of handler with stack
+// and responds with internal problem instead of empty 500
+func (gc *Controller) recoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		gc.logger.ErrorContext(
+			c, "panic recovered",
+			slog.String("panic", fmt.Sprint(recovered)),
+			slog.String("stack", string(debug.Stack())),
+		)
+
+		// streamed response can't be replaced by problem
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		writeProblem(c, newProblem(codeInternal, ""))
+	})
+}
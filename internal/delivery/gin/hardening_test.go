@@ -0,0 +1,102 @@
+package gin_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_RequestID(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+
+	tests := []struct {
+		name   string
+		sent   string
+		echoed bool
+	}{
+		{name: "echoed", sent: "req-42", echoed: true},
+		{name: "generated without header"},
+		{name: "replaced when too long", sent: strings.Repeat("a", 129)},
+		{name: "replaced when unsafe", sent: "req\x01<script>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.sent != "" {
+				header.Set("X-Request-ID", tt.sent)
+			}
+
+			rec := f.Do(t, http.MethodGet, "/api/v1/transactions?count=ten", "", header)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+
+			id := rec.Header().Get("X-Request-ID")
+			if tt.echoed {
+				assert.Equal(t, tt.sent, id)
+			} else {
+				_, err := uuid.Parse(id)
+				assert.NoError(t, err, "generated id %q", id)
+			}
+			assert.Equal(t, id, decodeProblem(t, rec.Body.Bytes()).RequestID)
+		})
+	}
+}
+
+func TestController_BodyLimit(t *testing.T) {
+	const limit = 256
+	f := testutil.NewServer(t, func(cfg *config.Config) {
+		cfg.HTTPServer.MaxBodyBytes = limit
+	})
+	from, to := f.InsertWallet(t, "10"), f.InsertWallet(t, "0")
+	body := fmt.Sprintf(`{"from": %q, "to": %q, "amount": "1"}`, from.Address, to.Address)
+
+	t.Run("within limit", func(t *testing.T) {
+		rec := f.Do(t, http.MethodPost, "/api/v1/send", body, nil)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	})
+	t.Run("declared length over limit", func(t *testing.T) {
+		rec := f.Do(t, http.MethodPost, "/api/v1/send", body+strings.Repeat(" ", limit), nil)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+		problem := decodeProblem(t, rec.Body.Bytes())
+		assert.Equal(t, "PAYLOAD_TOO_LARGE", problem.Code)
+		assert.Equal(t, "request body must not exceed 256 bytes", problem.Detail)
+	})
+	t.Run("chunked body over limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/send", strings.NewReader(strings.Repeat(" ", limit)+body))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+
+		rec := httptest.NewRecorder()
+		f.Handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Equal(t, "PAYLOAD_TOO_LARGE", decodeProblem(t, rec.Body.Bytes()).Code)
+	})
+}
+
+func TestController_PanicRecovery(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	engine, ok := f.Handler.(*gin.Engine)
+	require.True(t, ok)
+	engine.GET("/api/v1/panic", func(*gin.Context) {
+		panic("boom")
+	})
+
+	rec := f.Do(t, http.MethodGet, "/api/v1/panic", "", http.Header{"X-Request-Id": {"req-panic"}})
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	problem := decodeProblem(t, rec.Body.Bytes())
+	assert.Equal(t, "INTERNAL_SERVER_ERROR", problem.Code)
+	assert.Equal(t, "req-panic", problem.RequestID)
+	assert.NotContains(t, rec.Body.String(), "boom")
+}
@@ -0,0 +1,29 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+// Live reports that process is alive
+func (gc *Controller) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gc.probe.Live())
+}
+
+// Ready reports dependencies checks and workers states,
+// it responds 503 while some check fails or app is shutting down
+func (gc *Controller) Ready(c *gin.Context) {
+	response, ready := gc.probe.Ready(c)
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
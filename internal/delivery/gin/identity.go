@@ -0,0 +1,37 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/domain/usecase"
+)
+
+// callerMiddleware identifies caller by subject of client certificate,
+// that was verified by mutual TLS, and passes identity, address and request
+// id to usecases for audit. Must follow request id middleware
+func callerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := usecase.WithRequestInfo(c.Request.Context(), usecase.RequestInfo{
+			SourceIP:  c.ClientIP(),
+			RequestID: requestID(c),
+		})
+		if subject, ok := clientSubject(c.Request); ok {
+			ctx = usecase.WithCaller(ctx, subject)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// clientSubject returns subject of verified client certificate, e.g.
+// "CN=ops,O=Wallet". Unverified peer certificates are ignored
+func clientSubject(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	return r.TLS.VerifiedChains[0][0].Subject.String(), true
+}
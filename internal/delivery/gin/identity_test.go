@@ -0,0 +1,45 @@
+package gin_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_AdminClientSubjects(t *testing.T) {
+	f := testutil.NewServer(t, func(cfg *config.Config) {
+		cfg.Admin = config.Admin{ClientSubjects: []string{"CN=ops,O=Wallet"}}
+	})
+
+	verifyChain := func(subject *pkix.Name, verified bool) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/chain/verify", nil)
+		if subject != nil {
+			cert := &x509.Certificate{Subject: *subject}
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			if verified {
+				req.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		f.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	ops := &pkix.Name{CommonName: "ops", Organization: []string{"Wallet"}}
+	assert.Equal(t, http.StatusOK, verifyChain(ops, true))
+	assert.Equal(t, http.StatusUnauthorized, verifyChain(ops, false), "unverified certificate")
+	assert.Equal(t, http.StatusUnauthorized, verifyChain(&pkix.Name{CommonName: "dev"}, true))
+	assert.Equal(t, http.StatusUnauthorized, verifyChain(nil, false))
+
+	rec := f.Do(t, http.MethodGet, "/api/v1/admin/chain/verify", "", http.Header{"Authorization": {"Bearer "}})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "empty token must not match disabled token")
+}
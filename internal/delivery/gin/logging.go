@@ -0,0 +1,69 @@
+package gin
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	sloggin "github.com/samber/slog-gin"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// requestLogger logs served requests, successful ones with level of config.
+// Request id is added to its records by context of request
+func requestLogger(logger *slog.Logger, cfg config.RequestLog) gin.HandlerFunc {
+	return sloggin.NewWithConfig(logger, sloggin.Config{
+		DefaultLevel:     parseLevel(cfg.Level),
+		ClientErrorLevel: slog.LevelWarn,
+		ServerErrorLevel: slog.LevelError,
+		Filters: []sloggin.Filter{
+			// failed probes must still be visible
+			func(c *gin.Context) bool {
+				return c.Writer.Status() >= http.StatusBadRequest || !slices.Contains(cfg.SkipPaths, c.Request.URL.Path)
+			},
+		},
+	})
+}
+
+// parseLevel parses level of config, that is already validated, info by default
+func parseLevel(level string) slog.Level {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return parsed
+}
+
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// GetLogLevel responds with current level of app logger
+func (gc *Controller) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, dtos.LogLevel{Level: levelName(gc.level.Level())})
+}
+
+// SetLogLevel changes level of app logger until restart
+func (gc *Controller) SetLogLevel(c *gin.Context) {
+	var dto dtos.LogLevel
+	if err := gc.bindJSON(c, &dto); err != nil {
+		writeInvalidRequest(c, err)
+		return
+	}
+
+	before := levelName(gc.level.Level())
+	gc.level.Set(parseLevel(dto.Level))
+	gc.auditUc.Record(c, models.AuditRecord{
+		Action: models.AuditLogLevel,
+		Before: models.AuditState{"level": before},
+		After:  models.AuditState{"level": dto.Level},
+	}, nil)
+	gc.logger.WarnContext(c, "Log level changed", "from", before, "to", dto.Level)
+
+	c.JSON(http.StatusOK, dto)
+}
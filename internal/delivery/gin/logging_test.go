@@ -0,0 +1,39 @@
+package gin_test
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_LogLevel(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	admin := http.Header{"Authorization": {"Bearer " + testutil.AdminToken}}
+
+	rec := f.Do(t, http.MethodGet, "/api/v1/admin/log/level", "", admin)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level": "info"}`, rec.Body.String())
+
+	rec = f.Do(t, http.MethodPut, "/api/v1/admin/log/level", `{"level": "debug"}`, admin)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"level": "debug"}`, rec.Body.String())
+	assert.Equal(t, slog.LevelDebug, f.Level.Level())
+
+	// change of level is audited
+	rec = f.Do(t, http.MethodGet, "/api/v1/admin/audit", "", admin)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"action":"log.level"`)
+
+	rec = f.Do(t, http.MethodPut, "/api/v1/admin/log/level", `{"level": "verbose"}`, admin)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, slog.LevelDebug, f.Level.Level())
+
+	rec = f.Do(t, http.MethodPut, "/api/v1/admin/log/level", `{"level": "error"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, slog.LevelDebug, f.Level.Level())
+}
@@ -0,0 +1,49 @@
+package gin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const unmatchedRoute = "unmatched"
+
+// httpObserver receives stats of every handled request (e.g. metrics.Metrics)
+type httpObserver interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// metricsMiddleware reports request count and latency per route and status.
+// Route is taken from gin.Context.FullPath to keep labels cardinality bounded
+func metricsMiddleware(observer httpObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		observer.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// metricsTokenMiddleware aborts requests without metrics token as bearer token
+func metricsTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasBearer(c, token) {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			writeProblem(c, newProblem(codeUnauthorized, ""))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Metrics exposes metrics in Prometheus format
+func (gc *Controller) Metrics(c *gin.Context) {
+	gc.metricsHandler.ServeHTTP(c.Writer, c.Request)
+}
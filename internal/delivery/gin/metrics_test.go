@@ -0,0 +1,61 @@
+package gin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_Metrics(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	engine, ok := f.Handler.(*gin.Engine)
+	require.True(t, ok)
+	engine.GET("/api/v1/panic", func(*gin.Context) {
+		panic("boom")
+	})
+
+	require.Equal(t, http.StatusInternalServerError, f.Do(t, http.MethodGet, "/api/v1/panic", "", nil).Code)
+	require.Equal(t, http.StatusNotFound, f.Do(t, http.MethodGet, "/api/v1/missing/42", "", nil).Code)
+
+	t.Run("requests by route and status", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/metrics", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		body := rec.Body.String()
+		// recovered panic is observed as its response
+		assert.Contains(t, body, `wallet_http_requests_total{method="GET",route="/api/v1/panic",status="500"} 1`)
+		// paths without route don't make labels of their own
+		assert.Contains(t, body, `wallet_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+		assert.Contains(t, body, `wallet_http_request_duration_seconds_count{method="GET",route="/api/v1/panic",status="500"} 1`)
+	})
+	t.Run("public without admins", func(t *testing.T) {
+		f := testutil.NewServer(t, func(cfg *config.Config) {
+			cfg.Admin.Token = ""
+		})
+
+		rec := f.Do(t, http.MethodGet, "/metrics", "", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+	t.Run("metrics token", func(t *testing.T) {
+		f := testutil.NewServer(t, func(cfg *config.Config) {
+			cfg.Metrics.Token = "metrics-token"
+		})
+
+		rec := f.Do(t, http.MethodGet, "/metrics", "", nil)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "wallet_http_requests_total")
+
+		// admin token doesn't replace metrics token
+		rec = f.Do(t, http.MethodGet, "/metrics", "", http.Header{"Authorization": {"Bearer " + testutil.AdminToken}})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = f.Do(t, http.MethodGet, "/metrics", "", http.Header{"Authorization": {"Bearer metrics-token"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,290 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/pkg/openapi"
+)
+
+// route is definition of endpoint. Both gin route and its
+// OpenAPI operation are made of it, so docs can't drift from routes
+type route struct {
+	name    string
+	method  string
+	path    string // gin path, relative to api version for api routes
+	handler func(*Controller, *gin.Context)
+	admin   bool
+	// auditors are allowed to call admin route besides admins
+	auditors bool
+	// scraped route requires metrics token, if it's configured
+	scraped bool
+	// unversioned route was served before api versioning,
+	// so legacy version serves it under unversioned path too
+	unversioned bool
+
+	summary   string
+	params    []openapi.Parameter
+	body      any
+	responses []routeResponse
+	// problems are codes of problem responses besides common ones of every route
+	problems []string
+}
+
+// routeResponse is documented response, that isn't problem
+type routeResponse struct {
+	status       int
+	description  string
+	body         any
+	contentTypes []string // json by default
+}
+
+// Problems, that every api and admin route may respond with
+var (
+	commonProblems = []string{codeInternal, codeUnavailable}
+	adminProblems  = []string{codeUnauthorized}
+)
+
+var (
+	addressParam = openapi.Parameter{
+		Name:     "address",
+		In:       openapi.InPath,
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string", Format: "uuid"},
+	}
+	readYourWritesParam = openapi.Parameter{
+		Name:        readYourWritesHeader,
+		In:          openapi.InHeader,
+		Description: "true forces reads from primary database",
+		Schema:      &openapi.Schema{Type: "boolean"},
+	}
+)
+
+// apiRoutes are routes of every api version
+var apiRoutes = []route{
+	{
+		name:        "send",
+		method:      http.MethodPost,
+		path:        "/send",
+		handler:     (*Controller).Send,
+		unversioned: true,
+		summary:     "Send balance between wallets",
+		body:        dtos.SendRequest{},
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.SendResponse{}},
+		},
+		problems: []string{
+			codeInvalidRequest, codeInvalidArgument, codeLackOfCurrency, codeWalletFrozen, codeNotFound,
+		},
+	},
+	{
+		name:        "getLast",
+		method:      http.MethodGet,
+		path:        "/transactions",
+		handler:     (*Controller).GetLast,
+		unversioned: true,
+		summary:     "Get last transactions",
+		params: []openapi.Parameter{
+			{
+				Name:        "count",
+				In:          openapi.InQuery,
+				Description: "Count of transactions",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: new(float64), Default: 0},
+			},
+			readYourWritesParam,
+		},
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.GetLastResponse{}},
+		},
+		problems: []string{codeInvalidRequest},
+	},
+	{
+		name:        "getBalance",
+		method:      http.MethodGet,
+		path:        "/wallet/:address/balance",
+		handler:     (*Controller).GetBalance,
+		unversioned: true,
+		summary:     "Get wallet balance",
+		params: []openapi.Parameter{
+			addressParam,
+			{
+				Name:        "at",
+				In:          openapi.InQuery,
+				Description: "Moment of point-in-time balance, current balance by default",
+				Schema:      &openapi.Schema{Type: "string", Format: "date-time"},
+			},
+			readYourWritesParam,
+		},
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.GetBalanceResponse{}},
+		},
+		problems: []string{codeInvalidRequest, codeNotFound, codeHistoryUnavailable},
+	},
+	{
+		name:        "getStatement",
+		method:      http.MethodGet,
+		path:        "/wallet/:address/statement",
+		handler:     (*Controller).GetStatement,
+		unversioned: true,
+		summary:     "Export wallet statement",
+		params: []openapi.Parameter{
+			addressParam,
+			{
+				Name:        "from",
+				In:          openapi.InQuery,
+				Description: "Start of period, exclusive",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "string", Format: "date-time"},
+			},
+			{
+				Name:        "to",
+				In:          openapi.InQuery,
+				Description: "End of period, inclusive, now by default",
+				Schema:      &openapi.Schema{Type: "string", Format: "date-time"},
+			},
+			{
+				Name:   "format",
+				In:     openapi.InQuery,
+				Schema: &openapi.Schema{Type: "string", Enum: []any{dtos.StatementCSV, dtos.StatementJSONL}, Default: dtos.StatementCSV},
+			},
+			readYourWritesParam,
+		},
+		responses: []routeResponse{
+			{
+				status:       http.StatusOK,
+				description:  "Streamed rows: opening, transactions with running balance, closing",
+				body:         dtos.StatementRow{},
+				contentTypes: []string{"text/csv", "application/x-ndjson"},
+			},
+		},
+		problems: []string{codeInvalidRequest, codeNotFound, codeHistoryUnavailable},
+	},
+	{
+		name:        "verifyChain",
+		method:      http.MethodGet,
+		path:        adminPath + "/chain/verify",
+		handler:     (*Controller).VerifyChain,
+		unversioned: true,
+		admin:       true,
+		summary:     "Verify transactions hash chain",
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.ChainReport{}},
+			{status: http.StatusConflict, description: "Chain is broken", body: dtos.ChainReport{}},
+		},
+	},
+	{
+		name:     "queryAudit",
+		method:   http.MethodGet,
+		path:     adminPath + "/audit",
+		handler:  (*Controller).QueryAudit,
+		admin:    true,
+		auditors: true,
+		summary:  "Query audit log of state-changing actions",
+		params: []openapi.Parameter{
+			{Name: "actor", In: openapi.InQuery, Description: "Identity of caller", Schema: &openapi.Schema{Type: "string"}},
+			{
+				Name:        "wallet",
+				In:          openapi.InQuery,
+				Description: "Address of affected wallet or counterparty of transfer",
+				Schema:      &openapi.Schema{Type: "string", Format: "uuid"},
+			},
+			{
+				Name:        "from",
+				In:          openapi.InQuery,
+				Description: "Start of period, inclusive",
+				Schema:      &openapi.Schema{Type: "string", Format: "date-time"},
+			},
+			{
+				Name:        "to",
+				In:          openapi.InQuery,
+				Description: "End of period, exclusive",
+				Schema:      &openapi.Schema{Type: "string", Format: "date-time"},
+			},
+			{
+				Name:        "after_id",
+				In:          openapi.InQuery,
+				Description: "next_after_id of previous page",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: new(float64), Default: 0},
+			},
+			{
+				Name:        "limit",
+				In:          openapi.InQuery,
+				Description: "Count of records per page",
+				Schema:      &openapi.Schema{Type: "integer", Minimum: new(float64), Default: 100},
+			},
+		},
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.AuditPage{}},
+		},
+		problems: []string{codeInvalidRequest, codeInvalidArgument},
+	},
+	{
+		name:    "getLogLevel",
+		method:  http.MethodGet,
+		path:    adminPath + "/log/level",
+		handler: (*Controller).GetLogLevel,
+		admin:   true,
+		summary: "Get level of app logger",
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.LogLevel{}},
+		},
+	},
+	{
+		name:    "setLogLevel",
+		method:  http.MethodPut,
+		path:    adminPath + "/log/level",
+		handler: (*Controller).SetLogLevel,
+		admin:   true,
+		summary: "Change level of app logger until restart",
+		body:    dtos.LogLevel{},
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.LogLevel{}},
+		},
+		problems: []string{codeInvalidRequest, codePayloadTooLarge},
+	},
+}
+
+// systemRoutes are unversioned routes of probes and metrics
+var systemRoutes = []route{
+	{
+		name:    "live",
+		method:  http.MethodGet,
+		path:    livenessPath,
+		handler: (*Controller).Live,
+		summary: "Liveness probe",
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.LivenessResponse{}},
+		},
+	},
+	{
+		name:    "ready",
+		method:  http.MethodGet,
+		path:    readinessPath,
+		handler: (*Controller).Ready,
+		summary: "Readiness probe",
+		responses: []routeResponse{
+			{status: http.StatusOK, body: dtos.ReadinessResponse{}},
+			{status: http.StatusServiceUnavailable, description: "Some check fails or app is shutting down", body: dtos.ReadinessResponse{}},
+		},
+	},
+	{
+		name:    "metrics",
+		method:  http.MethodGet,
+		path:    metricsPath,
+		handler: (*Controller).Metrics,
+		scraped: true,
+		summary: "Prometheus metrics",
+		responses: []routeResponse{
+			{status: http.StatusOK, body: "", contentTypes: []string{"text/plain"}},
+		},
+	},
+}
+
+// handlerOf binds handler of route to controller
+func (gc *Controller) handlerOf(r route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r.handler(gc, c)
+	}
+}
@@ -10,18 +10,16 @@ import (
 
 func (gc *Controller) Send(c *gin.Context) {
 	var dto dtos.SendRequest
-	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, dtos.ErrorResp{Error: "INVALID_REQUEST_BODY"})
+	if err := gc.bindJSON(c, &dto); err != nil {
+		writeInvalidRequest(c, err)
 		return
 	}
 
 	response, err := gc.transactionUc.Send(c, dto)
 	if err != nil {
-		gc.logger.Error("error", "cause", err.Error())
-		code, errDto := handleErr(err)
-		c.JSON(code, errDto)
+		gc.writeErr(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, versionOf(c).mapper.Send(response))
 }
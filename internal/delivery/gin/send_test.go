@@ -0,0 +1,168 @@
+package gin_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_SendValidation(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	from := f.InsertWallet(t, "100")
+	to := f.InsertWallet(t, "0")
+
+	body := func(from, to, amount string) string {
+		return fmt.Sprintf(`{"from":%q,"to":%q,"amount":%q}`, from, to, amount)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		fields []dtos.FieldError
+	}{
+		{
+			name: "empty addresses",
+			body: body("", "", "1"),
+			fields: []dtos.FieldError{
+				{Field: "from", Rule: "required", Message: "is required"},
+				{Field: "to", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:   "non-uuid address",
+			body:   body(from.Address, "wallet", "1"),
+			fields: []dtos.FieldError{{Field: "to", Rule: "uuid4", Message: "must be uuid"}},
+		},
+		{
+			name:   "missing amount",
+			body:   body(from.Address, to.Address, ""),
+			fields: []dtos.FieldError{{Field: "amount", Rule: "required", Message: "is required"}},
+		},
+		{
+			name: "zero amount",
+			body: body(from.Address, to.Address, "0"),
+			fields: []dtos.FieldError{{
+				Field: "amount", Rule: "positive_decimal", Message: "must be positive decimal number without sign and exponent",
+			}},
+		},
+		{
+			name: "exponent",
+			body: body(from.Address, to.Address, "1e400"),
+			fields: []dtos.FieldError{
+				{Field: "amount", Rule: "positive_decimal", Message: "must be positive decimal number without sign and exponent"},
+			},
+		},
+		{
+			name:   "too many decimal places",
+			body:   body(from.Address, to.Address, "0.000000000000000000000000000001"),
+			fields: []dtos.FieldError{{Field: "amount", Rule: "amount_scale", Message: "has too many decimal places"}},
+		},
+		{
+			name:   "too big amount",
+			body:   body(from.Address, to.Address, "1000000.01"),
+			fields: []dtos.FieldError{{Field: "amount", Rule: "amount_max", Message: "exceeds max amount"}},
+		},
+		{
+			name:   "unknown field",
+			body:   `{"from":"a","to":"b","amount":"1","currency":"RUB"}`,
+			fields: []dtos.FieldError{{Field: "currency", Rule: "unknown", Message: "is unknown field"}},
+		},
+		{
+			name: "trailing json value",
+			body: body(from.Address, to.Address, "1") + `{"x":1}`,
+		},
+		{
+			name: "trailing garbage",
+			body: body(from.Address, to.Address, "1") + `}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.Do(t, http.MethodPost, "/api/v1/send", tt.body, nil)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+			problem := decodeProblem(t, rec.Body.Bytes())
+			assert.Equal(t, "INVALID_REQUEST_BODY", problem.Code)
+			assert.Equal(t, tt.fields, problem.Errors)
+		})
+	}
+
+	t.Run("trailing zeros within scale", func(t *testing.T) {
+		rec := f.Do(t, http.MethodPost, "/api/v1/send", body(from.Address, to.Address, "1.5000"), nil)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	})
+	t.Run("gin globals are kept", func(t *testing.T) {
+		// other gin engines of process must not get rules of controller
+		assert.False(t, binding.EnableDecoderDisallowUnknownFields)
+		// default validator of gin checks binding tags only
+		assert.NoError(t, binding.Validator.ValidateStruct(struct {
+			Amount string `validate:"required"`
+		}{}))
+	})
+}
+
+// validAmount is amount accepted with transfer limits of fixture
+var validAmount = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// FuzzController_Send checks that no request body makes Send fail with
+// server error or move amount, that violates transfer limits
+func FuzzController_Send(f *testing.F) {
+	f.Add("1", "", "", "")
+	f.Add("0.01", "", "", "")
+	f.Add("1e400", "", "", "")
+	f.Add("-1", "", "", "")
+	f.Add("0.000000000000000000000000000001", "", "", "")
+	f.Add("99999999999999999999999999999999999999999", "", "", "")
+	f.Add("1", "not-uuid", "", "")
+	f.Add("1", "", "", `,"extra":true`)
+	f.Add(" 1", "", "", "")
+	f.Add("1.", "", "", "")
+	f.Add("١", "", "", "")
+
+	f.Fuzz(func(t *testing.T, amount, fromOverride, toOverride, extra string) {
+		fx := testutil.NewServer(t, nil)
+		from := fx.InsertWallet(t, testutil.MaxAmount)
+		to := fx.InsertWallet(t, "0")
+
+		if fromOverride != "" {
+			from.Address = fromOverride
+		}
+		if toOverride != "" {
+			to.Address = toOverride
+		}
+
+		fields, err := json.Marshal(map[string]string{"from": from.Address, "to": to.Address, "amount": amount})
+		require.NoError(t, err)
+		body := string(fields[:len(fields)-1]) + extra + "}"
+
+		rec := fx.Do(t, http.MethodPost, "/api/v1/send", body, nil)
+		require.Less(t, rec.Code, http.StatusInternalServerError, rec.Body.String())
+
+		if rec.Code != http.StatusOK {
+			assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+			return
+		}
+
+		require.Regexp(t, validAmount, amount)
+		d, err := decimal.NewFromString(amount)
+		require.NoError(t, err)
+		assert.True(t, d.IsPositive(), amount)
+		assert.True(t, d.Equal(d.Truncate(2)), amount)
+		assert.True(t, d.LessThanOrEqual(decimal.RequireFromString(testutil.MaxAmount)), amount)
+
+		received, err := fx.Wallet.GetByAddress(context.Background(), to.Address)
+		require.NoError(t, err)
+		assert.True(t, d.Equal(received.Balance.Decimal()), "received %s, sent %s", received.Balance.Decimal(), amount)
+	})
+}
@@ -0,0 +1,171 @@
+package gin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// statementFlushRows is count of statement rows sent to client at once,
+// write deadline of connection is extended after every flush
+const statementFlushRows = 1000
+
+// responseControllerKey is gin.Context key of *http.ResponseController
+// of original response writer, as wrappers (e.g. of sloggin) hide its Unwrap method
+const responseControllerKey = "response_controller"
+
+func responseControllerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(responseControllerKey, http.NewResponseController(c.Writer))
+		c.Next()
+	}
+}
+
+func (gc *Controller) GetStatement(c *gin.Context) {
+	dto := dtos.StatementRequest{
+		Address: c.Param("address"),
+		To:      time.Now().UTC(),
+		Format:  c.DefaultQuery("format", dtos.StatementCSV),
+	}
+
+	// period bounds in RFC 3339 format, to is now by default
+	var err error
+	if dto.From, err = time.Parse(time.RFC3339Nano, c.Query("from")); err != nil {
+		writeInvalidRequest(c, newTimeParamError("from"))
+		return
+	}
+	if to, ok := c.GetQuery("to"); ok {
+		if dto.To, err = time.Parse(time.RFC3339Nano, to); err != nil {
+			writeInvalidRequest(c, newTimeParamError("to"))
+			return
+		}
+	}
+
+	if err := gc.validator.ValidateStruct(dto); err != nil {
+		writeInvalidRequest(c, err)
+		return
+	}
+
+	// every download holds database connection until it's sent, so
+	// slow clients must not take all connections of pool
+	if !gc.statements.TryAcquire() {
+		writeProblem(c, newProblem(codeUnavailable, "too many statements are downloaded, retry later"))
+		return
+	}
+	defer gc.statements.Release()
+
+	ctx, cancel := context.WithTimeout(c, gc.config.Statement.Timeout)
+	defer cancel()
+
+	encoder := newStatementEncoder(dto.Format, c.Writer)
+	rows := 0
+	write := func(row dtos.StatementRow) error {
+		// headers are sent with the first row, so errors
+		// before it are still reported with status code
+		if rows == 0 {
+			c.Header("Content-Type", encoder.contentType)
+			c.Header("Content-Disposition", fmt.Sprintf(
+				`attachment; filename="statement-%s.%s"`, dto.Address, dto.Format,
+			))
+			c.Status(http.StatusOK)
+		}
+		rows++
+
+		if err := encoder.write(row); err != nil {
+			return err
+		}
+		if rows%statementFlushRows == 0 {
+			return gc.flushStatement(c, encoder)
+		}
+		return nil
+	}
+
+	if err := gc.statementUc.Statement(ctx, dto, write); err != nil {
+		if rows == 0 {
+			gc.writeErr(c, err)
+			return
+		}
+		gc.logger.ErrorContext(c, "error", "cause", err.Error(), "rows", rows)
+		// status is already sent, client notices
+		// truncated statement by missing closing row
+		_ = c.Error(err)
+	}
+
+	if err := encoder.flush(); err != nil {
+		gc.logger.ErrorContext(c, "failed to flush statement", "cause", err.Error())
+	}
+}
+
+// flushStatement sends buffered rows to client and
+// extends write deadline, that bounds only one flush
+func (gc *Controller) flushStatement(c *gin.Context, encoder *statementEncoder) error {
+	if err := encoder.flush(); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+
+	if rc, ok := c.Value(responseControllerKey).(*http.ResponseController); ok {
+		_ = rc.SetWriteDeadline(time.Now().Add(gc.config.HTTPServer.WriteTimeout))
+	}
+
+	return nil
+}
+
+// statementHeader is header row of csv statement
+var statementHeader = []string{"type", "id", "timestamp", "from_address", "to_address", "amount", "balance"}
+
+// statementEncoder writes statement rows as csv or json lines
+type statementEncoder struct {
+	contentType string
+	csv         *csv.Writer
+	json        *json.Encoder
+}
+
+func newStatementEncoder(format string, w http.ResponseWriter) *statementEncoder {
+	if format == dtos.StatementJSONL {
+		return &statementEncoder{contentType: "application/x-ndjson", json: json.NewEncoder(w)}
+	}
+	return &statementEncoder{contentType: "text/csv; charset=utf-8", csv: csv.NewWriter(w)}
+}
+
+func (e *statementEncoder) write(row dtos.StatementRow) error {
+	if e.json != nil {
+		return e.json.Encode(row)
+	}
+
+	if row.Type == dtos.StatementOpening {
+		if err := e.csv.Write(statementHeader); err != nil {
+			return err
+		}
+	}
+
+	id := ""
+	if row.ID != 0 {
+		id = strconv.Itoa(row.ID)
+	}
+	return e.csv.Write([]string{
+		row.Type,
+		id,
+		row.Timestamp.Format(time.RFC3339Nano),
+		row.FromAddress,
+		row.ToAddress,
+		row.Amount,
+		row.Balance,
+	})
+}
+
+func (e *statementEncoder) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
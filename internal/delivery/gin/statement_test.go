@@ -0,0 +1,40 @@
+package gin_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_GetStatement(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	wallet := f.InsertWallet(t, "10")
+	from := time.Now().UTC()
+	target := fmt.Sprintf("/api/v1/wallet/%s/statement?from=%s", wallet.Address, from.Format(time.RFC3339Nano))
+
+	rec := f.Do(t, http.MethodGet, target+"&format=jsonl", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"type":"opening"`)
+	assert.Contains(t, lines[1], `"type":"closing"`)
+
+	// downloads are bounded by semaphore, that is released after download
+	rec = f.Do(t, http.MethodGet, "/metrics", "", http.Header{"Authorization": {"Bearer " + testutil.AdminToken}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `wallet_semaphore_size{semaphore="statement"} 1`)
+	assert.Contains(t, rec.Body.String(), `wallet_semaphore_holders{semaphore="statement"} 0`)
+
+	rec = f.Do(t, http.MethodGet, target, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "type,id,timestamp"), rec.Body.String())
+}
@@ -0,0 +1,93 @@
+package gin_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+// recordSpans makes global tracer provider record spans. Controller tracer
+// is delegated to the first provider only, so it's set once per process
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return spans
+}
+
+// lastSpan returns the last ended span
+func lastSpan(t *testing.T, recorder *tracetest.SpanRecorder) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	ended := recorder.Ended()
+	require.NotEmpty(t, ended)
+
+	return ended[len(ended)-1]
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes()))
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestController_Tracing(t *testing.T) {
+	recorder := recordSpans()
+	f := testutil.NewServer(t, nil)
+	w := f.InsertWallet(t, "1")
+
+	t.Run("continues incoming trace", func(t *testing.T) {
+		const (
+			traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+			spanID  = "00f067aa0ba902b7"
+		)
+
+		rec := f.Do(t, http.MethodGet, "/api/v1/wallet/"+w.Address+"/balance", "", http.Header{
+			"Traceparent": {"00-" + traceID + "-" + spanID + "-01"},
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		span := lastSpan(t, recorder)
+		assert.Equal(t, "GET /api/v1/wallet/:address/balance", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+		assert.Equal(t, spanID, span.Parent().SpanID().String())
+		assert.True(t, span.Parent().IsRemote())
+
+		attrs := attributes(span)
+		assert.Equal(t, "/api/v1/wallet/:address/balance", attrs["http.route"].AsString())
+		assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+	t.Run("starts new trace", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/api/v1/unknown", "", nil)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		span := lastSpan(t, recorder)
+		assert.Equal(t, "GET unmatched", span.Name())
+		assert.True(t, span.SpanContext().IsValid())
+		assert.False(t, span.Parent().IsValid())
+		assert.Equal(t, int64(http.StatusNotFound), attributes(span)["http.response.status_code"].AsInt64())
+	})
+}
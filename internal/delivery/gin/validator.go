@@ -1,32 +1,112 @@
 package gin
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"sync"
 
-	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/utils/validation"
 )
 
 // defaultValidator is wrapper of external validator.Validate type
+// with custom rules of amounts, that are checked against transfer limits
 type defaultValidator struct {
 	once     sync.Once
 	validate *validator.Validate
+	limits   config.Transfer
 }
 
-var _ binding.StructValidator = &defaultValidator{}
+func newDefaultValidator(limits config.Transfer) *defaultValidator {
+	return &defaultValidator{limits: limits}
+}
 
 func (v *defaultValidator) ValidateStruct(obj any) error {
 	v.lazyinit()
 	return v.validate.Struct(obj)
 }
 
-func (v *defaultValidator) Engine() any {
-	v.lazyinit()
-	return v.validate
-}
-
 func (v *defaultValidator) lazyinit() {
 	v.once.Do(func() {
 		v.validate = validator.New(validator.WithRequiredStructEnabled())
+
+		// report fields by json names, as client see them in request
+		v.validate.RegisterTagNameFunc(validation.TagName("json"))
+
+		maxAmount, err := decimal.NewFromString(v.limits.MaxAmount)
+		if err != nil {
+			// config is validated, so it's programming error
+			panic(fmt.Sprintf("invalid max amount %q: %v", v.limits.MaxAmount, err))
+		}
+
+		_ = v.validate.RegisterValidation("positive_decimal", validation.PositiveDecimal)
+		_ = v.validate.RegisterValidation("amount_scale", func(fl validator.FieldLevel) bool {
+			amount, ok := validation.ParsePlainDecimal(fl.Field().String())
+			// trailing zeros don't exceed scale, e.g. 1.50 with scale 1
+			return ok && amount.Equal(amount.Truncate(v.limits.MaxScale))
+		})
+		_ = v.validate.RegisterValidation("amount_max", func(fl validator.FieldLevel) bool {
+			amount, ok := validation.ParsePlainDecimal(fl.Field().String())
+			return ok && amount.LessThanOrEqual(maxAmount)
+		})
 	})
 }
+
+// errTrailingData is returned for request body with data after json value
+var errTrailingData = errors.New("request body must contain single json value")
+
+// bindJSON decodes json body of request to obj and validates it.
+// Unknown fields and data after json value are rejected, as typo
+// in request body must not be silently ignored
+func (gc *Controller) bindJSON(c *gin.Context, obj any) error {
+	if c.Request.Body == nil {
+		return io.EOF
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+
+	return gc.validator.ValidateStruct(obj)
+}
+
+// fieldErrors translates validator.ValidationErrors to field errors of problem,
+// other errors aren't validation ones and give nil
+func fieldErrors(err error) []dtos.FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make([]dtos.FieldError, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		fields[i] = dtos.FieldError{
+			Field:   validation.FieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: translator.Describe(fieldErr),
+		}
+	}
+
+	return fields
+}
+
+// translator describes failed rules of requests, values of
+// requests aren't echoed back in case they are sensitive
+var translator = validation.Translator{
+	Custom: map[string]func(validator.FieldError) string{
+		"amount_scale": func(validator.FieldError) string { return "has too many decimal places" },
+		"amount_max":   func(validator.FieldError) string { return "exceeds max amount" },
+	},
+}
@@ -0,0 +1,100 @@
+package gin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// apiVersion is group of endpoints under common path. Handlers are shared
+// between versions, version specific response shapes are made by mapper
+type apiVersion struct {
+	name   string
+	path   string
+	mapper responseMapper
+
+	// deprecated version is answered with Deprecation, Sunset and
+	// Link to successor headers, zero deprecatedAt means actual version
+	deprecatedAt time.Time
+	sunsetAt     time.Time
+	successor    string
+	// legacy version serves only routes, that were served before versioning
+	legacy bool
+}
+
+// serves reports whether route is served under path of version
+func (v apiVersion) serves(r route) bool {
+	return !v.legacy || r.unversioned
+}
+
+// responseMapper maps usecases responses to response bodies of api version
+type responseMapper interface {
+	Send(dtos.SendResponse) any
+	GetLast(dtos.GetLastResponse) any
+	GetBalance(dtos.GetBalanceResponse) any
+}
+
+// v1Mapper keeps usecases responses as is, they are v1 shapes.
+// Later versions map them to own shapes and leave v1 unchanged
+type v1Mapper struct{}
+
+func (v1Mapper) Send(resp dtos.SendResponse) any             { return resp }
+func (v1Mapper) GetLast(resp dtos.GetLastResponse) any       { return resp }
+func (v1Mapper) GetBalance(resp dtos.GetBalanceResponse) any { return resp }
+
+var (
+	apiV1 = apiVersion{
+		name:   "v1",
+		path:   basePath + "/v1",
+		mapper: v1Mapper{},
+	}
+	// apiLegacy is v1 under unversioned paths, which were served before versioning
+	apiLegacy = apiVersion{
+		name:         "legacy",
+		path:         basePath,
+		mapper:       v1Mapper{},
+		deprecatedAt: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		sunsetAt:     time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		successor:    apiV1.path,
+		legacy:       true,
+	}
+
+	// apiVersions are served api versions, the first one is the latest
+	apiVersions = []apiVersion{apiV1, apiLegacy}
+)
+
+const apiVersionKey = "api_version"
+
+// versionMiddleware makes version available for shared handlers
+// and marks responses of deprecated version
+func versionMiddleware(version apiVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+
+		if !version.deprecatedAt.IsZero() {
+			// RFC 9745 date is structured field of unix seconds
+			c.Header("Deprecation", "@"+strconv.FormatInt(version.deprecatedAt.Unix(), 10))
+			// RFC 8594
+			c.Header("Sunset", version.sunsetAt.Format(http.TimeFormat))
+
+			successor := version.successor + strings.TrimPrefix(c.Request.URL.Path, version.path)
+			c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+
+		c.Next()
+	}
+}
+
+// versionOf returns api version of request, the latest one out of version groups
+func versionOf(c *gin.Context) apiVersion {
+	if version, ok := c.Get(apiVersionKey); ok {
+		return version.(apiVersion)
+	}
+
+	return apiVersions[0]
+}
@@ -0,0 +1,100 @@
+package gin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+func TestController_Versions(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+	w := f.InsertWallet(t, "10")
+
+	t.Run("actual version", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/api/v1/wallet/"+w.Address+"/balance", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"balance":"10"}`, rec.Body.String())
+
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+		assert.Empty(t, rec.Header().Get("Sunset"))
+	})
+	t.Run("legacy routes are deprecated", func(t *testing.T) {
+		rec := f.Do(t, http.MethodGet, "/api/wallet/"+w.Address+"/balance", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"balance":"10"}`, rec.Body.String())
+
+		assert.Equal(t, "@1792368000", rec.Header().Get("Deprecation"))
+		assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+		assert.Equal(t,
+			`</api/v1/wallet/`+w.Address+`/balance>; rel="successor-version"`,
+			rec.Header().Get("Link"),
+		)
+	})
+	t.Run("legacy admin routes are deprecated", func(t *testing.T) {
+		header := http.Header{"Authorization": {"Bearer " + testutil.AdminToken}}
+		rec := f.Do(t, http.MethodGet, "/api/admin/chain/verify", "", header)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		assert.NotEmpty(t, rec.Header().Get("Deprecation"))
+		assert.Equal(t, `</api/v1/admin/chain/verify>; rel="successor-version"`, rec.Header().Get("Link"))
+
+		rec = f.Do(t, http.MethodGet, "/api/v1/admin/chain/verify", "", header)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+	})
+	t.Run("routes added after versioning are versioned only", func(t *testing.T) {
+		header := http.Header{"Authorization": {"Bearer " + testutil.AdminToken}}
+		for _, path := range []string{"/admin/audit", "/admin/log/level"} {
+			assert.Equal(t, http.StatusNotFound, f.Do(t, http.MethodGet, "/api"+path, "", header).Code, path)
+			assert.Equal(t, http.StatusOK, f.Do(t, http.MethodGet, "/api/v1"+path, "", header).Code, path)
+		}
+	})
+}
+
+func TestController_VersionDocs(t *testing.T) {
+	f := testutil.NewServer(t, nil)
+
+	tests := []struct {
+		prefix     string
+		version    string
+		send       string
+		paths      int
+		deprecated bool
+	}{
+		{prefix: "/api/v1/swagger", version: "v1", send: "/api/v1/send", paths: 7},
+		{prefix: "/api/swagger", version: "legacy", send: "/api/send", paths: 5, deprecated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			rec := f.Do(t, http.MethodGet, tt.prefix+"/doc.json", "", nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var doc struct {
+				Info struct {
+					Version     string `json:"version"`
+					Description string `json:"description"`
+				} `json:"info"`
+				Paths map[string]map[string]struct {
+					Deprecated bool `json:"deprecated"`
+				} `json:"paths"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+
+			assert.Equal(t, tt.version, doc.Info.Version)
+			assert.Len(t, doc.Paths, tt.paths, "only routes of version are documented")
+			require.Contains(t, doc.Paths, tt.send)
+			assert.Equal(t, tt.deprecated, doc.Paths[tt.send]["post"].Deprecated)
+			if tt.deprecated {
+				assert.Contains(t, doc.Info.Description, "Deprecated")
+			}
+
+			rec = f.Do(t, http.MethodGet, tt.prefix+"/index.html", "", nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Audited actions, that change state of wallets or app
+const (
+	AuditTransfer       = "transfer"
+	AuditWalletCreate   = "wallet.create"
+	AuditWalletSeed     = "wallet.seed"
+	AuditWalletFreeze   = "wallet.freeze"
+	AuditWalletUnfreeze = "wallet.unfreeze"
+	AuditTreasuryMint   = "treasury.mint"
+	AuditTreasuryBurn   = "treasury.burn"
+	AuditLogLevel       = "log.level"
+)
+
+// Outcomes of audited actions
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditState is audited state of wallet or operation, e.g. its balance
+type AuditState map[string]string
+
+// AuditRecord is append-only record of action. Actor is identity of caller,
+// Wallet is address of affected wallet and Counterparty is address of the
+// other wallet of transfer. Before and After are state changed by action,
+// they are empty if state didn't exist or action failed. Error is cause of failure
+type AuditRecord struct {
+	ID           int
+	Timestamp    time.Time
+	Actor        string
+	SourceIP     string
+	RequestID    string
+	Action       string
+	Wallet       string
+	Counterparty string
+	Before       AuditState
+	After        AuditState
+	Outcome      string
+	Error        string
+}
+
+// AuditFilter selects audit records, zero fields match any record. Wallet
+// matches both Wallet and Counterparty, period is [From, To)
+type AuditFilter struct {
+	Actor  string
+	Wallet string
+	From   time.Time
+	To     time.Time
+}
+
+// Match reports whether record is selected by filter
+func (f AuditFilter) Match(record AuditRecord) bool {
+	switch {
+	case f.Actor != "" && record.Actor != f.Actor:
+		return false
+	case f.Wallet != "" && record.Wallet != f.Wallet && record.Counterparty != f.Wallet:
+		return false
+	case !f.From.IsZero() && record.Timestamp.Before(f.From):
+		return false
+	case !f.To.IsZero() && !record.Timestamp.Before(f.To):
+		return false
+	default:
+		return true
+	}
+}
@@ -4,73 +4,106 @@ import (
 	"errors"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/lunn06/wallet/pkg/money"
 )
 
-// Balance represents currency type with inner Decimal type
-// to ensure high precision
+// ErrNegativeBalance is returned for balance, that would be negative
+var ErrNegativeBalance = errors.New("balance must be positive")
+
+// Balance is non-negative money in currency of wallets, so it never
+// has more digits than currency allows. Zero value is zero, that
+// takes currency of the other balance in arithmetic and comparison
 type Balance struct {
-	d decimal.Decimal
+	m money.Money
 }
 
-func (b Balance) Decimal() decimal.Decimal {
-	return b.d
+// NewBalanceFromMoney returns balance of money, that must not be negative
+func NewBalanceFromMoney(m money.Money) (Balance, error) {
+	if m.IsNegative() {
+		return Balance{}, ErrNegativeBalance
+	}
+	return Balance{m: m}, nil
 }
 
-func NewBalanceFromString(s string) (Balance, error) {
-	d, err := decimal.NewFromString(s)
+// NewBalanceFromString parses balance, that must fit scale of currency exactly
+func NewBalanceFromString(s string, currency money.Currency) (Balance, error) {
+	m, err := money.Parse(s, currency)
 	if err != nil {
 		return Balance{}, err
 	}
+	return NewBalanceFromMoney(m)
+}
 
-	if d.IsNegative() {
-		return Balance{}, errors.New("balance must be positive")
+// NewBalanceFromDecimal returns balance, that must fit scale of currency exactly,
+// e.g. balance read from storage
+func NewBalanceFromDecimal(amount decimal.Decimal, currency money.Currency) (Balance, error) {
+	m, err := money.FromDecimal(amount, currency)
+	if err != nil {
+		return Balance{}, err
 	}
+	return NewBalanceFromMoney(m)
+}
 
-	return Balance{d: d}, nil
+func (b Balance) Money() money.Money {
+	return b.m
 }
 
-func NewBalanceFromDecimal(amount decimal.Decimal) (Balance, error) {
-	if amount.IsNegative() {
-		return Balance{}, errors.New("balance must be positive")
-	}
-	return Balance{amount}, nil
+func (b Balance) Decimal() decimal.Decimal {
+	return b.m.Amount()
 }
 
-func NewBalanceFromFloat(f float64) (Balance, error) {
-	if f < 0 {
-		return Balance{}, errors.New("balance must be positive")
-	}
-	return Balance{decimal.NewFromFloat(f)}, nil
+func (b Balance) Currency() money.Currency {
+	return b.m.Currency()
 }
 
-func (b Balance) Add(other Balance) Balance {
-	return Balance{b.d.Add(other.d)}
+// Add fails for balances in different currencies
+func (b Balance) Add(other Balance) (Balance, error) {
+	sum, err := b.money(other).Add(other.money(b))
+	if err != nil {
+		return Balance{}, err
+	}
+	return Balance{m: sum}, nil
 }
 
+// Sub fails for balances in different currencies and with ErrNegativeBalance for negative result
 func (b Balance) Sub(other Balance) (Balance, error) {
-	res := b.d.Sub(other.d)
-	if res.IsNegative() {
-		return Balance{}, errors.New("result of Sub() must be positive")
+	diff, err := b.money(other).Sub(other.money(b))
+	if err != nil {
+		return Balance{}, err
+	}
+	if diff.IsNegative() {
+		return Balance{}, ErrNegativeBalance
 	}
-	return Balance{res}, nil
+	return Balance{m: diff}, nil
 }
 
-func (b Balance) GreaterOrEqual(other Balance) bool {
-	return b.d.GreaterThanOrEqual(other.d)
+// Cmp compares balances in the same currency, result is -1, 0 or 1
+func (b Balance) Cmp(other Balance) (int, error) {
+	return b.money(other).Cmp(other.money(b))
 }
 
-func (b Balance) Less(other Balance) bool {
-	return b.d.LessThan(other.d)
+// Equal reports whether balances have the same currency and amount
+func (b Balance) Equal(other Balance) bool {
+	return b.money(other).Equal(other.money(b))
 }
 
-func (b Balance) Equal(other Balance) bool {
-	return b.d.Equal(other.d)
+func (b Balance) IsZero() bool {
+	return b.m.IsZero()
 }
 
 func (b Balance) Float64() float64 {
-	return b.d.InexactFloat64()
+	return b.m.Amount().InexactFloat64()
 }
 
 func (b Balance) String() string {
-	return b.d.String()
+	return b.m.Amount().String()
+}
+
+// money returns money of balance, zero value takes currency of other
+func (b Balance) money(other Balance) money.Money {
+	if b.m.Currency() == (money.Currency{}) {
+		return money.Zero(other.m.Currency())
+	}
+	return b.m
 }
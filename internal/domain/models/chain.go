@@ -0,0 +1,47 @@
+package models
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// ChainHash returns SHA-256 over canonical fields of transaction followed
+// by hash of previous transaction in chain. Timestamp is taken with
+// microsecond precision of storages and amount without trailing zeros,
+// so hash doesn't depend on storage representation. Currency code is
+// hashed with amount, so amount can't be moved to other currency unnoticed
+func (t Transaction) ChainHash(prevHash []byte) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n%s\n%d\n%t\n",
+		t.ID,
+		t.FromAddress,
+		t.ToAddress,
+		t.Amount.Decimal().String(),
+		t.Amount.Currency().Code,
+		t.Timestamp.UnixMicro(),
+		t.Successful,
+	)
+	h.Write(prevHash)
+
+	return h.Sum(nil)
+}
+
+// Checkpoint is signed record of chain head, it makes rewriting
+// of whole chain after checkpoint evident
+type Checkpoint struct {
+	ID            int
+	TransactionID int    // ID of head Transaction
+	Hash          []byte // Hash of head Transaction
+	Timestamp     time.Time
+	Signature     []byte // Ed25519 signature of SignedData
+}
+
+// SignedData returns canonical bytes covered by checkpoint signature
+func (c Checkpoint) SignedData() []byte {
+	return fmt.Appendf(nil, "wallet-chain-checkpoint\n%d\n%x\n%d\n",
+		c.TransactionID,
+		c.Hash,
+		c.Timestamp.UnixMicro(),
+	)
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Snapshot represents balance of wallet at the moment of Timestamp,
+// balance at other moments is computed from nearest snapshot and transactions
+type Snapshot struct {
+	ID        int
+	Address   string // Wallet Address
+	Balance   Balance
+	Timestamp time.Time
+}
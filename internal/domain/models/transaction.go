@@ -3,7 +3,8 @@ package models
 import "time"
 
 // Transaction represent balance transferring between wallets
-// or trying to do this, which indicates by Successful field
+// or trying to do this, which indicates by Successful field.
+// Hash chains transaction to previous one, see ChainHash
 type Transaction struct {
 	ID          int
 	FromAddress string // Source Wallet Address
@@ -11,4 +12,12 @@ type Transaction struct {
 	Amount      Balance
 	Timestamp   time.Time
 	Successful  bool
+	PrevHash    []byte // Hash of previous Transaction in chain, empty for the first one
+	Hash        []byte // Empty for transactions inserted before chain was introduced
+}
+
+// TransferBalances are balances of wallets right after successful transfer
+type TransferBalances struct {
+	From Balance
+	To   Balance
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// TreasuryAddress is address of system wallet, that holds funds issued by operators.
+// Funds get to other wallets from it by regular transfers
+const TreasuryAddress = "00000000-0000-4000-8000-000000000000"
+
+// Kinds of treasury operations, mint issues new funds
+// into treasury wallet and burn retires them from it
+const (
+	TreasuryMint = "mint"
+	TreasuryBurn = "burn"
+)
+
+// TreasuryOperation is change of treasury balance by operator.
+// Balance is treasury balance after operation
+type TreasuryOperation struct {
+	ID        int
+	Kind      string
+	Amount    Balance
+	Reason    string
+	Balance   Balance
+	Timestamp time.Time
+}
@@ -1,7 +1,10 @@
 package models
 
+// Wallet is account with balance. Frozen wallet
+// can neither send nor receive transfers
 type Wallet struct {
 	ID      int
 	Address string
 	Balance Balance
+	Frozen  bool
 }
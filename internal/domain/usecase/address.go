@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ParseAddress parses incoming wallet address, that must be random (version 4)
+// uuid as addresses of api requests are, and returns it in canonical form
+func ParseAddress(s string) (string, error) {
+	address, err := uuid.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	if address.Version() != 4 || address.Variant() != uuid.RFC4122 {
+		return "", fmt.Errorf("address must be uuid of version 4, got version %d", address.Version())
+	}
+	return address.String(), nil
+}
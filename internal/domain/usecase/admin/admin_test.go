@@ -0,0 +1,194 @@
+package admin_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/domain/usecase/admin"
+	"github.com/lunn06/wallet/internal/domain/usecase/audit"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+type fixture struct {
+	testutil.Storage
+	usecase admin.Usecase
+}
+
+func newFixture() fixture {
+	storage := testutil.NewStorage(testutil.USD)
+	history := snapshot.NewUsecase(storage.Wallet, storage.Transaction, storage.Snapshot, nil)
+	auditor := audit.NewUsecase(storage.Audit, nil, testutil.Discard)
+
+	return fixture{
+		Storage: storage,
+		usecase: admin.NewUsecase(storage.Wallet, storage.Transaction, storage.Treasury, history, auditor, storage.Currency, testutil.Discard),
+	}
+}
+
+func TestUsecase_Wallets(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+
+	created, err := f.usecase.CreateWallet(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "0", created.Balance)
+	assert.NoError(t, uuid.Validate(created.Address))
+
+	address := uuid.NewString()
+	_, err = f.usecase.CreateWallet(ctx, address)
+	require.NoError(t, err)
+	_, err = f.usecase.CreateWallet(ctx, address)
+	assert.True(t, errorx.IsOfType(err, usecase.ErrOnInsert))
+
+	// addresses are random uuids, as addresses of api requests are
+	for _, invalid := range []string{"not-uuid", uuid.NewMD5(uuid.NameSpaceURL, []byte("wallet")).String()} {
+		_, err = f.usecase.CreateWallet(ctx, invalid)
+		assert.True(t, errorx.IsOfType(err, usecase.ErrInvalid), "%s: %v", invalid, err)
+	}
+	created, err = f.usecase.CreateWallet(ctx, strings.ToUpper(uuid.NewString()))
+	require.NoError(t, err)
+	assert.Equal(t, strings.ToLower(created.Address), created.Address)
+
+	wallets, err := f.usecase.ListWallets(ctx)
+	require.NoError(t, err)
+	assert.Len(t, wallets, 3)
+
+	require.NoError(t, f.usecase.SetFrozen(ctx, address, true))
+	wallet, err := f.usecase.GetWallet(ctx, address)
+	require.NoError(t, err)
+	assert.True(t, wallet.Frozen)
+
+	err = f.usecase.SetFrozen(ctx, uuid.NewString(), true)
+	assert.True(t, errorx.IsOfType(err, usecase.ErrOnUpdate))
+}
+
+func TestUsecase_Treasury(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+
+	minted, err := f.usecase.Mint(ctx, dtos.TreasuryRequest{Amount: "100.5", Reason: "initial issue"})
+	require.NoError(t, err)
+	assert.Equal(t, models.TreasuryMint, minted.Kind)
+	assert.Equal(t, "100.5", minted.Balance)
+
+	burned, err := f.usecase.Burn(ctx, dtos.TreasuryRequest{Amount: "0.5", Reason: "correction"})
+	require.NoError(t, err)
+	assert.Equal(t, "100", burned.Balance)
+
+	_, err = f.usecase.Burn(ctx, dtos.TreasuryRequest{Amount: "1000", Reason: "too much"})
+	assert.True(t, errorx.IsOfType(err, usecase.ErrLackOfCurrency))
+
+	for _, dto := range []dtos.TreasuryRequest{
+		{Amount: "1", Reason: " "},
+		{Amount: "0", Reason: "zero"},
+		{Amount: "-1", Reason: "negative"},
+		{Amount: "many", Reason: "invalid"},
+		{Amount: "0.001", Reason: "fraction of cent"},
+	} {
+		_, err = f.usecase.Mint(ctx, dto)
+		assert.True(t, errorx.IsOfType(err, usecase.ErrInvalid), "%+v", dto)
+	}
+
+	operations, err := f.usecase.TreasuryHistory(ctx)
+	require.NoError(t, err)
+	require.Len(t, operations, 2)
+	assert.Equal(t, "initial issue", operations[0].Reason)
+	assert.Equal(t, models.TreasuryBurn, operations[1].Kind)
+
+	wallet, err := f.usecase.GetWallet(ctx, models.TreasuryAddress)
+	require.NoError(t, err)
+	assert.Equal(t, "100", wallet.Balance)
+}
+
+func TestUsecase_History(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+
+	_, err := f.usecase.Mint(ctx, dtos.TreasuryRequest{Amount: "10", Reason: "issue"})
+	require.NoError(t, err)
+	wallet, err := f.usecase.CreateWallet(ctx, "")
+	require.NoError(t, err)
+	f.Transfer(t, models.TreasuryAddress, wallet.Address, "3", time.Now().UTC())
+	f.Transfer(t, wallet.Address, models.TreasuryAddress, "1", time.Now().UTC())
+
+	history, err := f.usecase.History(ctx, dtos.HistoryRequest{Address: wallet.Address})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "3", history[0].Amount)
+	assert.Equal(t, wallet.Address, history[1].FromAddress)
+
+	_, err = f.usecase.History(ctx, dtos.HistoryRequest{Address: uuid.NewString()})
+	assert.True(t, errorx.IsOfType(err, usecase.ErrOnGet))
+	_, err = f.usecase.History(ctx, dtos.HistoryRequest{Address: wallet.Address, From: time.Now().Add(time.Hour)})
+	assert.True(t, errorx.IsOfType(err, usecase.ErrInvalid))
+}
+
+func TestUsecase_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+
+	_, err := f.usecase.Mint(ctx, dtos.TreasuryRequest{Amount: "10", Reason: "issue"})
+	require.NoError(t, err)
+	wallet, err := f.usecase.CreateWallet(ctx, "")
+	require.NoError(t, err)
+	f.Transfer(t, models.TreasuryAddress, wallet.Address, "4", time.Now().UTC())
+
+	report, err := f.usecase.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dtos.ReconcileReport{Checked: 2, Mismatches: []dtos.ReconcileMismatch{}}, report)
+
+	// balance changed bypassing ledger
+	stored, err := f.Wallet.GetByAddress(ctx, wallet.Address)
+	require.NoError(t, err)
+	stored.Balance = f.Balance(t, "5")
+	require.NoError(t, f.Wallet.UpdateBalance(ctx, stored))
+
+	report, err = f.usecase.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, []dtos.ReconcileMismatch{{Address: wallet.Address, Stored: "5", Ledger: "4"}}, report.Mismatches)
+}
+
+func TestUsecase_Audit(t *testing.T) {
+	ctx := usecase.WithCaller(context.Background(), "CN=operator")
+	f := newFixture()
+
+	wallet, err := f.usecase.CreateWallet(ctx, "")
+	require.NoError(t, err)
+	require.NoError(t, f.usecase.SetFrozen(ctx, wallet.Address, true))
+	_, err = f.usecase.Mint(ctx, dtos.TreasuryRequest{Amount: "10", Reason: "issue"})
+	require.NoError(t, err)
+	_, err = f.usecase.Burn(ctx, dtos.TreasuryRequest{Amount: "100", Reason: "too much"})
+	require.Error(t, err)
+
+	records, err := f.Audit.List(ctx, models.AuditFilter{Actor: "CN=operator"}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, models.AuditWalletCreate, records[0].Action)
+	assert.Equal(t, models.AuditState{"balance": "0", "frozen": "false"}, records[0].After)
+
+	assert.Equal(t, models.AuditWalletFreeze, records[1].Action)
+	assert.Equal(t, "false", records[1].Before["frozen"])
+	assert.Equal(t, "true", records[1].After["frozen"])
+
+	assert.Equal(t, models.AuditTreasuryMint, records[2].Action)
+	assert.Equal(t, models.TreasuryAddress, records[2].Wallet)
+	assert.Equal(t, "0", records[2].Before["balance"])
+	assert.Equal(t, "10", records[2].After["balance"])
+
+	assert.Equal(t, models.AuditTreasuryBurn, records[3].Action)
+	assert.Equal(t, models.AuditFailure, records[3].Outcome)
+	assert.NotEmpty(t, records[3].Error)
+}
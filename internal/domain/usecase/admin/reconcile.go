@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/joomcode/errorx"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// Reconcile describes comparing stored balance of every wallet with balance
+// computed from its snapshots and transactions. Transfers committed during
+// reconciliation may be reported as mismatches, so they should be rechecked
+func (auc Usecase) Reconcile(ctx context.Context) (_ dtos.ReconcileReport, err error) {
+	ctx, span := tracer.Start(ctx, "admin.Reconcile")
+	defer usecase.EndSpan(span, &err)
+
+	report := dtos.ReconcileReport{Mismatches: []dtos.ReconcileMismatch{}}
+	err = auc.eachWallet(ctx, func(wallet models.Wallet) error {
+		report.Checked++
+
+		ledger, err := auc.history.BalanceAt(ctx, wallet.Address, time.Now())
+		if err != nil {
+			// broken history is reported per wallet, other errors abort reconciliation
+			if !errorx.IsOfType(err, usecase.ErrHistoryUnavailable) && !errorx.IsOfType(err, usecase.ErrInconsistent) {
+				return err
+			}
+			report.Mismatches = append(report.Mismatches, dtos.ReconcileMismatch{
+				Address: wallet.Address,
+				Stored:  wallet.Balance.String(),
+				Error:   err.Error(),
+			})
+			return nil
+		}
+
+		if !ledger.Equal(wallet.Balance) {
+			report.Mismatches = append(report.Mismatches, dtos.ReconcileMismatch{
+				Address: wallet.Address,
+				Stored:  wallet.Balance.String(),
+				Ledger:  ledger.String(),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dtos.ReconcileReport{}, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
+)
+
+// Mint describes issuing new funds into treasury wallet
+func (auc Usecase) Mint(ctx context.Context, dto dtos.TreasuryRequest) (_ dtos.TreasuryOperation, err error) {
+	ctx, span := tracer.Start(ctx, "admin.Mint")
+	defer usecase.EndSpan(span, &err)
+
+	return auc.applyTreasury(ctx, models.TreasuryMint, dto)
+}
+
+// Burn describes retiring funds from treasury wallet
+func (auc Usecase) Burn(ctx context.Context, dto dtos.TreasuryRequest) (_ dtos.TreasuryOperation, err error) {
+	ctx, span := tracer.Start(ctx, "admin.Burn")
+	defer usecase.EndSpan(span, &err)
+
+	return auc.applyTreasury(ctx, models.TreasuryBurn, dto)
+}
+
+// TreasuryHistory describes getting all treasury operations ordered by id
+func (auc Usecase) TreasuryHistory(ctx context.Context) (_ []dtos.TreasuryOperation, err error) {
+	ctx, span := tracer.Start(ctx, "admin.TreasuryHistory")
+	defer usecase.EndSpan(span, &err)
+
+	var result []dtos.TreasuryOperation
+	afterID := 0
+	for {
+		operations, err := auc.treasuryInteractor.List(ctx, afterID, listLimit)
+		if err != nil {
+			return nil, usecase.ErrOnGet.Wrap(err, "failed to list treasury operations")
+		}
+
+		for _, operation := range operations {
+			result = append(result, treasuryOperationToDto(operation))
+		}
+
+		if len(operations) < listLimit {
+			return result, nil
+		}
+		afterID = operations[len(operations)-1].ID
+	}
+}
+
+func (auc Usecase) applyTreasury(ctx context.Context, kind string, dto dtos.TreasuryRequest) (_ dtos.TreasuryOperation, err error) {
+	record := models.AuditRecord{
+		Action: treasuryActions[kind],
+		Wallet: models.TreasuryAddress,
+		Before: models.AuditState{"amount": dto.Amount, "reason": dto.Reason},
+	}
+	defer func() {
+		auc.auditor.Record(ctx, record, err)
+	}()
+
+	amount, err := usecase.ParseAmount(dto.Amount, auc.currency)
+	if err != nil {
+		return dtos.TreasuryOperation{}, usecase.ErrInvalid.Wrap(err, "invalid amount")
+	}
+	if amount.Decimal().IsZero() {
+		return dtos.TreasuryOperation{}, usecase.ErrInvalid.New("amount must be positive")
+	}
+	reason := strings.TrimSpace(dto.Reason)
+	if reason == "" {
+		return dtos.TreasuryOperation{}, usecase.ErrInvalid.New("reason is required")
+	}
+
+	operation, err := auc.treasuryInteractor.Apply(ctx, models.TreasuryOperation{
+		Kind:      kind,
+		Amount:    amount,
+		Reason:    reason,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		if storageImpl.IsInsufficientFundsErr(err) {
+			return dtos.TreasuryOperation{}, usecase.ErrLackOfCurrency.Wrap(err, "underdraft treasury balance")
+		}
+		return dtos.TreasuryOperation{}, usecase.ErrOnUpdate.Wrap(err, "failed to apply treasury operation")
+	}
+
+	// treasury balance before operation is restored from the one after it
+	before, _ := operation.Balance.Add(operation.Amount)
+	if kind == models.TreasuryMint {
+		before, _ = operation.Balance.Sub(operation.Amount)
+	}
+	record.Before["balance"] = before.String()
+	record.After = models.AuditState{
+		"amount":       operation.Amount.String(),
+		"reason":       operation.Reason,
+		"balance":      operation.Balance.String(),
+		"operation_id": strconv.Itoa(operation.ID),
+	}
+
+	auc.logger.InfoContext(ctx, "Treasury operation applied",
+		"id", operation.ID,
+		"kind", operation.Kind,
+		"amount", operation.Amount,
+		"reason", operation.Reason,
+		"balance", operation.Balance,
+	)
+
+	return treasuryOperationToDto(operation), nil
+}
+
+var treasuryActions = map[string]string{
+	models.TreasuryMint: models.AuditTreasuryMint,
+	models.TreasuryBurn: models.AuditTreasuryBurn,
+}
+
+func treasuryOperationToDto(operation models.TreasuryOperation) dtos.TreasuryOperation {
+	return dtos.TreasuryOperation{
+		ID:        operation.ID,
+		Kind:      operation.Kind,
+		Amount:    operation.Amount.String(),
+		Reason:    operation.Reason,
+		Balance:   operation.Balance.String(),
+		Timestamp: operation.Timestamp,
+	}
+}
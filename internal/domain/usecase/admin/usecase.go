@@ -0,0 +1,86 @@
+// Package admin contains operator usecases, that aren't exposed via public API
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/admin")
+
+// Defining interactors interfaces, that define necessary to usecase methods
+
+type walletInteractor interface {
+	GetByAddress(ctx context.Context, address string) (models.Wallet, error)
+	List(ctx context.Context, afterID int, limit int) ([]models.Wallet, error)
+	Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error)
+	SetFrozen(ctx context.Context, address string, frozen bool) error
+}
+
+type transactionInteractor interface {
+	GetSuccessfulByAddress(ctx context.Context, address string, after, until time.Time) ([]models.Transaction, error)
+}
+
+type treasuryInteractor interface {
+	Apply(ctx context.Context, operation models.TreasuryOperation) (models.TreasuryOperation, error)
+	List(ctx context.Context, afterID int, limit int) ([]models.TreasuryOperation, error)
+}
+
+// balanceHistory computes balance at past moments (e.g. snapshot.Usecase)
+type balanceHistory interface {
+	BalanceAt(ctx context.Context, address string, moment time.Time) (models.Balance, error)
+}
+
+// auditor records state-changing actions (e.g. audit.Usecase)
+type auditor interface {
+	Record(ctx context.Context, record models.AuditRecord, err error)
+}
+
+// Usecase contains interactors interfaces
+type Usecase struct {
+	logger                *slog.Logger
+	walletInteractor      walletInteractor
+	transactionInteractor transactionInteractor
+	treasuryInteractor    treasuryInteractor
+	history               balanceHistory
+	auditor               auditor
+	currency              money.Currency
+}
+
+func NewUsecase(
+	walletInteractor walletInteractor,
+	transactionInteractor transactionInteractor,
+	treasuryInteractor treasuryInteractor,
+	history balanceHistory,
+	auditor auditor,
+	currency money.Currency,
+	logger *slog.Logger,
+) Usecase {
+	if walletInteractor == nil || transactionInteractor == nil || treasuryInteractor == nil {
+		panic("interactor can not be nil")
+	}
+	if history == nil {
+		panic("history can not be nil")
+	}
+	if auditor == nil {
+		panic("auditor can not be nil")
+	}
+	return Usecase{
+		logger:                logger,
+		walletInteractor:      walletInteractor,
+		transactionInteractor: transactionInteractor,
+		treasuryInteractor:    treasuryInteractor,
+		history:               history,
+		auditor:               auditor,
+		currency:              currency,
+	}
+}
+
+// listLimit is count of wallets or operations fetched per page
+const listLimit = 100
@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// CreateWallet describes creating wallet with zero balance, address must be uuid4
+// as api addresses are, empty address means random one.
+// Funds get to wallet only by transfers, e.g. from treasury
+func (auc Usecase) CreateWallet(ctx context.Context, address string) (_ dtos.AdminWallet, err error) {
+	ctx, span := tracer.Start(ctx, "admin.CreateWallet")
+	defer usecase.EndSpan(span, &err)
+
+	if address == "" {
+		address = uuid.NewString()
+	}
+	record := models.AuditRecord{Action: models.AuditWalletCreate, Wallet: address}
+	defer func() {
+		auc.auditor.Record(ctx, record, err)
+	}()
+
+	address, err = usecase.ParseAddress(address)
+	if err != nil {
+		return dtos.AdminWallet{}, usecase.ErrInvalid.Wrap(err, "invalid address %q", record.Wallet)
+	}
+	record.Wallet = address
+
+	// zero money is never negative
+	balance, _ := models.NewBalanceFromMoney(money.Zero(auc.currency))
+	wallet, err := auc.walletInteractor.Insert(ctx, models.Wallet{Address: address, Balance: balance})
+	if err != nil {
+		return dtos.AdminWallet{}, usecase.ErrOnInsert.Wrap(err, "failed to insert wallet")
+	}
+
+	record.After = walletState(wallet)
+
+	auc.logger.InfoContext(ctx, "Wallet created", "address", wallet.Address)
+
+	return walletToDto(wallet), nil
+}
+
+// GetWallet describes getting wallet by address
+func (auc Usecase) GetWallet(ctx context.Context, address string) (_ dtos.AdminWallet, err error) {
+	ctx, span := tracer.Start(ctx, "admin.GetWallet")
+	defer usecase.EndSpan(span, &err)
+
+	wallet, err := auc.walletInteractor.GetByAddress(ctx, address)
+	if err != nil {
+		return dtos.AdminWallet{}, usecase.ErrOnGet.Wrap(err, "failed to get wallet by address")
+	}
+
+	return walletToDto(wallet), nil
+}
+
+// ListWallets describes getting all wallets ordered by id
+func (auc Usecase) ListWallets(ctx context.Context) (_ []dtos.AdminWallet, err error) {
+	ctx, span := tracer.Start(ctx, "admin.ListWallets")
+	defer usecase.EndSpan(span, &err)
+
+	var result []dtos.AdminWallet
+	err = auc.eachWallet(ctx, func(wallet models.Wallet) error {
+		result = append(result, walletToDto(wallet))
+		return nil
+	})
+
+	return result, err
+}
+
+// History describes getting successful transactions of wallet in period
+func (auc Usecase) History(ctx context.Context, dto dtos.HistoryRequest) (_ []dtos.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "admin.History")
+	defer usecase.EndSpan(span, &err)
+
+	if dto.To.IsZero() {
+		dto.To = time.Now()
+	}
+	if !dto.To.After(dto.From) {
+		return nil, usecase.ErrInvalid.New("end of period must be after its start")
+	}
+
+	// to report missing wallet instead of empty history
+	if _, err := auc.walletInteractor.GetByAddress(ctx, dto.Address); err != nil {
+		return nil, usecase.ErrOnGet.Wrap(err, "failed to get wallet by address")
+	}
+
+	transactions, err := auc.transactionInteractor.GetSuccessfulByAddress(ctx, dto.Address, dto.From, dto.To)
+	if err != nil {
+		return nil, usecase.ErrOnGet.Wrap(err, "failed to get transactions")
+	}
+
+	result := make([]dtos.Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, dtos.Transaction{
+			ID:          t.ID,
+			FromAddress: t.FromAddress,
+			ToAddress:   t.ToAddress,
+			Amount:      t.Amount.String(),
+			Timestamp:   t.Timestamp,
+		})
+	}
+
+	return result, nil
+}
+
+// SetFrozen describes freezing or unfreezing wallet, frozen
+// wallet can neither send nor receive transfers
+func (auc Usecase) SetFrozen(ctx context.Context, address string, frozen bool) (err error) {
+	ctx, span := tracer.Start(ctx, "admin.SetFrozen")
+	defer usecase.EndSpan(span, &err)
+
+	record := models.AuditRecord{Action: models.AuditWalletUnfreeze, Wallet: address}
+	if frozen {
+		record.Action = models.AuditWalletFreeze
+	}
+	defer func() {
+		auc.auditor.Record(ctx, record, err)
+	}()
+
+	// missing wallet is reported by SetFrozen
+	if wallet, err := auc.walletInteractor.GetByAddress(ctx, address); err == nil {
+		record.Before = walletState(wallet)
+	}
+
+	if err := auc.walletInteractor.SetFrozen(ctx, address, frozen); err != nil {
+		return usecase.ErrOnUpdate.Wrap(err, "failed to set frozen")
+	}
+
+	if record.Before != nil {
+		record.After = maps.Clone(record.Before)
+		record.After["frozen"] = strconv.FormatBool(frozen)
+	}
+
+	auc.logger.InfoContext(ctx, "Wallet frozen state changed", "address", address, "frozen", frozen)
+
+	return nil
+}
+
+// eachWallet passes all wallets to f page by page
+func (auc Usecase) eachWallet(ctx context.Context, f func(models.Wallet) error) error {
+	afterID := 0
+	for {
+		wallets, err := auc.walletInteractor.List(ctx, afterID, listLimit)
+		if err != nil {
+			return usecase.ErrOnGet.Wrap(err, "failed to list wallets")
+		}
+
+		for _, wallet := range wallets {
+			if err := f(wallet); err != nil {
+				return err
+			}
+		}
+
+		if len(wallets) < listLimit {
+			return nil
+		}
+		afterID = wallets[len(wallets)-1].ID
+	}
+}
+
+// walletState is audited state of wallet
+func walletState(wallet models.Wallet) models.AuditState {
+	return models.AuditState{
+		"balance": wallet.Balance.String(),
+		"frozen":  strconv.FormatBool(wallet.Frozen),
+	}
+}
+
+func walletToDto(wallet models.Wallet) dtos.AdminWallet {
+	return dtos.AdminWallet{
+		ID:      wallet.ID,
+		Address: wallet.Address,
+		Balance: wallet.Balance.String(),
+		Frozen:  wallet.Frozen,
+	}
+}
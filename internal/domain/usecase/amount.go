@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// ParseAmount parses incoming amount, that must fit scale of currency
+// exactly and must not be negative, to balance
+func ParseAmount(s string, currency money.Currency) (models.Balance, error) {
+	amount, err := money.Parse(s, currency)
+	if err != nil {
+		return models.Balance{}, err
+	}
+	return models.NewBalanceFromMoney(amount)
+}
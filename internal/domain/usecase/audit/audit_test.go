@@ -0,0 +1,128 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/domain/usecase/audit"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/storage/memory"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+var usd = money.Currency{Code: "USD", Scale: 2}
+
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestUsecase_Record(t *testing.T) {
+	storage := memory.AuditStorage{Storage: memory.NewStorage(usd)}
+	var sink bytes.Buffer
+	uc := audit.NewUsecase(storage, slog.New(slog.NewJSONHandler(&sink, nil)), logger)
+
+	address := uuid.New()
+	ctx := usecase.WithCaller(context.Background(), "CN=operator")
+	ctx = usecase.WithRequestInfo(ctx, usecase.RequestInfo{SourceIP: "10.0.0.1", RequestID: "req-1"})
+
+	uc.Record(ctx, models.AuditRecord{
+		Action: models.AuditWalletFreeze,
+		Wallet: strings.ToUpper(address.String()),
+		Before: models.AuditState{"frozen": "false"},
+		After:  models.AuditState{"frozen": "true"},
+	}, nil)
+	uc.Record(context.Background(), models.AuditRecord{
+		Action: models.AuditWalletCreate,
+		Wallet: "not uuid",
+		After:  models.AuditState{"balance": "0"},
+	}, errors.New("already exists"))
+
+	records, err := storage.List(context.Background(), models.AuditFilter{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	succeeded := records[0]
+	assert.Equal(t, "CN=operator", succeeded.Actor)
+	assert.Equal(t, "10.0.0.1", succeeded.SourceIP)
+	assert.Equal(t, "req-1", succeeded.RequestID)
+	assert.Equal(t, address.String(), succeeded.Wallet)
+	assert.Equal(t, models.AuditSuccess, succeeded.Outcome)
+	assert.Equal(t, models.AuditState{"frozen": "true"}, succeeded.After)
+	assert.WithinDuration(t, time.Now(), succeeded.Timestamp, time.Minute)
+
+	failed := records[1]
+	assert.Equal(t, audit.AnonymousActor, failed.Actor)
+	assert.Equal(t, "not uuid", failed.Wallet)
+	assert.Equal(t, models.AuditFailure, failed.Outcome)
+	assert.Equal(t, "already exists", failed.Error)
+	assert.Empty(t, failed.After)
+
+	// sink mirrors every record as json line
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	require.Len(t, lines, 2)
+	var mirrored struct {
+		ID     int    `json:"id"`
+		Actor  string `json:"actor"`
+		Action string `json:"action"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &mirrored))
+	assert.Equal(t, succeeded.ID, mirrored.ID)
+	assert.Equal(t, "CN=operator", mirrored.Actor)
+	assert.Equal(t, models.AuditWalletFreeze, mirrored.Action)
+}
+
+func TestUsecase_Query(t *testing.T) {
+	ctx := context.Background()
+	uc := audit.NewUsecase(memory.AuditStorage{Storage: memory.NewStorage(usd)}, nil, logger)
+
+	wallet, other := uuid.NewString(), uuid.NewString()
+	for range 3 {
+		uc.Record(ctx, models.AuditRecord{Action: models.AuditTransfer, Wallet: wallet, Counterparty: other}, nil)
+	}
+	uc.Record(ctx, models.AuditRecord{Action: models.AuditWalletSeed, Wallet: uuid.NewString()}, nil)
+
+	t.Run("paging", func(t *testing.T) {
+		first, err := uc.Query(ctx, dtos.AuditQuery{Wallet: other, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, first.Records, 2)
+		assert.NotZero(t, first.NextAfterID)
+
+		second, err := uc.Query(ctx, dtos.AuditQuery{Wallet: other, Limit: 2, AfterID: first.NextAfterID})
+		require.NoError(t, err)
+		require.Len(t, second.Records, 1)
+		assert.Zero(t, second.NextAfterID)
+		assert.Greater(t, second.Records[0].ID, first.Records[1].ID)
+	})
+	t.Run("period", func(t *testing.T) {
+		page, err := uc.Query(ctx, dtos.AuditQuery{From: time.Now().Add(-time.Minute)})
+		require.NoError(t, err)
+		assert.Len(t, page.Records, 4)
+
+		page, err = uc.Query(ctx, dtos.AuditQuery{To: time.Now().Add(-time.Minute)})
+		require.NoError(t, err)
+		assert.Empty(t, page.Records)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		now := time.Now()
+		for _, dto := range []dtos.AuditQuery{
+			{Limit: audit.MaxLimit + 1},
+			{AfterID: -1},
+			{From: now, To: now},
+		} {
+			_, err := uc.Query(ctx, dto)
+			assert.True(t, errorx.IsOfType(err, usecase.ErrInvalid), "%+v", dto)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// Limits of records per page of Query
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Query describes getting page of audit records matching query ordered by id
+func (uc Usecase) Query(ctx context.Context, dto dtos.AuditQuery) (_ dtos.AuditPage, err error) {
+	ctx, span := tracer.Start(ctx, "audit.Query")
+	defer usecase.EndSpan(span, &err)
+
+	if dto.Limit < 0 || dto.Limit > MaxLimit {
+		return dtos.AuditPage{}, usecase.ErrInvalid.New("limit must be between 0 and %d", MaxLimit)
+	}
+	if dto.AfterID < 0 {
+		return dtos.AuditPage{}, usecase.ErrInvalid.New("after id must not be negative")
+	}
+	if !dto.From.IsZero() && !dto.To.IsZero() && !dto.To.After(dto.From) {
+		return dtos.AuditPage{}, usecase.ErrInvalid.New("end of period must be after its start")
+	}
+	limit := dto.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	records, err := uc.auditInteractor.List(ctx, models.AuditFilter{
+		Actor:  dto.Actor,
+		Wallet: canonicalAddress(dto.Wallet),
+		From:   dto.From,
+		To:     dto.To,
+	}, dto.AfterID, limit)
+	if err != nil {
+		return dtos.AuditPage{}, usecase.ErrOnGet.Wrap(err, "failed to list audit records")
+	}
+
+	page := dtos.AuditPage{Records: make([]dtos.AuditRecord, 0, len(records))}
+	for _, record := range records {
+		page.Records = append(page.Records, recordToDto(record))
+	}
+	// full page may be followed by the next one
+	if len(records) == limit {
+		page.NextAfterID = records[len(records)-1].ID
+	}
+
+	return page, nil
+}
+
+func recordToDto(record models.AuditRecord) dtos.AuditRecord {
+	return dtos.AuditRecord{
+		ID:           record.ID,
+		Timestamp:    record.Timestamp,
+		Actor:        record.Actor,
+		SourceIP:     record.SourceIP,
+		RequestID:    record.RequestID,
+		Action:       record.Action,
+		Wallet:       record.Wallet,
+		Counterparty: record.Counterparty,
+		Before:       record.Before,
+		After:        record.After,
+		Outcome:      record.Outcome,
+		Error:        record.Error,
+	}
+}
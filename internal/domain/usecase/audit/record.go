@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+)
+
+// AnonymousActor is actor of actions without authenticated caller
+const AnonymousActor = "anonymous"
+
+// Record stores record of action, that failed with err or succeeded with nil err.
+// Actor, source and time of record are taken from ctx and clock. Action has
+// already happened, so failure to store record is logged instead of returned
+func (uc Usecase) Record(ctx context.Context, record models.AuditRecord, err error) {
+	record.Timestamp = time.Now().UTC()
+	record.Actor = AnonymousActor
+	if caller, ok := usecase.CallerFrom(ctx); ok {
+		record.Actor = caller
+	}
+	if info, ok := usecase.RequestInfoFrom(ctx); ok {
+		record.SourceIP, record.RequestID = info.SourceIP, info.RequestID
+	}
+	// the same wallet must be found by any spelling of its address
+	record.Wallet = canonicalAddress(record.Wallet)
+	record.Counterparty = canonicalAddress(record.Counterparty)
+
+	record.Outcome = models.AuditSuccess
+	if err != nil {
+		record.Outcome = models.AuditFailure
+		record.Error = err.Error()
+		record.After = nil
+	}
+
+	// record must be stored even if request is cancelled right after action
+	stored, insertErr := uc.auditInteractor.Insert(context.WithoutCancel(ctx), record)
+	if insertErr != nil {
+		uc.logger.ErrorContext(ctx, "Failed to store audit record",
+			"action", record.Action,
+			"wallet", record.Wallet,
+			"cause", insertErr.Error(),
+		)
+		stored = record
+	}
+
+	if uc.sink != nil {
+		// records carry request id themselves, so context attrs aren't needed
+		uc.sink.LogAttrs(context.Background(), slog.LevelInfo, "audit", recordAttrs(stored)...)
+	}
+}
+
+func recordAttrs(record models.AuditRecord) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Int("id", record.ID),
+		slog.Time("time", record.Timestamp),
+		slog.String("actor", record.Actor),
+		slog.String("source_ip", record.SourceIP),
+		slog.String("request_id", record.RequestID),
+		slog.String("action", record.Action),
+		slog.String("wallet", record.Wallet),
+		slog.String("counterparty", record.Counterparty),
+		slog.Any("before", map[string]string(record.Before)),
+		slog.Any("after", map[string]string(record.After)),
+		slog.String("outcome", record.Outcome),
+	}
+	if record.Error != "" {
+		attrs = append(attrs, slog.String("error", record.Error))
+	}
+	return attrs
+}
+
+// canonicalAddress returns canonical form of uuid address,
+// malformed addresses of failed actions are kept as is
+func canonicalAddress(address string) string {
+	parsed, err := uuid.Parse(address)
+	if err != nil {
+		return address
+	}
+	return parsed.String()
+}
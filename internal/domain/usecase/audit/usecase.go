@@ -0,0 +1,39 @@
+// Package audit contains usecases of append-only log of state-changing actions
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/audit")
+
+// Defining interactors interfaces, that define necessary to usecase methods
+
+type auditInteractor interface {
+	Insert(ctx context.Context, record models.AuditRecord) (models.AuditRecord, error)
+	List(ctx context.Context, filter models.AuditFilter, afterID int, limit int) ([]models.AuditRecord, error)
+}
+
+// Usecase contains interactor of audit records and sink, that
+// mirrors every record (e.g. to rotating file), nil sink disables it
+type Usecase struct {
+	logger          *slog.Logger
+	auditInteractor auditInteractor
+	sink            *slog.Logger
+}
+
+func NewUsecase(auditInteractor auditInteractor, sink *slog.Logger, logger *slog.Logger) Usecase {
+	if auditInteractor == nil {
+		panic("interactor can not be nil")
+	}
+	return Usecase{
+		logger:          logger,
+		auditInteractor: auditInteractor,
+		sink:            sink,
+	}
+}
@@ -0,0 +1,254 @@
+package chain_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase/chain"
+	"github.com/lunn06/wallet/internal/dtos"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/internal/testutil"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// tamperedStorage serves copy of chain, that test is free to modify
+type tamperedStorage struct {
+	transactions []models.Transaction
+}
+
+func (s *tamperedStorage) GetChainHead(_ context.Context) (models.Transaction, error) {
+	if len(s.transactions) == 0 {
+		return models.Transaction{}, storageImpl.ErrNotFound.New("no transactions")
+	}
+	return s.transactions[len(s.transactions)-1], nil
+}
+
+func (s *tamperedStorage) IterateChain(_ context.Context, yield func(models.Transaction) error) error {
+	for _, t := range s.transactions {
+		if err := yield(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fixture struct {
+	testutil.Storage
+	key      ed25519.PrivateKey
+	from, to models.Wallet
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	storage := testutil.NewStorage(testutil.USD)
+	return fixture{
+		Storage: storage,
+		key:     ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+		from:    storage.InsertWallet(t, "100"),
+		to:      storage.InsertWallet(t, "100"),
+	}
+}
+
+func (f fixture) usecase() chain.Usecase {
+	return chain.NewUsecase(f.Transaction, f.Checkpoint, f.key, nil)
+}
+
+func (f fixture) transfer(t *testing.T, n int) {
+	t.Helper()
+
+	for range n {
+		f.Transfer(t, f.from.Address, f.to.Address, "1", time.Now())
+	}
+}
+
+// tampered returns copy of stored chain
+func (f fixture) tampered(t *testing.T) *tamperedStorage {
+	t.Helper()
+
+	s := &tamperedStorage{}
+	err := f.Transaction.IterateChain(context.Background(), func(tx models.Transaction) error {
+		s.transactions = append(s.transactions, tx)
+		return nil
+	})
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestUsecase_Verify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty chain", func(t *testing.T) {
+		report, err := newFixture(t).usecase().Verify(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, dtos.ChainReport{Valid: true}, report)
+	})
+	t.Run("valid chain with checkpoints", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 3)
+		taken, err := f.usecase().Checkpoint(ctx)
+		require.NoError(t, err)
+		require.True(t, taken)
+		f.transfer(t, 2)
+
+		report, err := f.usecase().Verify(ctx)
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.Equal(t, 5, report.Checked)
+		assert.Equal(t, 1, report.CheckpointsVerified)
+		require.NotNil(t, report.Head)
+		assert.Equal(t, 5, report.Head.TransactionID)
+		assert.Nil(t, report.BrokenLink)
+	})
+	t.Run("modified amount", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 4)
+		s := f.tampered(t)
+		s.transactions[1].Amount = f.Balance(t, "1000")
+
+		report, err := chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, s.transactions[1].ID, report.BrokenLink.TransactionID)
+		assert.Equal(t, dtos.ChainHashMismatch, report.BrokenLink.Reason)
+		assert.Equal(t, 2, report.Checked)
+	})
+	t.Run("modified currency", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 2)
+		s := f.tampered(t)
+		amount, err := models.NewBalanceFromString("1", money.Currency{Code: "EUR", Scale: 2})
+		require.NoError(t, err)
+		s.transactions[0].Amount = amount
+
+		report, err := chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, s.transactions[0].ID, report.BrokenLink.TransactionID)
+		assert.Equal(t, dtos.ChainHashMismatch, report.BrokenLink.Reason)
+	})
+	t.Run("deleted transaction", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 4)
+		s := f.tampered(t)
+		s.transactions = append(s.transactions[:2], s.transactions[3:]...)
+
+		report, err := chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, s.transactions[2].ID, report.BrokenLink.TransactionID)
+		assert.Equal(t, dtos.ChainPrevHashMismatch, report.BrokenLink.Reason)
+	})
+	t.Run("rewritten chain is caught by checkpoint", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 3)
+		_, err := f.usecase().Checkpoint(ctx)
+		require.NoError(t, err)
+
+		// consistently rehashed chain without signing key
+		s := f.tampered(t)
+		var prevHash []byte
+		for i := range s.transactions {
+			if i == 0 {
+				s.transactions[i].Amount = f.Balance(t, "50")
+			}
+			s.transactions[i].PrevHash = prevHash
+			s.transactions[i].Hash = s.transactions[i].ChainHash(prevHash)
+			prevHash = s.transactions[i].Hash
+		}
+
+		report, err := chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, 3, report.BrokenLink.TransactionID)
+		assert.Equal(t, 1, report.BrokenLink.CheckpointID)
+		assert.Equal(t, dtos.ChainCheckpointMismatch, report.BrokenLink.Reason)
+	})
+	t.Run("truncated chain is caught by checkpoint", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 3)
+		_, err := f.usecase().Checkpoint(ctx)
+		require.NoError(t, err)
+
+		s := f.tampered(t)
+		s.transactions = s.transactions[:2]
+
+		report, err := chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, 3, report.BrokenLink.TransactionID)
+		assert.Equal(t, dtos.ChainCheckpointMissing, report.BrokenLink.Reason)
+	})
+	t.Run("checkpoint signed by other key", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 1)
+		_, err := f.usecase().Checkpoint(ctx)
+		require.NoError(t, err)
+
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = 1
+		other := chain.NewUsecase(f.Transaction, f.Checkpoint, ed25519.NewKeyFromSeed(seed), nil)
+
+		report, err := other.Verify(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, dtos.ChainCheckpointSignature, report.BrokenLink.Reason)
+	})
+	t.Run("unchained legacy transactions", func(t *testing.T) {
+		f := newFixture(t)
+		f.transfer(t, 3)
+		s := f.tampered(t)
+		s.transactions[0].PrevHash, s.transactions[0].Hash = nil, nil
+		s.transactions[1].PrevHash = nil
+		s.transactions[1].Hash = s.transactions[1].ChainHash(nil)
+		s.transactions[2].PrevHash = s.transactions[1].Hash
+		s.transactions[2].Hash = s.transactions[2].ChainHash(s.transactions[1].Hash)
+
+		report, err := chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.Equal(t, 1, report.Unchained)
+
+		// unhashed transaction after chain start
+		s.transactions[2].PrevHash, s.transactions[2].Hash = nil, nil
+		report, err = chain.NewUsecase(s, f.Checkpoint, f.key, nil).Verify(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report.BrokenLink)
+		assert.Equal(t, dtos.ChainUnchained, report.BrokenLink.Reason)
+	})
+}
+
+func TestUsecase_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	taken, err := f.usecase().Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.False(t, taken, "empty chain")
+
+	f.transfer(t, 2)
+	taken, err = f.usecase().Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.True(t, taken)
+
+	taken, err = f.usecase().Checkpoint(ctx)
+	require.NoError(t, err)
+	assert.False(t, taken, "head is already checkpointed")
+
+	latest, err := f.Checkpoint.GetLatest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, latest.TransactionID)
+	assert.True(t, ed25519.Verify(f.key.Public().(ed25519.PublicKey), latest.SignedData(), latest.Signature))
+
+	_, err = chain.NewUsecase(f.Transaction, f.Checkpoint, nil, nil).Checkpoint(ctx)
+	assert.Error(t, err)
+}
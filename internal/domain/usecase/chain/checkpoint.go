@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
+)
+
+// Checkpoint describes signing of current chain head. Nothing is done
+// if chain is empty or head is already checkpointed.
+// It reports whether checkpoint was taken
+func (cuc Usecase) Checkpoint(ctx context.Context) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "chain.Checkpoint")
+	defer usecase.EndSpan(span, &err)
+
+	if cuc.key == nil {
+		return false, usecase.ErrNotConfigured.New("chain signing key isn't configured")
+	}
+
+	head, err := cuc.transactionInteractor.GetChainHead(ctx)
+	if storageImpl.IsNotFoundErr(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, usecase.ErrOnGet.Wrap(err, "failed to get chain head")
+	}
+	// only unchained transactions
+	if len(head.Hash) == 0 {
+		return false, nil
+	}
+
+	latest, err := cuc.checkpointInteractor.GetLatest(ctx)
+	if err != nil && !storageImpl.IsNotFoundErr(err) {
+		return false, usecase.ErrOnGet.Wrap(err, "failed to get latest checkpoint")
+	}
+	if err == nil && latest.TransactionID == head.ID {
+		return false, nil
+	}
+
+	checkpoint := models.Checkpoint{
+		TransactionID: head.ID,
+		Hash:          head.Hash,
+		// signed timestamp must survive storage precision
+		Timestamp: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	checkpoint.Signature = ed25519.Sign(cuc.key, checkpoint.SignedData())
+
+	if _, err := cuc.checkpointInteractor.Insert(ctx, checkpoint); err != nil {
+		return false, usecase.ErrOnInsert.Wrap(err, "failed to insert checkpoint")
+	}
+
+	return true, nil
+}
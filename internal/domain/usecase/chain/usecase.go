@@ -0,0 +1,52 @@
+package chain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/chain")
+
+// Defining interactors interfaces, that define necessary to usecase methods
+
+type transactionInteractor interface {
+	GetChainHead(ctx context.Context) (models.Transaction, error)
+	IterateChain(ctx context.Context, yield func(models.Transaction) error) error
+}
+
+type checkpointInteractor interface {
+	Insert(ctx context.Context, checkpoint models.Checkpoint) (models.Checkpoint, error)
+	GetLatest(ctx context.Context) (models.Checkpoint, error)
+	GetAll(ctx context.Context) ([]models.Checkpoint, error)
+}
+
+// Usecase contains interactors interfaces and key of checkpoints
+// signatures, nil key disables checkpoints
+type Usecase struct {
+	logger                *slog.Logger
+	transactionInteractor transactionInteractor
+	checkpointInteractor  checkpointInteractor
+	key                   ed25519.PrivateKey
+}
+
+func NewUsecase(
+	transactionInteractor transactionInteractor,
+	checkpointInteractor checkpointInteractor,
+	key ed25519.PrivateKey,
+	logger *slog.Logger,
+) Usecase {
+	if transactionInteractor == nil || checkpointInteractor == nil {
+		panic("interactor can not be nil")
+	}
+	return Usecase{
+		logger:                logger,
+		transactionInteractor: transactionInteractor,
+		checkpointInteractor:  checkpointInteractor,
+		key:                   key,
+	}
+}
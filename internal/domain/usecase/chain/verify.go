@@ -0,0 +1,123 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// errBroken stops walking of chain at the first broken link
+var errBroken = errors.New("chain is broken")
+
+// Verify describes walking transactions chain in order of ids and reporting
+// the first broken link. Signatures of checkpoints are verified before walking,
+// then every checkpoint is compared with hash of its transaction, so rewriting
+// of the whole chain or deleting of its tail after checkpoint is detected too
+func (cuc Usecase) Verify(ctx context.Context) (_ dtos.ChainReport, err error) {
+	ctx, span := tracer.Start(ctx, "chain.Verify")
+	defer usecase.EndSpan(span, &err)
+
+	var report dtos.ChainReport
+
+	// checkpoints by transaction id, that are removed when transaction is seen
+	pending := make(map[int][]models.Checkpoint)
+	if cuc.key != nil {
+		checkpoints, err := cuc.checkpointInteractor.GetAll(ctx)
+		if err != nil {
+			return dtos.ChainReport{}, usecase.ErrOnGet.Wrap(err, "failed to get checkpoints")
+		}
+
+		publicKey := cuc.key.Public().(ed25519.PublicKey)
+		for _, checkpoint := range checkpoints {
+			if !ed25519.Verify(publicKey, checkpoint.SignedData(), checkpoint.Signature) {
+				report.BrokenLink = &dtos.BrokenLink{
+					TransactionID: checkpoint.TransactionID,
+					CheckpointID:  checkpoint.ID,
+					Reason:        dtos.ChainCheckpointSignature,
+				}
+				return report, nil
+			}
+			pending[checkpoint.TransactionID] = append(pending[checkpoint.TransactionID], checkpoint)
+		}
+	}
+
+	var prevHash []byte
+	chained := false
+	err = cuc.transactionInteractor.IterateChain(ctx, func(t models.Transaction) error {
+		report.Checked++
+
+		if len(t.Hash) == 0 {
+			// only transactions before the first chained one may be unchained
+			if chained {
+				report.BrokenLink = &dtos.BrokenLink{TransactionID: t.ID, Reason: dtos.ChainUnchained}
+				return errBroken
+			}
+			report.Unchained++
+			return nil
+		}
+		chained = true
+
+		if !bytes.Equal(t.PrevHash, prevHash) {
+			report.BrokenLink = brokenLink(t.ID, dtos.ChainPrevHashMismatch, prevHash, t.PrevHash)
+			return errBroken
+		}
+		if expected := t.ChainHash(t.PrevHash); !bytes.Equal(t.Hash, expected) {
+			report.BrokenLink = brokenLink(t.ID, dtos.ChainHashMismatch, expected, t.Hash)
+			return errBroken
+		}
+
+		for _, checkpoint := range pending[t.ID] {
+			if !bytes.Equal(checkpoint.Hash, t.Hash) {
+				report.BrokenLink = brokenLink(t.ID, dtos.ChainCheckpointMismatch, checkpoint.Hash, t.Hash)
+				report.BrokenLink.CheckpointID = checkpoint.ID
+				return errBroken
+			}
+			report.CheckpointsVerified++
+		}
+		delete(pending, t.ID)
+
+		prevHash = t.Hash
+		report.Head = &dtos.ChainLink{TransactionID: t.ID, Hash: hex.EncodeToString(t.Hash)}
+
+		return nil
+	})
+	if errors.Is(err, errBroken) {
+		return report, nil
+	}
+	if err != nil {
+		return dtos.ChainReport{}, usecase.ErrOnGet.Wrap(err, "failed to iterate chain")
+	}
+
+	// checkpoint of transaction, that isn't in chain anymore
+	for _, checkpoints := range pending {
+		for _, checkpoint := range checkpoints {
+			if report.BrokenLink == nil || checkpoint.ID < report.BrokenLink.CheckpointID {
+				report.BrokenLink = &dtos.BrokenLink{
+					TransactionID: checkpoint.TransactionID,
+					CheckpointID:  checkpoint.ID,
+					Reason:        dtos.ChainCheckpointMissing,
+					Expected:      hex.EncodeToString(checkpoint.Hash),
+				}
+			}
+		}
+	}
+
+	report.Valid = report.BrokenLink == nil
+
+	return report, nil
+}
+
+func brokenLink(transactionID int, reason string, expected, actual []byte) *dtos.BrokenLink {
+	return &dtos.BrokenLink{
+		TransactionID: transactionID,
+		Reason:        reason,
+		Expected:      hex.EncodeToString(expected),
+		Actual:        hex.EncodeToString(actual),
+	}
+}
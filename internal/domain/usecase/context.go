@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+
+	storageImpl "github.com/lunn06/wallet/internal/storage"
+)
+
+// WithReadYourWrites makes usecases in ctx read from primary storage,
+// so they observe writes made just before them instead of replica lag
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return storageImpl.WithPrimary(ctx)
+}
+
+type callerKey struct{}
+
+// WithCaller stores identity of authenticated caller
+// of usecases in ctx, e.g. subject of client certificate
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns identity of caller stored in ctx by WithCaller
+func CallerFrom(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(callerKey{}).(string)
+	return caller, ok
+}
+
+// RequestInfo describes request, that called usecases, for audit
+type RequestInfo struct {
+	SourceIP  string
+	RequestID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo stores info of request calling usecases in ctx
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns info stored in ctx by WithRequestInfo
+func RequestInfoFrom(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
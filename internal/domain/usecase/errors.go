@@ -18,6 +18,10 @@ func IsLackOfCurrencyErr(err *errorx.Error) bool {
 	return err.IsOfType(ErrLackOfCurrency)
 }
 
+func IsFrozenErr(err *errorx.Error) bool {
+	return err.IsOfType(ErrFrozen)
+}
+
 func IsClientErr(err *errorx.Error) bool {
 	return errorx.HasTrait(err, Client) || storageImpl.IsExternalErr(err.Cause())
 }
@@ -34,6 +38,11 @@ var (
 	Client            = errorx.RegisterTrait("client")
 	ErrInvalid        = DomainErrors.NewType("invalid", Client)
 	ErrLackOfCurrency = DomainErrors.NewType("lack_of_currency")
+	// ErrFrozen means that wallet is frozen by operator and can't take part in transfers
+	ErrFrozen = DomainErrors.NewType("frozen", Client)
+	// ErrHistoryUnavailable means that balance at requested moment can't be
+	// computed, as wallet didn't exist or its snapshots are deleted by retention
+	ErrHistoryUnavailable = DomainErrors.NewType("history_unavailable", Client)
 
 	// Server is errorx trait for internal errors
 	Server        = errorx.RegisterTrait("server")
@@ -41,4 +50,9 @@ var (
 	ErrOnInsert   = DomainErrors.NewType("failed_to_insert", Server)
 	ErrOnRollback = DomainErrors.NewType("failed_to_rollback", Server)
 	ErrOnUpdate   = DomainErrors.NewType("failed_to_update token", Server)
+	ErrOnDelete   = DomainErrors.NewType("failed_to_delete", Server)
+	// ErrInconsistent means that stored data contradicts itself, e.g. ledger leads to negative balance
+	ErrInconsistent = DomainErrors.NewType("inconsistent", Server)
+	// ErrNotConfigured means that operation requires missing part of config, e.g. signing key
+	ErrNotConfigured = DomainErrors.NewType("not_configured", Server)
 )
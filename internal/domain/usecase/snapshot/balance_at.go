@@ -0,0 +1,64 @@
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
+)
+
+// BalanceAt describes computing balance of wallet at moment
+// from the nearest snapshot before it and subsequent transactions
+func (suc Usecase) BalanceAt(ctx context.Context, address string, moment time.Time) (_ models.Balance, err error) {
+	ctx, span := tracer.Start(ctx, "snapshot.BalanceAt")
+	defer usecase.EndSpan(span, &err)
+
+	// to report missing wallet instead of missing history
+	if _, err := suc.walletInteractor.GetByAddress(ctx, address); err != nil {
+		return models.Balance{}, usecase.ErrOnGet.Wrap(err, "failed to get wallet by address")
+	}
+
+	balance, _, err := suc.balanceAt(ctx, address, moment)
+	return balance, err
+}
+
+// balanceAt returns balance at moment and count of transactions after the nearest snapshot
+func (suc Usecase) balanceAt(ctx context.Context, address string, moment time.Time) (models.Balance, int, error) {
+	snapshot, err := suc.snapshotInteractor.GetLatest(ctx, address, moment)
+	if err != nil {
+		if storageImpl.IsNotFoundErr(err) {
+			return models.Balance{}, 0, usecase.ErrHistoryUnavailable.New("no balance history of %s at %s", address, moment)
+		}
+		return models.Balance{}, 0, usecase.ErrOnGet.Wrap(err, "failed to get snapshot")
+	}
+
+	transactions, err := suc.transactionInteractor.GetSuccessfulByAddress(ctx, snapshot.Address, snapshot.Timestamp, moment)
+	if err != nil {
+		return models.Balance{}, 0, usecase.ErrOnGet.Wrap(err, "failed to get transactions")
+	}
+
+	// sum as decimal, as intermediate balance of unordered
+	// transactions with equal timestamps may be negative
+	sum := snapshot.Balance.Decimal()
+	for _, t := range transactions {
+		switch {
+		case t.FromAddress == t.ToAddress:
+			continue
+		case t.ToAddress == snapshot.Address:
+			sum = sum.Add(t.Amount.Decimal())
+		case t.FromAddress == snapshot.Address:
+			sum = sum.Sub(t.Amount.Decimal())
+		}
+	}
+
+	balance, err := models.NewBalanceFromDecimal(sum, snapshot.Balance.Currency())
+	if err != nil {
+		return models.Balance{}, 0, usecase.ErrInconsistent.Wrap(
+			err, "balance of %s at %s is %s", snapshot.Address, moment, sum,
+		)
+	}
+
+	return balance, len(transactions), nil
+}
@@ -0,0 +1,120 @@
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+type fixture struct {
+	testutil.Storage
+	usecase snapshot.Usecase
+}
+
+func newFixture() fixture {
+	storage := testutil.NewStorage(testutil.USD)
+	return fixture{
+		Storage: storage,
+		usecase: snapshot.NewUsecase(storage.Wallet, storage.Transaction, storage.Snapshot, nil),
+	}
+}
+
+func assertBalance(t *testing.T, expected string, actual models.Balance) {
+	t.Helper()
+
+	e, err := models.NewBalanceFromString(expected, testutil.USD)
+	require.NoError(t, err)
+	assert.True(t, e.Equal(actual), "expected %s, got %s", expected, actual)
+}
+
+func TestUsecase_BalanceAt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	created := time.Now()
+	wallet1 := f.InsertWallet(t, "100")
+	wallet2 := f.InsertWallet(t, "50")
+
+	// transfers are in the future to be after initial snapshots
+	base := time.Now().Add(time.Hour)
+	f.Transfer(t, wallet1.Address, wallet2.Address, "10.5", base)
+	f.Transfer(t, wallet2.Address, wallet1.Address, "0.25", base.Add(time.Minute))
+	f.Transfer(t, wallet1.Address, wallet1.Address, "1", base.Add(2*time.Minute))
+
+	t.Run("before transfers", func(t *testing.T) {
+		balance, err := f.usecase.BalanceAt(ctx, wallet1.Address, base.Add(-time.Second))
+		require.NoError(t, err)
+		assertBalance(t, "100", balance)
+	})
+	t.Run("between transfers", func(t *testing.T) {
+		balance, err := f.usecase.BalanceAt(ctx, wallet1.Address, base)
+		require.NoError(t, err)
+		assertBalance(t, "89.5", balance)
+
+		balance, err = f.usecase.BalanceAt(ctx, wallet2.Address, base.Add(30*time.Second))
+		require.NoError(t, err)
+		assertBalance(t, "60.5", balance)
+	})
+	t.Run("after transfers", func(t *testing.T) {
+		balance, err := f.usecase.BalanceAt(ctx, wallet1.Address, base.Add(time.Hour))
+		require.NoError(t, err)
+		assertBalance(t, "89.75", balance)
+	})
+	t.Run("before wallet creation", func(t *testing.T) {
+		_, err := f.usecase.BalanceAt(ctx, wallet1.Address, created.Add(-time.Hour))
+		assert.True(t, errorx.IsOfType(err, usecase.ErrHistoryUnavailable))
+	})
+	t.Run("missing wallet", func(t *testing.T) {
+		_, err := f.usecase.BalanceAt(ctx, uuid.NewString(), base)
+		assert.True(t, errorx.IsOfType(err, usecase.ErrOnGet))
+	})
+}
+
+func TestUsecase_TakeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	wallet1 := f.InsertWallet(t, "100")
+	wallet2 := f.InsertWallet(t, "50")
+	idle := f.InsertWallet(t, "1")
+
+	base := time.Now().Add(time.Hour)
+	f.Transfer(t, wallet1.Address, wallet2.Address, "30", base)
+
+	taken, err := f.usecase.TakeSnapshots(ctx, base.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, taken, "idle wallet must be skipped")
+
+	snap, err := f.Snapshot.GetLatest(ctx, wallet2.Address, base.Add(time.Hour))
+	require.NoError(t, err)
+	assertBalance(t, "80", snap.Balance)
+
+	snap, err = f.Snapshot.GetLatest(ctx, idle.Address, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, snap.Timestamp.Before(base))
+
+	// nothing changed since previous snapshots
+	taken, err = f.usecase.TakeSnapshots(ctx, base.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, taken)
+
+	// balance is still computed after older snapshots are pruned
+	deleted, err := f.usecase.Prune(ctx, base.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	balance, err := f.usecase.BalanceAt(ctx, wallet1.Address, base.Add(time.Hour))
+	require.NoError(t, err)
+	assertBalance(t, "70", balance)
+
+	_, err = f.usecase.BalanceAt(ctx, wallet1.Address, base)
+	assert.True(t, errorx.IsOfType(err, usecase.ErrHistoryUnavailable))
+}
@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/joomcode/errorx"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+)
+
+// listLimit is count of wallets processed per page
+const listLimit = 100
+
+// TakeSnapshots describes recording balance of every wallet at moment,
+// computed from ledger. Wallets without transactions since their latest
+// snapshot and wallets created after moment are skipped.
+// It returns count of taken snapshots
+func (suc Usecase) TakeSnapshots(ctx context.Context, moment time.Time) (taken int, err error) {
+	ctx, span := tracer.Start(ctx, "snapshot.TakeSnapshots")
+	defer usecase.EndSpan(span, &err)
+
+	for afterID := 0; ; {
+		wallets, err := suc.walletInteractor.List(ctx, afterID, listLimit)
+		if err != nil {
+			return taken, usecase.ErrOnGet.Wrap(err, "failed to list wallets")
+		}
+		if len(wallets) == 0 {
+			return taken, nil
+		}
+		afterID = wallets[len(wallets)-1].ID
+
+		for _, wallet := range wallets {
+			balance, changes, err := suc.balanceAt(ctx, wallet.Address, moment)
+			if errorx.IsOfType(err, usecase.ErrHistoryUnavailable) {
+				continue
+			}
+			if err != nil {
+				return taken, err
+			}
+			if changes == 0 {
+				continue
+			}
+
+			if _, err := suc.snapshotInteractor.Insert(ctx, models.Snapshot{
+				Address:   wallet.Address,
+				Balance:   balance,
+				Timestamp: moment,
+			}); err != nil {
+				return taken, usecase.ErrOnInsert.Wrap(err, "failed to insert snapshot")
+			}
+			taken++
+		}
+	}
+}
+
+// Prune describes deleting snapshots taken before moment. The newest of them
+// per wallet is kept, so balance can be computed at any moment after it.
+// It returns count of deleted snapshots
+func (suc Usecase) Prune(ctx context.Context, moment time.Time) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "snapshot.Prune")
+	defer usecase.EndSpan(span, &err)
+
+	deleted, err := suc.snapshotInteractor.DeleteBefore(ctx, moment)
+	if err != nil {
+		return 0, usecase.ErrOnDelete.Wrap(err, "failed to delete snapshots")
+	}
+
+	return deleted, nil
+}
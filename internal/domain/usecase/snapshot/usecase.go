@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/snapshot")
+
+// Defining interactors interfaces, that define necessary to usecase methods
+
+type walletInteractor interface {
+	GetByAddress(ctx context.Context, address string) (models.Wallet, error)
+	List(ctx context.Context, afterID int, limit int) ([]models.Wallet, error)
+}
+
+type transactionInteractor interface {
+	GetSuccessfulByAddress(ctx context.Context, address string, after, until time.Time) ([]models.Transaction, error)
+}
+
+type snapshotInteractor interface {
+	Insert(ctx context.Context, snapshot models.Snapshot) (models.Snapshot, error)
+	GetLatest(ctx context.Context, address string, moment time.Time) (models.Snapshot, error)
+	DeleteBefore(ctx context.Context, moment time.Time) (int, error)
+}
+
+// Usecase contains interactors interfaces
+type Usecase struct {
+	logger                *slog.Logger
+	walletInteractor      walletInteractor
+	transactionInteractor transactionInteractor
+	snapshotInteractor    snapshotInteractor
+}
+
+func NewUsecase(
+	walletInteractor walletInteractor,
+	transactionInteractor transactionInteractor,
+	snapshotInteractor snapshotInteractor,
+	logger *slog.Logger,
+) Usecase {
+	if walletInteractor == nil || transactionInteractor == nil || snapshotInteractor == nil {
+		panic("interactor can not be nil")
+	}
+	return Usecase{
+		logger:                logger,
+		walletInteractor:      walletInteractor,
+		transactionInteractor: transactionInteractor,
+		snapshotInteractor:    snapshotInteractor,
+	}
+}
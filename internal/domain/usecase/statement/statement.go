@@ -0,0 +1,80 @@
+package statement
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+// Statement describes streaming statement of wallet to write: opening balance
+// at dto.From, every successful transaction in (dto.From, dto.To] with running
+// balance and closing balance at dto.To. Nothing is written if opening balance
+// isn't available, error of write stops statement and is returned as is
+func (suc Usecase) Statement(ctx context.Context, dto dtos.StatementRequest, write func(dtos.StatementRow) error) (err error) {
+	ctx, span := tracer.Start(ctx, "statement.Statement")
+	defer usecase.EndSpan(span, &err)
+
+	opening, err := suc.history.BalanceAt(ctx, dto.Address, dto.From)
+	if err != nil {
+		return err
+	}
+
+	if err := write(dtos.StatementRow{
+		Type:      dtos.StatementOpening,
+		Timestamp: dto.From,
+		Balance:   opening.String(),
+	}); err != nil {
+		return err
+	}
+
+	// sum as decimal, as intermediate balance of unordered
+	// transactions with equal timestamps may be negative
+	running := opening.Decimal()
+	var writeErr error
+	if err := suc.transactionInteractor.IterateSuccessfulByAddress(
+		ctx, dto.Address, dto.From, dto.To,
+		func(t models.Transaction) error {
+			amount := t.Amount.Decimal()
+			switch {
+			case t.FromAddress == t.ToAddress:
+				amount = decimal.Zero
+			// stored addresses are normalized, requested one may be not
+			case strings.EqualFold(t.FromAddress, dto.Address):
+				amount = amount.Neg()
+			}
+			running = running.Add(amount)
+
+			writeErr = write(dtos.StatementRow{
+				Type:        dtos.StatementTransaction,
+				ID:          t.ID,
+				Timestamp:   t.Timestamp,
+				FromAddress: t.FromAddress,
+				ToAddress:   t.ToAddress,
+				Amount:      amount.String(),
+				Balance:     running.String(),
+			})
+			return writeErr
+		},
+	); err != nil {
+		if writeErr != nil {
+			return writeErr
+		}
+		return usecase.ErrOnGet.Wrap(err, "failed to iterate transactions")
+	}
+
+	closing, err := models.NewBalanceFromDecimal(running, opening.Currency())
+	if err != nil {
+		return usecase.ErrInconsistent.Wrap(err, "closing balance of %s is %s", dto.Address, running)
+	}
+
+	return write(dtos.StatementRow{
+		Type:      dtos.StatementClosing,
+		Timestamp: dto.To,
+		Balance:   closing.String(),
+	})
+}
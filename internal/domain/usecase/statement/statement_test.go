@@ -0,0 +1,107 @@
+package statement_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/domain/usecase/statement"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/testutil"
+)
+
+var (
+	usecaseImpl statement.Usecase
+	storage     testutil.Storage
+)
+
+func TestMain(m *testing.M) {
+	storage = testutil.NewStorage(testutil.USD)
+	snapshotUc := snapshot.NewUsecase(storage.Wallet, storage.Transaction, storage.Snapshot, nil)
+	usecaseImpl = statement.NewUsecase(storage.Transaction, snapshotUc, nil)
+
+	m.Run()
+}
+
+func TestUsecase_Statement(t *testing.T) {
+	wallet1 := storage.InsertWallet(t, "100")
+	wallet2 := storage.InsertWallet(t, "50")
+
+	// transfers are in the future to be after initial snapshots
+	base := time.Now().Add(time.Hour).UTC()
+	storage.Transfer(t, wallet1.Address, wallet2.Address, "1", base)
+	t2 := storage.Transfer(t, wallet1.Address, wallet2.Address, "10.5", base.Add(time.Minute))
+	t3 := storage.Transfer(t, wallet2.Address, wallet1.Address, "0.25", base.Add(2*time.Minute))
+	t4 := storage.Transfer(t, wallet1.Address, wallet1.Address, "5", base.Add(3*time.Minute))
+	storage.Transfer(t, wallet2.Address, wallet1.Address, "7", base.Add(time.Hour))
+
+	t.Run("success", func(t *testing.T) {
+		var rows []dtos.StatementRow
+		err := usecaseImpl.Statement(context.Background(), dtos.StatementRequest{
+			// requested address isn't normalized
+			Address: strings.ToUpper(wallet1.Address),
+			From:    base,
+			To:      base.Add(30 * time.Minute),
+			Format:  dtos.StatementCSV,
+		}, func(row dtos.StatementRow) error {
+			rows = append(rows, row)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []dtos.StatementRow{
+			{Type: dtos.StatementOpening, Timestamp: base, Balance: "99"},
+			{
+				Type: dtos.StatementTransaction, ID: t2.ID, Timestamp: t2.Timestamp,
+				FromAddress: wallet1.Address, ToAddress: wallet2.Address, Amount: "-10.5", Balance: "88.5",
+			},
+			{
+				Type: dtos.StatementTransaction, ID: t3.ID, Timestamp: t3.Timestamp,
+				FromAddress: wallet2.Address, ToAddress: wallet1.Address, Amount: "0.25", Balance: "88.75",
+			},
+			{
+				Type: dtos.StatementTransaction, ID: t4.ID, Timestamp: t4.Timestamp,
+				FromAddress: wallet1.Address, ToAddress: wallet1.Address, Amount: "0", Balance: "88.75",
+			},
+			{Type: dtos.StatementClosing, Timestamp: base.Add(30 * time.Minute), Balance: "88.75"},
+		}, rows)
+	})
+	t.Run("history unavailable", func(t *testing.T) {
+		called := false
+		err := usecaseImpl.Statement(context.Background(), dtos.StatementRequest{
+			Address: wallet1.Address,
+			From:    base.Add(-24 * time.Hour),
+			To:      base,
+		}, func(dtos.StatementRow) error {
+			called = true
+			return nil
+		})
+		assert.True(t, errorx.IsOfType(err, usecase.ErrHistoryUnavailable))
+		assert.False(t, called, "nothing must be written")
+	})
+	t.Run("write error", func(t *testing.T) {
+		stop := errors.New("client gone")
+		rows := 0
+		err := usecaseImpl.Statement(context.Background(), dtos.StatementRequest{
+			Address: wallet1.Address,
+			From:    base,
+			To:      base.Add(30 * time.Minute),
+		}, func(dtos.StatementRow) error {
+			rows++
+			if rows == 2 {
+				return stop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 2, rows)
+	})
+}
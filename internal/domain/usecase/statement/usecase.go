@@ -0,0 +1,47 @@
+package statement
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/statement")
+
+// Defining interactors interfaces, that define necessary to usecase methods
+
+type transactionInteractor interface {
+	IterateSuccessfulByAddress(
+		ctx context.Context,
+		address string,
+		after, until time.Time,
+		yield func(models.Transaction) error,
+	) error
+}
+
+// balanceHistory computes balance at past moments (e.g. snapshot.Usecase)
+type balanceHistory interface {
+	BalanceAt(ctx context.Context, address string, moment time.Time) (models.Balance, error)
+}
+
+// Usecase contains interactors interfaces
+type Usecase struct {
+	logger                *slog.Logger
+	transactionInteractor transactionInteractor
+	history               balanceHistory
+}
+
+func NewUsecase(transactionInteractor transactionInteractor, history balanceHistory, logger *slog.Logger) Usecase {
+	if transactionInteractor == nil || history == nil {
+		panic("interactor can not be nil")
+	}
+	return Usecase{
+		logger:                logger,
+		transactionInteractor: transactionInteractor,
+		history:               history,
+	}
+}
@@ -10,7 +10,10 @@ import (
 const defaultLimit = 5
 
 // GetLast describe getting last successful transactions
-func (tuc Usecase) GetLast(ctx context.Context, dto dtos.GetLastRequest) (dtos.GetLastResponse, error) {
+func (tuc Usecase) GetLast(ctx context.Context, dto dtos.GetLastRequest) (_ dtos.GetLastResponse, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetLast")
+	defer usecase.EndSpan(span, &err)
+
 	if dto.Count < 1 {
 		dto.Count = defaultLimit
 	}
@@ -2,15 +2,44 @@ package transation
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"time"
 
+	"github.com/joomcode/errorx"
+
 	"github.com/lunn06/wallet/internal/domain/models"
 	"github.com/lunn06/wallet/internal/domain/usecase"
 	"github.com/lunn06/wallet/internal/dtos"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
 )
 
 // Send describes transferring balance between wallets
 func (tuc Usecase) Send(ctx context.Context, dto dtos.SendRequest) (respDto dtos.SendResponse, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Send")
+	defer usecase.EndSpan(span, &err)
+
+	// balances are checked before update, so replica lag isn't acceptable here
+	ctx = usecase.WithReadYourWrites(ctx)
+
+	var amountBalance models.Balance
+
+	// report outcome of transfer after all other defers have set err
+	defer func() {
+		tuc.observer.ObserveTransfer(transferOutcome(err), amountBalance.Float64())
+	}()
+
+	// audit outcome of transfer after failed transaction is inserted
+	record := models.AuditRecord{
+		Action:       models.AuditTransfer,
+		Wallet:       dto.FromAddress,
+		Counterparty: dto.ToAddress,
+		Before:       models.AuditState{"amount": dto.Amount},
+	}
+	defer func() {
+		tuc.auditor.Record(ctx, record, err)
+	}()
+
 	if dto.FromAddress == dto.ToAddress {
 		return respDto, usecase.ErrInvalid.New("invalid dto with same addresses")
 	}
@@ -25,44 +54,86 @@ func (tuc Usecase) Send(ctx context.Context, dto dtos.SendRequest) (respDto dtos
 		return dtos.SendResponse{}, usecase.ErrOnGet.Wrap(err, "failed to get wallet by address")
 	}
 
-	amountBalance, err := models.NewBalanceFromString(dto.Amount)
+	amountBalance, err = usecase.ParseAmount(dto.Amount, tuc.currency)
 	if err != nil {
 		return dtos.SendResponse{}, usecase.ErrInvalid.Wrap(err, "invalid amount")
 	}
 
-	// to create and insert Transaction with success status
-	// this defer func see on predefined err variable to define success status
+	record.Before["from_balance"] = from.Balance.String()
+	record.Before["to_balance"] = to.Balance.String()
+
+	tr := models.Transaction{
+		FromAddress: dto.FromAddress,
+		ToAddress:   dto.ToAddress,
+		Amount:      amountBalance,
+		Timestamp:   time.Now().UTC(),
+	}
+
+	// to insert Transaction with failed status, successful one
+	// is inserted by storage in the same transfer
 	defer func() {
-		tr := models.Transaction{
-			FromAddress: dto.FromAddress,
-			ToAddress:   dto.ToAddress,
-			Amount:      amountBalance,
-			Timestamp:   time.Now().UTC(),
-			Successful:  err == nil,
+		if err == nil {
+			return
 		}
 		if _, inErr := tuc.transactionInteractor.Insert(ctx, tr); inErr != nil {
 			err = usecase.ErrOnInsert.Wrap(inErr, "failed to insert transaction")
 		}
 	}()
 
-	if from.Balance.Less(amountBalance) {
-		return dtos.SendResponse{}, usecase.ErrLackOfCurrency.New("underdraft from-wallet balance")
+	if from.Frozen || to.Frozen {
+		return dtos.SendResponse{}, usecase.ErrFrozen.New("wallet is frozen")
 	}
 
-	from.Balance, _ = from.Balance.Sub(amountBalance)
-	to.Balance = to.Balance.Add(amountBalance)
-
-	if err := tuc.walletInteractor.UpdateBalance(ctx, from); err != nil {
-		return dtos.SendResponse{}, usecase.ErrOnUpdate.Wrap(err, "failed to update from-wallet")
+	if _, err = from.Balance.Sub(amountBalance); err != nil {
+		if errors.Is(err, models.ErrNegativeBalance) {
+			return dtos.SendResponse{}, usecase.ErrLackOfCurrency.New("underdraft from-wallet balance")
+		}
+		return dtos.SendResponse{}, usecase.ErrInconsistent.Wrap(err, "balance of %s isn't in currency of transfers", from.Address)
 	}
-	if err := tuc.walletInteractor.UpdateBalance(ctx, to); err != nil {
-		// if it can't update to-wallet, make the rollback here
-		from.Balance = from.Balance.Add(amountBalance)
-		if err := tuc.walletInteractor.UpdateBalance(ctx, from); err != nil {
-			return dtos.SendResponse{}, usecase.ErrOnRollback.Wrap(err, "failed to rollback from-wallet")
+
+	// balance could be changed by concurrent transfer after the check above,
+	// so storage checks it again atomically with debit and credit
+	transferred, balances, err := tuc.transactionInteractor.Transfer(ctx, tr)
+	if err != nil {
+		if storageImpl.IsInsufficientFundsErr(err) {
+			return dtos.SendResponse{}, usecase.ErrLackOfCurrency.Wrap(err, "underdraft from-wallet balance")
 		}
-		return dtos.SendResponse{}, usecase.ErrOnUpdate.Wrap(err, "failed to update to-wallet")
+		if storageImpl.IsFrozenErr(err) {
+			return dtos.SendResponse{}, usecase.ErrFrozen.Wrap(err, "wallet is frozen")
+		}
+		return dtos.SendResponse{}, usecase.ErrOnUpdate.Wrap(err, "failed to transfer")
+	}
+
+	// balances are committed by transfer, so concurrent transfers can't skew them,
+	// while balances read for the check above could be already changed
+	fromBefore, _ := balances.From.Add(amountBalance)
+	toBefore, _ := balances.To.Sub(amountBalance)
+	record.Before["from_balance"] = fromBefore.String()
+	record.Before["to_balance"] = toBefore.String()
+	record.After = models.AuditState{
+		"amount":         amountBalance.String(),
+		"from_balance":   balances.From.String(),
+		"to_balance":     balances.To.String(),
+		"transaction_id": strconv.Itoa(transferred.ID),
 	}
 
 	return dtos.SendResponse{}, nil
 }
+
+// transferOutcome maps result of Send to metrics label
+func transferOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errorx.IsOfType(err, usecase.ErrLackOfCurrency):
+		return "lack_of_currency"
+	case errorx.IsOfType(err, usecase.ErrFrozen):
+		return "frozen"
+	case errorx.Cast(err) != nil && usecase.IsNotFoundErr(errorx.Cast(err)):
+		return "not_found"
+	case errorx.HasTrait(err, usecase.Client):
+		return "invalid"
+	default:
+		return "failure"
+	}
+}
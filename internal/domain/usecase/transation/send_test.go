@@ -2,7 +2,6 @@ package transation_test
 
 import (
 	"context"
-	"math/rand"
 	"testing"
 	"time"
 
@@ -17,7 +16,8 @@ import (
 
 func TestUsecase_Send(t *testing.T) {
 	t.Run("success sending", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(rand.Float64() * 100)
+		// balance must be enough to send 3.50
+		balance := randomBalance(350)
 		wallet1, err := walletStorage.Insert(context.Background(), models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
@@ -41,7 +41,7 @@ func TestUsecase_Send(t *testing.T) {
 		transactions, err := transactionStorage.GetLastSuccessful(context.Background(), 1)
 		require.NoError(t, err)
 
-		amount, _ := models.NewBalanceFromString("3.50")
+		amount, _ := models.NewBalanceFromString("3.50", usd)
 
 		transaction := transactions[0]
 		assert.Equal(t, wallet1.Address, transaction.FromAddress)
@@ -52,7 +52,7 @@ func TestUsecase_Send(t *testing.T) {
 		wallet11, err := walletStorage.GetByID(context.Background(), wallet1.ID)
 		require.NoError(t, err)
 
-		add := wallet11.Balance.Add(amount)
+		add, err := wallet11.Balance.Add(amount)
 		require.NoError(t, err)
 		assert.True(t, wallet1.Balance.Equal(add))
 
@@ -65,7 +65,7 @@ func TestUsecase_Send(t *testing.T) {
 	})
 
 	t.Run("send with wrong amount", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(rand.Float64() * 100)
+		balance := randomBalance(0)
 		wallet1, err := walletStorage.Insert(context.Background(), models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
@@ -84,10 +84,22 @@ func TestUsecase_Send(t *testing.T) {
 			Amount:      "wrong",
 		})
 		assert.ErrorContains(t, err, usecase.ErrInvalid.String())
+
+		// usd has no fractions of cent
+		_, err = usecaseImpl.Send(context.Background(), dtos.SendRequest{
+			FromAddress: wallet1.Address,
+			ToAddress:   wallet2.Address,
+			Amount:      "0.001",
+		})
+		assert.ErrorContains(t, err, usecase.ErrInvalid.String())
+
+		wallet11, err := walletStorage.GetByID(context.Background(), wallet1.ID)
+		require.NoError(t, err)
+		assert.True(t, wallet1.Balance.Equal(wallet11.Balance))
 	})
 
 	t.Run("send with wrong address", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(rand.Float64() * 100)
+		balance := randomBalance(0)
 		wallet1, err := walletStorage.Insert(context.Background(), models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
@@ -116,7 +128,7 @@ func TestUsecase_Send(t *testing.T) {
 	})
 
 	t.Run("send with same address", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(rand.Float64() * 100)
+		balance := randomBalance(0)
 		wallet, err := walletStorage.Insert(context.Background(), models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
@@ -130,4 +142,30 @@ func TestUsecase_Send(t *testing.T) {
 		})
 		assert.ErrorContains(t, err, usecase.ErrInvalid.String())
 	})
+	t.Run("send with frozen wallet", func(t *testing.T) {
+		balance := randomBalance(350)
+		wallet1, err := walletStorage.Insert(context.Background(), models.Wallet{
+			Address: uuid.NewString(),
+			Balance: balance,
+		})
+		require.NoError(t, err)
+
+		wallet2, err := walletStorage.Insert(context.Background(), models.Wallet{
+			Address: uuid.NewString(),
+			Balance: balance,
+		})
+		require.NoError(t, err)
+		require.NoError(t, walletStorage.SetFrozen(context.Background(), wallet2.Address, true))
+
+		_, err = usecaseImpl.Send(context.Background(), dtos.SendRequest{
+			FromAddress: wallet1.Address,
+			ToAddress:   wallet2.Address,
+			Amount:      "3.50",
+		})
+		assert.ErrorContains(t, err, usecase.ErrFrozen.String())
+
+		wallet11, err := walletStorage.GetByID(context.Background(), wallet1.ID)
+		require.NoError(t, err)
+		assert.True(t, wallet1.Balance.Equal(wallet11.Balance))
+	})
 }
@@ -1,22 +1,44 @@
 package transation_test
 
 import (
+	"io"
+	"log/slog"
+	"math/rand"
 	"testing"
 
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase/audit"
 	"github.com/lunn06/wallet/internal/domain/usecase/transation"
-	"github.com/lunn06/wallet/internal/storage/mock"
+	"github.com/lunn06/wallet/internal/metrics"
+	"github.com/lunn06/wallet/internal/storage/memory"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
+var usd = money.Currency{Code: "USD", Scale: 2}
+
 var (
 	usecaseImpl        transation.Usecase
-	transactionStorage mock.TransactionStorage
-	walletStorage      mock.WalletStorage
+	transactionStorage memory.TransactionStorage
+	walletStorage      memory.WalletStorage
+	auditStorage       memory.AuditStorage
 )
 
 func TestMain(m *testing.M) {
-	transactionStorage = mock.TransactionStorage{}
-	walletStorage = mock.WalletStorage{}
-	usecaseImpl = transation.NewUsecase(&transactionStorage, &walletStorage)
+	storage := memory.NewStorage(usd)
+	transactionStorage = memory.TransactionStorage{Storage: storage}
+	walletStorage = memory.WalletStorage{Storage: storage}
+	auditStorage = memory.AuditStorage{Storage: storage}
+	auditor := audit.NewUsecase(auditStorage, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	usecaseImpl = transation.NewUsecase(transactionStorage, walletStorage, metrics.New(), auditor, usd)
 
 	m.Run()
 }
+
+// randomBalance returns random balance of at least minCents
+func randomBalance(minCents int64) models.Balance {
+	balance, err := models.NewBalanceFromMoney(money.FromMinor(minCents+rand.Int63n(10000), usd))
+	if err != nil {
+		panic(err)
+	}
+	return balance
+}
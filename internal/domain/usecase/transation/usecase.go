@@ -3,34 +3,67 @@ package transation
 import (
 	"context"
 
+	"go.opentelemetry.io/otel"
+
 	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/transation")
+
 // Defining interactors interfaces, that define necessary to usecaseImpl methods
 
 type walletInteractor interface {
 	GetByAddress(ctx context.Context, address string) (models.Wallet, error)
-	Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error)
-	UpdateBalance(ctx context.Context, wallet models.Wallet) error
 }
 
 type transactionInteractor interface {
 	GetLastSuccessful(ctx context.Context, limit int) ([]models.Transaction, error)
 	Insert(ctx context.Context, transaction models.Transaction) (models.Transaction, error)
+	// Transfer atomically moves amount between wallets and inserts successful transaction
+	Transfer(ctx context.Context, transaction models.Transaction) (models.Transaction, models.TransferBalances, error)
+}
+
+// transferObserver receives outcome of every Send call (e.g. metrics.Metrics)
+type transferObserver interface {
+	ObserveTransfer(outcome string, amount float64)
 }
 
-// Usecase contains interactors interfaces
+// auditor records state-changing actions (e.g. audit.Usecase)
+type auditor interface {
+	Record(ctx context.Context, record models.AuditRecord, err error)
+}
+
+// Usecase contains interactors interfaces and currency of wallets
 type Usecase struct {
 	transactionInteractor transactionInteractor
 	walletInteractor      walletInteractor
+	observer              transferObserver
+	auditor               auditor
+	currency              money.Currency
 }
 
-func NewUsecase(transactionInteractor transactionInteractor, walletInteractor walletInteractor) Usecase {
+func NewUsecase(
+	transactionInteractor transactionInteractor,
+	walletInteractor walletInteractor,
+	observer transferObserver,
+	auditor auditor,
+	currency money.Currency,
+) Usecase {
 	if transactionInteractor == nil || walletInteractor == nil {
 		panic("interactor can not be nil")
 	}
+	if observer == nil {
+		panic("observer can not be nil")
+	}
+	if auditor == nil {
+		panic("auditor can not be nil")
+	}
 	return Usecase{
 		transactionInteractor: transactionInteractor,
 		walletInteractor:      walletInteractor,
+		observer:              observer,
+		auditor:               auditor,
+		currency:              currency,
 	}
 }
@@ -8,7 +8,22 @@ import (
 )
 
 // GetBalance describes getting balance of target wallet
-func (wuc Usecase) GetBalance(ctx context.Context, dto dtos.GetBalanceRequest) (dtos.GetBalanceResponse, error) {
+func (wuc Usecase) GetBalance(ctx context.Context, dto dtos.GetBalanceRequest) (_ dtos.GetBalanceResponse, err error) {
+	ctx, span := tracer.Start(ctx, "wallet.GetBalance")
+	defer usecase.EndSpan(span, &err)
+
+	if dto.At != nil {
+		balance, err := wuc.history.BalanceAt(ctx, dto.Address, *dto.At)
+		if err != nil {
+			return dtos.GetBalanceResponse{}, err
+		}
+
+		return dtos.GetBalanceResponse{
+			Balance: balance.String(),
+			At:      dto.At,
+		}, nil
+	}
+
 	wallet, err := wuc.interactor.GetByAddress(ctx, dto.Address)
 	if err != nil {
 		return dtos.GetBalanceResponse{}, usecase.ErrOnGet.Wrap(err, "failed to get wallet by address")
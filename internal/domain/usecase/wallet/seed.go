@@ -0,0 +1,170 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/dtos"
+	storageImpl "github.com/lunn06/wallet/internal/storage"
+)
+
+// Seed describes inserting missing wallets by address, so applying the same seed
+// again changes nothing. Balance of existing wallet is kept unless overwrite is set,
+// then seed is upserted by address and balance of existing wallet is set to seeded one.
+// Seed is validated as a whole before any wallet is written
+func (wuc Usecase) Seed(ctx context.Context, seed []dtos.SeedWallet, overwrite bool) (err error) {
+	ctx, span := tracer.Start(ctx, "wallet.Seed")
+	defer usecase.EndSpan(span, &err)
+
+	wallets := make([]models.Wallet, 0, len(seed))
+	seen := make(map[string]struct{}, len(seed))
+	for i, dto := range seed {
+		address, err := usecase.ParseAddress(dto.Address)
+		if err != nil {
+			return usecase.ErrInvalid.Wrap(err, "seed wallet %d: invalid address %q", i, dto.Address)
+		}
+		if _, ok := seen[address]; ok {
+			return usecase.ErrInvalid.New("seed wallet %d: duplicate address %s", i, address)
+		}
+		seen[address] = struct{}{}
+
+		balance, err := usecase.ParseAmount(dto.Balance, wuc.currency)
+		if err != nil {
+			return usecase.ErrInvalid.Wrap(err, "seed wallet %d: invalid balance %q", i, dto.Balance)
+		}
+
+		wallets = append(wallets, models.Wallet{Address: address, Balance: balance})
+	}
+
+	write := wuc.insertMissing
+	if overwrite {
+		write = wuc.upsert
+	}
+	for _, wallet := range wallets {
+		if err := write(ctx, wallet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertMissing inserts seed wallet and records audit of insert, existing wallet is kept
+func (wuc Usecase) insertMissing(ctx context.Context, wallet models.Wallet) error {
+	record := models.AuditRecord{
+		Action: models.AuditWalletSeed,
+		Wallet: wallet.Address,
+		After:  models.AuditState{"balance": wallet.Balance.String()},
+	}
+
+	stored, inserted, err := wuc.interactor.InsertIfMissing(ctx, wallet)
+	if err != nil {
+		err = usecase.ErrOnInsert.Wrap(err, "failed to insert wallet %s", wallet.Address)
+		wuc.auditor.Record(ctx, record, err)
+		return err
+	}
+
+	if !inserted {
+		if !stored.Balance.Equal(wallet.Balance) {
+			wuc.logger.WarnContext(ctx, "Seed wallet exists with other balance, it is kept",
+				"address", stored.Address, "balance", stored.Balance, "seed_balance", wallet.Balance)
+		}
+		return nil
+	}
+
+	wuc.auditor.Record(ctx, record, nil)
+	wuc.logger.InfoContext(ctx, "Seed wallet", "address", stored.Address, "balance", stored.Balance)
+
+	return nil
+}
+
+// upsert upserts seed wallet and records audit of insert or of changed balance
+func (wuc Usecase) upsert(ctx context.Context, wallet models.Wallet) error {
+	record := models.AuditRecord{
+		Action: models.AuditWalletSeed,
+		Wallet: wallet.Address,
+		After:  models.AuditState{"balance": wallet.Balance.String()},
+	}
+	// missing wallet has no state before seed
+	existing, err := wuc.interactor.GetByAddress(ctx, wallet.Address)
+	if err == nil {
+		record.Before = models.AuditState{"balance": existing.Balance.String()}
+	}
+
+	stored, inserted, err := wuc.interactor.Upsert(ctx, wallet)
+	if err != nil {
+		err = usecase.ErrOnInsert.Wrap(err, "failed to upsert wallet %s", wallet.Address)
+		wuc.auditor.Record(ctx, record, err)
+		return err
+	}
+
+	if !inserted && existing.Balance.Equal(stored.Balance) {
+		return nil
+	}
+
+	wuc.auditor.Record(ctx, record, nil)
+	wuc.logger.InfoContext(ctx, "Seed wallet", "address", stored.Address, "balance", stored.Balance, "inserted", inserted)
+
+	return nil
+}
+
+// SeedDemo describes inserting count wallets with random addresses and the same balance
+func (wuc Usecase) SeedDemo(ctx context.Context, count int, balance string) (_ []dtos.SeedWallet, err error) {
+	ctx, span := tracer.Start(ctx, "wallet.SeedDemo")
+	defer usecase.EndSpan(span, &err)
+
+	demoBalance, err := usecase.ParseAmount(balance, wuc.currency)
+	if err != nil {
+		return nil, usecase.ErrInvalid.Wrap(err, "invalid demo balance %q", balance)
+	}
+
+	result := make([]dtos.SeedWallet, 0, count)
+	for range count {
+		address := uuid.NewString()
+		wallet, err := wuc.interactor.Insert(ctx, models.Wallet{
+			Address: address,
+			Balance: demoBalance,
+		})
+		record := models.AuditRecord{
+			Action: models.AuditWalletCreate,
+			Wallet: address,
+			After:  models.AuditState{"balance": wallet.Balance.String(), "frozen": "false"},
+		}
+		wuc.auditor.Record(ctx, record, err)
+		if err != nil {
+			return nil, usecase.ErrOnInsert.Wrap(err, "failed to insert wallet")
+		}
+
+		result = append(result, dtos.SeedWallet{
+			Address: wallet.Address,
+			Balance: wallet.Balance.String(),
+		})
+	}
+
+	wuc.logger.InfoContext(ctx, "Demo wallets created", "count", count, "balance", demoBalance)
+
+	return result, nil
+}
+
+// MissingWallets describes finding addresses, that have no stored wallet,
+// e.g. wallets of previous run of app over memory storage
+func (wuc Usecase) MissingWallets(ctx context.Context, addresses []string) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "wallet.MissingWallets")
+	defer usecase.EndSpan(span, &err)
+
+	var missing []string
+	for _, address := range addresses {
+		_, err := wuc.interactor.GetByAddress(ctx, address)
+		switch {
+		case storageImpl.IsNotFoundErr(err):
+			missing = append(missing, address)
+		case err != nil:
+			return nil, usecase.ErrOnGet.Wrap(err, "failed to get wallet %s", address)
+		}
+	}
+
+	return missing, nil
+}
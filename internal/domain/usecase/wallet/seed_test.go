@@ -0,0 +1,133 @@
+package wallet_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/usecase"
+	"github.com/lunn06/wallet/internal/domain/usecase/audit"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/domain/usecase/wallet"
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/storage/memory"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+var usd = money.Currency{Code: "USD", Scale: 2}
+
+func newUsecase() (wallet.Usecase, memory.WalletStorage) {
+	storage := memory.NewStorage(usd)
+	ws := memory.WalletStorage{Storage: storage}
+	history := snapshot.NewUsecase(ws, memory.TransactionStorage{Storage: storage}, memory.SnapshotStorage{Storage: storage}, nil)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	auditor := audit.NewUsecase(memory.AuditStorage{Storage: storage}, nil, logger)
+
+	return wallet.NewUsecase(ws, history, auditor, usd, logger), ws
+}
+
+func getBalance(t *testing.T, wuc wallet.Usecase, address string) string {
+	t.Helper()
+
+	resp, err := wuc.GetBalance(context.Background(), dtos.GetBalanceRequest{Address: address})
+	require.NoError(t, err)
+
+	return resp.Balance
+}
+
+func TestUsecase_Seed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("idempotent insert", func(t *testing.T) {
+		wuc, ws := newUsecase()
+		a, b := uuid.NewString(), uuid.NewString()
+
+		seed := []dtos.SeedWallet{{Address: a, Balance: "100"}, {Address: strings.ToUpper(b), Balance: "0.5"}}
+		require.NoError(t, wuc.Seed(ctx, seed, false))
+		require.NoError(t, wuc.Seed(ctx, seed, false))
+
+		wallets, err := ws.List(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, wallets, 2)
+		assert.Equal(t, "0.5", getBalance(t, wuc, b))
+
+		// balance of existing wallet is changed by treasury only
+		require.NoError(t, wuc.Seed(ctx, []dtos.SeedWallet{{Address: a, Balance: "42"}}, false))
+		assert.Equal(t, "100", getBalance(t, wuc, a))
+	})
+	t.Run("upsert overwrites balances", func(t *testing.T) {
+		wuc, ws := newUsecase()
+		a, b := uuid.NewString(), uuid.NewString()
+
+		require.NoError(t, wuc.Seed(ctx, []dtos.SeedWallet{{Address: a, Balance: "100"}}, true))
+		require.NoError(t, wuc.Seed(ctx, []dtos.SeedWallet{{Address: a, Balance: "42"}, {Address: b, Balance: "1"}}, true))
+
+		wallets, err := ws.List(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, wallets, 2)
+		assert.Equal(t, "42", getBalance(t, wuc, a))
+		assert.Equal(t, "1", getBalance(t, wuc, b))
+	})
+	t.Run("invalid seed writes nothing", func(t *testing.T) {
+		address := uuid.NewString()
+
+		for name, seed := range map[string][]dtos.SeedWallet{
+			"invalid address": {{Address: address, Balance: "1"}, {Address: "not-uuid", Balance: "1"}},
+			"not random address": {
+				{Address: address, Balance: "1"},
+				{Address: uuid.NewSHA1(uuid.NameSpaceURL, []byte("wallet")).String(), Balance: "1"},
+			},
+			"negative balance":  {{Address: address, Balance: "1"}, {Address: uuid.NewString(), Balance: "-1"}},
+			"excess scale":      {{Address: address, Balance: "1"}, {Address: uuid.NewString(), Balance: "0.001"}},
+			"duplicate address": {{Address: address, Balance: "1"}, {Address: strings.ToUpper(address), Balance: "2"}},
+		} {
+			wuc, ws := newUsecase()
+
+			err := wuc.Seed(ctx, seed, false)
+			assert.True(t, errorx.IsOfType(err, usecase.ErrInvalid), "%s: %v", name, err)
+
+			wallets, err := ws.List(ctx, 0, 10)
+			require.NoError(t, err)
+			assert.Empty(t, wallets, name)
+		}
+	})
+}
+
+func TestUsecase_SeedDemo(t *testing.T) {
+	ctx := context.Background()
+	wuc, _ := newUsecase()
+
+	wallets, err := wuc.SeedDemo(ctx, 3, "100.0")
+	require.NoError(t, err)
+	require.Len(t, wallets, 3)
+	for _, w := range wallets {
+		assert.Equal(t, "100", getBalance(t, wuc, w.Address))
+	}
+
+	_, err = wuc.SeedDemo(ctx, 1, "-1")
+	assert.True(t, errorx.IsOfType(err, usecase.ErrInvalid))
+}
+
+func TestUsecase_MissingWallets(t *testing.T) {
+	ctx := context.Background()
+	wuc, _ := newUsecase()
+
+	wallets, err := wuc.SeedDemo(ctx, 2, "1")
+	require.NoError(t, err)
+	unknown := uuid.NewString()
+
+	missing, err := wuc.MissingWallets(ctx, []string{wallets[0].Address, unknown, wallets[1].Address})
+	require.NoError(t, err)
+	assert.Equal(t, []string{unknown}, missing)
+
+	_, err = wuc.MissingWallets(ctx, []string{"not-uuid"})
+	assert.Error(t, err)
+}
@@ -3,26 +3,53 @@ package wallet
 import (
 	"context"
 	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
 
 	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
+var tracer = otel.Tracer("github.com/lunn06/wallet/internal/domain/usecase/wallet")
+
 // Defining interactor interface, that define necessary to usecase methods
 
 type walletInteractor interface {
 	GetByAddress(ctx context.Context, address string) (models.Wallet, error)
 	Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error)
+	InsertIfMissing(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error)
+	Upsert(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error)
+}
+
+// balanceHistory computes balance at past moments (e.g. snapshot.Usecase)
+type balanceHistory interface {
+	BalanceAt(ctx context.Context, address string, moment time.Time) (models.Balance, error)
+}
+
+// auditor records state-changing actions (e.g. audit.Usecase)
+type auditor interface {
+	Record(ctx context.Context, record models.AuditRecord, err error)
 }
 
 // Usecase contains interactors interfaces
 type Usecase struct {
 	logger     *slog.Logger
 	interactor walletInteractor
+	history    balanceHistory
+	auditor    auditor
+	currency   money.Currency
 }
 
-func NewUsecase(interactor walletInteractor, logger *slog.Logger) Usecase {
+func NewUsecase(interactor walletInteractor, history balanceHistory, auditor auditor, currency money.Currency, logger *slog.Logger) Usecase {
 	if interactor == nil {
 		panic("interactor can not be nil")
 	}
-	return Usecase{interactor: interactor, logger: logger}
+	if history == nil {
+		panic("history can not be nil")
+	}
+	if auditor == nil {
+		panic("auditor can not be nil")
+	}
+	return Usecase{interactor: interactor, history: history, auditor: auditor, currency: currency, logger: logger}
 }
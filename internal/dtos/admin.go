@@ -0,0 +1,56 @@
+package dtos
+
+import "time"
+
+// AdminWallet is wallet as shown to operators
+type AdminWallet struct {
+	ID      int    `json:"id"`
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+	Frozen  bool   `json:"frozen"`
+}
+
+// HistoryRequest requests successful transactions of wallet
+// with timestamp in (From, To], zero To means now
+type HistoryRequest struct {
+	Address string    `json:"address"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+}
+
+// TreasuryRequest requests mint or burn of Amount, Reason is required for audit
+type TreasuryRequest struct {
+	Amount string `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// TreasuryOperation is mint or burn with treasury Balance after it
+type TreasuryOperation struct {
+	ID        int       `json:"id"`
+	Kind      string    `json:"kind"`
+	Amount    string    `json:"amount"`
+	Reason    string    `json:"reason"`
+	Balance   string    `json:"balance"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReconcileReport lists wallets, which stored balance differs from balance
+// computed from their snapshots and transactions (ledger)
+type ReconcileReport struct {
+	Checked    int                 `json:"checked"`
+	Mismatches []ReconcileMismatch `json:"mismatches"`
+}
+
+// ReconcileMismatch describes wallet, which ledger balance differs from stored one
+// or can't be computed, then Error explains why
+type ReconcileMismatch struct {
+	Address string `json:"address"`
+	Stored  string `json:"stored"`
+	Ledger  string `json:"ledger,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// LogLevel is level of app logger, one of "debug", "info", "warn" or "error"
+type LogLevel struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error"`
+}
@@ -0,0 +1,39 @@
+package dtos
+
+import "time"
+
+// AuditQuery selects page of audit records, zero fields match any record.
+// Wallet matches both wallet and counterparty, period is [From, To).
+// AfterID is NextAfterID of previous page, zero Limit means default one
+type AuditQuery struct {
+	Actor   string    `json:"actor"`
+	Wallet  string    `json:"wallet" validate:"omitempty,uuid"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	AfterID int       `json:"after_id" validate:"gte=0"`
+	Limit   int       `json:"limit" validate:"gte=0,lte=1000"`
+}
+
+// AuditPage is page of audit records ordered by id. NextAfterID
+// requests the next page, it's omitted on the last page
+type AuditPage struct {
+	Records     []AuditRecord `json:"records"`
+	NextAfterID int           `json:"next_after_id,omitempty"`
+}
+
+// AuditRecord is record of state-changing action, Before and After are
+// changed state, e.g. wallet balances. Error is cause of failed action
+type AuditRecord struct {
+	ID           int               `json:"id"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Actor        string            `json:"actor"`
+	SourceIP     string            `json:"source_ip,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	Action       string            `json:"action"`
+	Wallet       string            `json:"wallet,omitempty"`
+	Counterparty string            `json:"counterparty,omitempty"`
+	Before       map[string]string `json:"before,omitempty"`
+	After        map[string]string `json:"after,omitempty"`
+	Outcome      string            `json:"outcome"`
+	Error        string            `json:"error,omitempty"`
+}
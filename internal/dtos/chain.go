@@ -0,0 +1,40 @@
+package dtos
+
+// Reasons of broken chain link
+const (
+	ChainHashMismatch        = "hash_mismatch"
+	ChainPrevHashMismatch    = "prev_hash_mismatch"
+	ChainUnchained           = "unchained"
+	ChainCheckpointMismatch  = "checkpoint_mismatch"
+	ChainCheckpointMissing   = "checkpoint_transaction_missing"
+	ChainCheckpointSignature = "checkpoint_signature_invalid"
+)
+
+// ChainReport is result of transactions hash chain verification.
+// Unchained transactions were inserted before chain was introduced,
+// checkpoints are verified only if signing key is configured.
+// Head is the last verified link
+type ChainReport struct {
+	Valid               bool        `json:"valid"`
+	Checked             int         `json:"checked"`
+	Unchained           int         `json:"unchained"`
+	CheckpointsVerified int         `json:"checkpoints_verified"`
+	Head                *ChainLink  `json:"head,omitempty"`
+	BrokenLink          *BrokenLink `json:"broken_link,omitempty"`
+}
+
+// ChainLink is chained transaction with hex encoded hash
+type ChainLink struct {
+	TransactionID int    `json:"transaction_id"`
+	Hash          string `json:"hash"`
+}
+
+// BrokenLink describes the first transaction or checkpoint, that breaks chain.
+// Expected and Actual are hex encoded hashes
+type BrokenLink struct {
+	TransactionID int    `json:"transaction_id"`
+	CheckpointID  int    `json:"checkpoint_id,omitempty"`
+	Reason        string `json:"reason"`
+	Expected      string `json:"expected,omitempty"`
+	Actual        string `json:"actual,omitempty"`
+}
@@ -1,5 +1,22 @@
 package dtos
 
-type ErrorResp struct {
-	Error string `json:"message"`
+// Problem is RFC 7807 problem details of error response. Code is
+// machine-readable kind of problem, Type is URI that identifies it
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes invalid field of request, Field is its json
+// name or query parameter and Rule is name of violated rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,11 @@
+package dtos
+
+type LivenessResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status  string            `json:"status"`
+	Checks  map[string]string `json:"checks"`
+	Workers map[string]any    `json:"workers"`
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+// Statement formats
+const (
+	StatementCSV   = "csv"
+	StatementJSONL = "jsonl"
+)
+
+// Statement row types, statement is complete only if it ends with closing row
+const (
+	StatementOpening     = "opening"
+	StatementTransaction = "transaction"
+	StatementClosing     = "closing"
+)
+
+// StatementRequest requests statement of wallet for period (From, To]
+type StatementRequest struct {
+	Address string    `json:"address" validate:"uuid4,required"`
+	From    time.Time `json:"from" validate:"required"`
+	To      time.Time `json:"to" validate:"required,gtfield=From"`
+	Format  string    `json:"format" validate:"oneof=csv jsonl"`
+}
+
+// StatementRow is one row of statement. Amount is signed change
+// of balance by transaction, Balance is running balance after it
+type StatementRow struct {
+	Type        string    `json:"type"`
+	ID          int       `json:"id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+	FromAddress string    `json:"from_address,omitempty"`
+	ToAddress   string    `json:"to_address,omitempty"`
+	Amount      string    `json:"amount,omitempty"`
+	Balance     string    `json:"balance"`
+}
@@ -18,10 +18,12 @@ type Transaction struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// SendRequest requests transfer of Amount between wallets. Amount is
+// positive plain decimal within scale and max amount of transfer limits
 type SendRequest struct {
-	FromAddress string `json:"from"`
-	ToAddress   string `json:"to"`
-	Amount      string `json:"amount"`
+	FromAddress string `json:"from" validate:"required,uuid4"`
+	ToAddress   string `json:"to" validate:"required,uuid4"`
+	Amount      string `json:"amount" validate:"required,positive_decimal,amount_scale,amount_max"`
 }
 
 type SendResponse struct {
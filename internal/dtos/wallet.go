@@ -1,9 +1,20 @@
 package dtos
 
+import "time"
+
+// GetBalanceRequest requests current balance or balance at moment At
 type GetBalanceRequest struct {
-	Address string `json:"address" validate:"uuid4,required"`
+	Address string     `json:"address" validate:"uuid4,required"`
+	At      *time.Time `json:"at"`
 }
 
 type GetBalanceResponse struct {
-	Balance string `json:"balance"`
+	Balance string     `json:"balance"`
+	At      *time.Time `json:"at,omitempty"`
+}
+
+// SeedWallet is wallet of seed file, which Balance is set on every seeding
+type SeedWallet struct {
+	Address string `json:"address" yaml:"address"`
+	Balance string `json:"balance" yaml:"balance"`
 }
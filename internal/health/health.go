@@ -0,0 +1,124 @@
+// Package health implements liveness and readiness probes of the app
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lunn06/wallet/internal/dtos"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+
+	checkTimeout = 2 * time.Second
+)
+
+// Check is dependency check, that returns error if dependency isn't ready
+type Check func(ctx context.Context) error
+
+// Reporter returns current state of background worker
+type Reporter func() any
+
+// Probe collects readiness checks and workers states.
+// It turns to not ready as soon as Shutdown is called.
+// Causes of failed checks are logged, as probe response is public
+type Probe struct {
+	logger *slog.Logger
+
+	mu        sync.RWMutex
+	checks    map[string]Check
+	reporters map[string]Reporter
+
+	shuttingDown atomic.Bool
+}
+
+func NewProbe(logger *slog.Logger) *Probe {
+	return &Probe{
+		logger:    logger,
+		checks:    make(map[string]Check),
+		reporters: make(map[string]Reporter),
+	}
+}
+
+func (p *Probe) AddCheck(name string, check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checks[name] = check
+}
+
+func (p *Probe) AddReporter(name string, reporter Reporter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reporters[name] = reporter
+}
+
+// Shutdown marks app as not ready to let load balancers drain traffic
+func (p *Probe) Shutdown() {
+	p.shuttingDown.Store(true)
+}
+
+// Live reports that process is alive
+func (p *Probe) Live() dtos.LivenessResponse {
+	return dtos.LivenessResponse{Status: StatusOK}
+}
+
+// Ready runs all checks concurrently and reports their results.
+// Second return value is false if app must not receive traffic
+func (p *Probe) Ready(ctx context.Context) (dtos.ReadinessResponse, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	resp := dtos.ReadinessResponse{
+		Status:  StatusOK,
+		Checks:  make(map[string]string, len(p.checks)),
+		Workers: make(map[string]any, len(p.reporters)),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		resMu   sync.Mutex
+		allPass = true
+	)
+	for name, check := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := StatusOK
+			if err := check(ctx); err != nil {
+				p.logger.WarnContext(ctx, "Readiness check failed", "check", name, "err", err)
+				result = StatusUnavailable
+			}
+
+			resMu.Lock()
+			defer resMu.Unlock()
+			resp.Checks[name] = result
+			allPass = allPass && result == StatusOK
+		}()
+	}
+	wg.Wait()
+
+	for name, reporter := range p.reporters {
+		resp.Workers[name] = reporter()
+	}
+
+	switch {
+	case p.shuttingDown.Load():
+		resp.Status = StatusShuttingDown
+	case !allPass:
+		resp.Status = StatusUnavailable
+	}
+
+	return resp, resp.Status == StatusOK
+}
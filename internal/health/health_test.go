@@ -0,0 +1,92 @@
+package health_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/dtos"
+	"github.com/lunn06/wallet/internal/health"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func passing(context.Context) error { return nil }
+
+func TestProbe_Ready(t *testing.T) {
+	t.Run("all checks pass", func(t *testing.T) {
+		probe := health.NewProbe(discard)
+		probe.AddCheck("database", passing)
+		probe.AddCheck("migrations", passing)
+		probe.AddReporter("snapshot", func() any { return "idle" })
+
+		resp, ready := probe.Ready(context.Background())
+		assert.True(t, ready)
+		assert.Equal(t, dtos.ReadinessResponse{
+			Status:  health.StatusOK,
+			Checks:  map[string]string{"database": health.StatusOK, "migrations": health.StatusOK},
+			Workers: map[string]any{"snapshot": "idle"},
+		}, resp)
+	})
+	t.Run("failed check hides its cause", func(t *testing.T) {
+		var logs bytes.Buffer
+		probe := health.NewProbe(slog.New(slog.NewTextHandler(&logs, nil)))
+		probe.AddCheck("database", func(context.Context) error {
+			return errors.New("dial tcp 10.0.0.5:5432: connection refused")
+		})
+		probe.AddCheck("migrations", passing)
+
+		resp, ready := probe.Ready(context.Background())
+		assert.False(t, ready)
+		assert.Equal(t, health.StatusUnavailable, resp.Status)
+		assert.Equal(t, map[string]string{
+			"database":   health.StatusUnavailable,
+			"migrations": health.StatusOK,
+		}, resp.Checks)
+
+		// cause is kept for operators
+		assert.Contains(t, logs.String(), "check=database")
+		assert.Contains(t, logs.String(), "connection refused")
+	})
+	t.Run("checks run concurrently with deadline", func(t *testing.T) {
+		probe := health.NewProbe(discard)
+		for _, name := range []string{"first", "second"} {
+			probe.AddCheck(name, func(ctx context.Context) error {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok, "check must be bounded")
+				time.Sleep(100 * time.Millisecond)
+				return nil
+			})
+		}
+
+		start := time.Now()
+		_, ready := probe.Ready(context.Background())
+		assert.True(t, ready)
+		assert.Less(t, time.Since(start), 200*time.Millisecond)
+	})
+}
+
+func TestProbe_Shutdown(t *testing.T) {
+	probe := health.NewProbe(discard)
+	probe.AddCheck("database", passing)
+
+	_, ready := probe.Ready(context.Background())
+	require.True(t, ready)
+
+	probe.Shutdown()
+
+	resp, ready := probe.Ready(context.Background())
+	assert.False(t, ready)
+	assert.Equal(t, health.StatusShuttingDown, resp.Status)
+	// checks are still reported while traffic is drained
+	assert.Equal(t, map[string]string{"database": health.StatusOK}, resp.Checks)
+
+	assert.Equal(t, dtos.LivenessResponse{Status: health.StatusOK}, probe.Live())
+}
@@ -0,0 +1,162 @@
+// Package metrics contains prometheus collectors of the service
+// and http handler that exposes them in prometheus text format
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/lunn06/wallet/pkg/semaphore"
+)
+
+const namespace = "wallet"
+
+// Metrics is registry of service collectors, that
+// other layers report to via Observe* methods
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	transfers      *prometheus.CounterVec
+	transferAmount *prometheus.CounterVec
+
+	workerRuns     *prometheus.CounterVec
+	workerDuration *prometheus.HistogramVec
+	workerLastRun  *prometheus.GaugeVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Count of handled http requests",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled http requests",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "transfers",
+			Name:      "total",
+			Help:      "Count of transfers between wallets",
+		}, []string{"outcome"}),
+		transferAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "transfers",
+			Name:      "amount_total",
+			Help:      "Sum of transferred amounts",
+		}, []string{"outcome"}),
+
+		workerRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "runs_total",
+			Help:      "Count of background worker runs",
+		}, []string{"worker", "outcome"}),
+		workerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "run_duration_seconds",
+			Help:      "Duration of background worker runs",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"worker"}),
+		workerLastRun: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "worker",
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time of the last background worker run",
+		}, []string{"worker", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.transfers,
+		m.transferAmount,
+		m.workerRuns,
+		m.workerDuration,
+		m.workerLastRun,
+	)
+
+	return m
+}
+
+// Register adds external collectors (e.g. storage pool stats) to registry
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler returns http.Handler that serves metrics in prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveTransfer(outcome string, amount float64) {
+	m.transfers.WithLabelValues(outcome).Inc()
+	m.transferAmount.WithLabelValues(outcome).Add(amount)
+}
+
+func (m *Metrics) ObserveWorkerRun(worker string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+
+	m.workerRuns.WithLabelValues(worker, outcome).Inc()
+	m.workerDuration.WithLabelValues(worker).Observe(duration.Seconds())
+	m.workerLastRun.WithLabelValues(worker, outcome).SetToCurrentTime()
+}
+
+// RegisterSemaphore exposes occupancy of named semaphore.Semaphore
+func (m *Metrics) RegisterSemaphore(name string, sem *semaphore.Semaphore) error {
+	labels := prometheus.Labels{"semaphore": name}
+
+	gauge := func(metric, help string, value func(semaphore.Stats) int64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "semaphore",
+			Name:        metric,
+			Help:        help,
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(value(sem.Stats()))
+		})
+	}
+
+	return m.Register(
+		gauge("size", "Max total weight of semaphore", func(s semaphore.Stats) int64 { return s.Size }),
+		gauge("acquired", "Currently acquired weight of semaphore", func(s semaphore.Stats) int64 { return s.Acquired }),
+		gauge("holders", "Count of unreleased semaphore acquisitions", func(s semaphore.Stats) int64 { return s.Holders }),
+		gauge("waiters", "Count of blocked semaphore acquisitions", func(s semaphore.Stats) int64 { return s.Waiters }),
+	)
+}
@@ -0,0 +1,63 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/metrics"
+)
+
+// scrape returns metrics exposed by handler in prometheus text format
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	return rec.Body.String()
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	m := metrics.New()
+
+	m.ObserveHTTPRequest(http.MethodPost, "/api/v1/send", http.StatusOK, 20*time.Millisecond)
+	m.ObserveHTTPRequest(http.MethodPost, "/api/v1/send", http.StatusOK, 30*time.Millisecond)
+	m.ObserveTransfer("success", 10.5)
+	m.ObserveTransfer("success", 0.5)
+	m.ObserveWorkerRun("snapshot", time.Second, nil)
+	m.ObserveWorkerRun("snapshot", time.Second, errors.New("boom"))
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`wallet_http_requests_total{method="POST",route="/api/v1/send",status="200"} 2`,
+		`wallet_http_request_duration_seconds_sum{method="POST",route="/api/v1/send",status="200"} 0.05`,
+		`wallet_transfers_total{outcome="success"} 2`,
+		`wallet_transfers_amount_total{outcome="success"} 11`,
+		`wallet_worker_runs_total{outcome="success",worker="snapshot"} 1`,
+		`wallet_worker_runs_total{outcome="failure",worker="snapshot"} 1`,
+		`wallet_worker_run_duration_seconds_count{worker="snapshot"} 2`,
+	} {
+		assert.Contains(t, body, line)
+	}
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetrics_Register(t *testing.T) {
+	m := metrics.New()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "external_gauge", Help: "External collector"})
+	gauge.Set(42)
+
+	require.NoError(t, m.Register(gauge))
+	assert.Contains(t, scrape(t, m), "external_gauge 42")
+
+	// the same collector can't be registered twice
+	assert.Error(t, m.Register(gauge))
+}
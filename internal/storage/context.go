@@ -0,0 +1,17 @@
+package storage
+
+import "context"
+
+type primaryKey struct{}
+
+// WithPrimary marks ctx to route all reads to primary database,
+// so reads observe writes made just before them ("read your writes")
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequired reports whether ctx is marked by WithPrimary
+func PrimaryRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(primaryKey{}).(bool)
+	return required
+}
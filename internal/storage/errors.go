@@ -1,6 +1,12 @@
 package storage
 
-import "github.com/joomcode/errorx"
+import (
+	"errors"
+
+	"github.com/joomcode/errorx"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
 
 func IsInternalErr(err error) bool {
 	return errorx.HasTrait(err, Internal)
@@ -18,19 +24,39 @@ func IsDuplicateErr(err error) bool {
 	return errorx.HasTrait(err, errorx.Duplicate())
 }
 
+func IsInsufficientFundsErr(err error) bool {
+	return errorx.IsOfType(err, ErrInsufficientFunds)
+}
+
+func IsFrozenErr(err error) bool {
+	return errorx.IsOfType(err, ErrFrozen)
+}
+
+// BalanceErr maps error of arithmetic on balance of wallet with address:
+// negative result is lack of balance, the rest is mismatch of currencies
+func BalanceErr(err error, address string) error {
+	if errors.Is(err, models.ErrNegativeBalance) {
+		return ErrInsufficientFunds.New("address = %s", address)
+	}
+	return ErrInvalid.Wrap(err, "address = %s", address)
+}
+
 var (
 	DBErrors = errorx.NewNamespace("database")
 
-	External           = errorx.RegisterTrait("external")
-	ErrNotFound        = DBErrors.NewType("not_found", External, errorx.NotFound())
-	ErrInvalid         = DBErrors.NewType("invalid", External)
-	ErrUniqueViolation = DBErrors.NewType("unique_violation", External, errorx.Duplicate())
+	External             = errorx.RegisterTrait("external")
+	ErrNotFound          = DBErrors.NewType("not_found", External, errorx.NotFound())
+	ErrInvalid           = DBErrors.NewType("invalid", External)
+	ErrUniqueViolation   = DBErrors.NewType("unique_violation", External, errorx.Duplicate())
+	ErrInsufficientFunds = DBErrors.NewType("insufficient_funds", External)
+	ErrFrozen            = DBErrors.NewType("frozen", External)
 
 	Internal             = errorx.RegisterTrait("internal")
 	ErrFailedToInsert    = DBErrors.NewType("failed_to_insert", Internal)
 	ErrFailedToUpdate    = DBErrors.NewType("failed_to_update", Internal)
 	ErrFailedToDelete    = DBErrors.NewType("failed_to_delete", Internal)
 	ErrFailedStmtBuild   = DBErrors.NewType("failed_to_build statement", Internal)
+	ErrUnavailable       = DBErrors.NewType("unavailable", Internal, errorx.Timeout())
 	UnhandledErr         = DBErrors.NewType("unhandled", Internal)
 	ErrFailedToMarshal   = DBErrors.NewType("failed to marshal", Internal)
 	ErrFailedToUnmarshal = DBErrors.NewType("failed_to_unmarshal", Internal)
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"maps"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+type AuditStorage struct {
+	*Storage
+}
+
+var _ storageLayer.AuditStorage = AuditStorage{}
+
+func (as AuditStorage) Insert(ctx context.Context, record models.AuditRecord) (models.AuditRecord, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	record.ID = len(as.auditRecords) + 1
+	record.Timestamp = record.Timestamp.UTC()
+	record = cloneAuditRecord(record)
+	as.auditRecords = append(as.auditRecords, record)
+
+	return cloneAuditRecord(record), nil
+}
+
+func (as AuditStorage) List(ctx context.Context, filter models.AuditFilter, afterID int, limit int) ([]models.AuditRecord, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	// ids are sequential and records are never deleted
+	var records []models.AuditRecord
+	for _, record := range as.auditRecords[min(max(afterID, 0), len(as.auditRecords)):] {
+		if len(records) == limit {
+			break
+		}
+		if filter.Match(record) {
+			records = append(records, cloneAuditRecord(record))
+		}
+	}
+
+	return records, nil
+}
+
+// cloneAuditRecord copies states of record, so stored record can't be changed by caller
+func cloneAuditRecord(record models.AuditRecord) models.AuditRecord {
+	record.Before = maps.Clone(record.Before)
+	record.After = maps.Clone(record.After)
+	return record
+}
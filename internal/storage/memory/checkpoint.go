@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+type CheckpointStorage struct {
+	*Storage
+}
+
+var _ storageLayer.CheckpointStorage = CheckpointStorage{}
+
+func (cs CheckpointStorage) Insert(ctx context.Context, checkpoint models.Checkpoint) (models.Checkpoint, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	// ids are sequential and transactions are never deleted
+	if checkpoint.TransactionID < 1 || checkpoint.TransactionID > len(cs.transactions) {
+		return models.Checkpoint{}, storageLayer.ErrNotFound.New(
+			"referenced transaction not found, id = %d", checkpoint.TransactionID,
+		)
+	}
+
+	checkpoint.ID = len(cs.checkpoints) + 1
+	checkpoint.Timestamp = checkpoint.Timestamp.UTC()
+	cs.checkpoints = append(cs.checkpoints, checkpoint)
+
+	return checkpoint, nil
+}
+
+func (cs CheckpointStorage) GetLatest(ctx context.Context) (models.Checkpoint, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	if len(cs.checkpoints) == 0 {
+		return models.Checkpoint{}, storageLayer.ErrNotFound.New("no checkpoints")
+	}
+
+	return cs.checkpoints[len(cs.checkpoints)-1], nil
+}
+
+func (cs CheckpointStorage) GetAll(ctx context.Context) ([]models.Checkpoint, error) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return slices.Clone(cs.checkpoints), nil
+}
@@ -0,0 +1,22 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/lunn06/wallet/internal/storage/memory"
+	"github.com/lunn06/wallet/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storages {
+		storage := memory.NewStorage(storagetest.Currency)
+		return storagetest.Storages{
+			Wallet:      memory.WalletStorage{Storage: storage},
+			Transaction: memory.TransactionStorage{Storage: storage},
+			Snapshot:    memory.SnapshotStorage{Storage: storage},
+			Checkpoint:  memory.CheckpointStorage{Storage: storage},
+			Treasury:    memory.TreasuryStorage{Storage: storage},
+			Audit:       memory.AuditStorage{Storage: storage},
+		}
+	})
+}
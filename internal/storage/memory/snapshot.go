@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+type SnapshotStorage struct {
+	*Storage
+}
+
+var _ storageLayer.SnapshotStorage = SnapshotStorage{}
+
+func (ss SnapshotStorage) Insert(ctx context.Context, snapshot models.Snapshot) (models.Snapshot, error) {
+	address, err := normalizeAddress(snapshot.Address)
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	snapshot.Address = address
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if _, ok := ss.walletsByAddress[address]; !ok {
+		return models.Snapshot{}, storageLayer.ErrNotFound.New("referenced wallet not found, address = %s", address)
+	}
+
+	return ss.insertSnapshot(snapshot), nil
+}
+
+func (ss SnapshotStorage) GetLatest(ctx context.Context, address string, moment time.Time) (models.Snapshot, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	var (
+		latest models.Snapshot
+		found  bool
+	)
+	for _, s := range ss.snapshots {
+		if s.Address != address || s.Timestamp.After(moment) {
+			continue
+		}
+		if !found || !s.Timestamp.Before(latest.Timestamp) {
+			latest, found = s, true
+		}
+	}
+	if !found {
+		return models.Snapshot{}, storageLayer.ErrNotFound.New("snapshot not found, address = %s, moment = %s", address, moment)
+	}
+
+	return latest, nil
+}
+
+func (ss SnapshotStorage) DeleteBefore(ctx context.Context, moment time.Time) (int, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	// the newest snapshot before moment per wallet is kept
+	newest := make(map[string]models.Snapshot)
+	for _, s := range ss.snapshots {
+		if !s.Timestamp.Before(moment) {
+			continue
+		}
+		if n, ok := newest[s.Address]; !ok || s.Timestamp.After(n.Timestamp) {
+			newest[s.Address] = s
+		}
+	}
+
+	kept := ss.snapshots[:0]
+	for _, s := range ss.snapshots {
+		if !s.Timestamp.Before(moment) || !s.Timestamp.Before(newest[s.Address].Timestamp) {
+			kept = append(kept, s)
+		}
+	}
+	deleted := len(ss.snapshots) - len(kept)
+	clear(ss.snapshots[len(kept):])
+	ss.snapshots = kept
+
+	return deleted, nil
+}
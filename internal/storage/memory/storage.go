@@ -0,0 +1,111 @@
+// Package memory contains thread-safe in-memory implementation
+// of storage layer with the same semantics as pgx storage
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// Storage is shared state of in-memory storages (that need to embed in other storages).
+// All access to it is serialized by inner RWMutex. Wallets without balance get zero
+// in currency, as wallets read from other storages do
+type Storage struct {
+	mu       sync.RWMutex
+	currency money.Currency
+
+	wallets          map[int]models.Wallet
+	walletsByAddress map[string]int
+	lastWalletID     int
+
+	transactions      []models.Transaction
+	lastTransactionID int
+
+	snapshots      []models.Snapshot
+	lastSnapshotID int
+
+	checkpoints []models.Checkpoint
+
+	treasuryOperations []models.TreasuryOperation
+
+	auditRecords []models.AuditRecord
+}
+
+func NewStorage(currency money.Currency) *Storage {
+	return &Storage{
+		currency:         currency,
+		wallets:          make(map[int]models.Wallet),
+		walletsByAddress: make(map[string]int),
+	}
+}
+
+// Ping implements health check of storage, in-memory storage is always available
+func (s *Storage) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Close implements app.Closer interface, data is kept until Storage is garbage collected
+func (s *Storage) Close(ctx context.Context) error {
+	return nil
+}
+
+// normalizeAddress validates address as uuid and returns
+// its canonical form, as postgres UUID type does
+func normalizeAddress(address string) (string, error) {
+	parsed, err := uuid.Parse(address)
+	if err != nil {
+		return "", storageLayer.ErrInvalid.Wrap(err, "address = %s", address)
+	}
+
+	return parsed.String(), nil
+}
+
+// insertSnapshot appends snapshot with next id, s.mu must be held
+func (s *Storage) insertSnapshot(snapshot models.Snapshot) models.Snapshot {
+	s.lastSnapshotID++
+	snapshot.ID = s.lastSnapshotID
+	snapshot.Timestamp = snapshot.Timestamp.UTC()
+
+	s.snapshots = append(s.snapshots, snapshot)
+
+	return snapshot
+}
+
+// insertWallet inserts wallet with next id and its initial snapshot taken at created,
+// address must be canonical and unique, s.mu must be held
+func (s *Storage) insertWallet(wallet models.Wallet, created time.Time) models.Wallet {
+	s.lastWalletID++
+	wallet.ID = s.lastWalletID
+	if wallet.Balance.Currency() == (money.Currency{}) {
+		// zero money is never negative
+		wallet.Balance, _ = models.NewBalanceFromMoney(money.Zero(s.currency))
+	}
+
+	s.wallets[wallet.ID] = wallet
+	s.walletsByAddress[wallet.Address] = wallet.ID
+
+	s.insertSnapshot(models.Snapshot{
+		Address:   wallet.Address,
+		Balance:   wallet.Balance,
+		Timestamp: created,
+	})
+
+	return wallet
+}
+
+// walletByAddress returns wallet by canonical address, s.mu must be held
+func (s *Storage) walletByAddress(address string) (models.Wallet, bool) {
+	id, ok := s.walletsByAddress[address]
+	if !ok {
+		return models.Wallet{}, false
+	}
+
+	return s.wallets[id], true
+}
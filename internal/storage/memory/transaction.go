@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+type TransactionStorage struct {
+	*Storage
+}
+
+var _ storageLayer.TransactionStorage = TransactionStorage{}
+
+func (ts TransactionStorage) GetByID(ctx context.Context, id int) (models.Transaction, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	// ids are sequential and transactions are never deleted
+	if id < 1 || id > len(ts.transactions) {
+		return models.Transaction{}, storageLayer.ErrNotFound.New("transaction not found, id = %d", id)
+	}
+
+	return ts.transactions[id-1], nil
+}
+
+func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) ([]models.Transaction, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	ts.mu.RLock()
+	successful := make([]models.Transaction, 0, min(limit, len(ts.transactions)))
+	for _, t := range ts.transactions {
+		if t.Successful {
+			successful = append(successful, t)
+		}
+	}
+	ts.mu.RUnlock()
+
+	// newest first, later inserted first on equal timestamps
+	slices.SortStableFunc(successful, func(a, b models.Transaction) int {
+		if c := b.Timestamp.Compare(a.Timestamp); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	return successful[:min(limit, len(successful))], nil
+}
+
+func (ts TransactionStorage) GetSuccessfulByAddress(
+	ctx context.Context,
+	address string,
+	after, until time.Time,
+) ([]models.Transaction, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.mu.RLock()
+	var transactions []models.Transaction
+	for _, t := range ts.transactions {
+		if !t.Successful || (t.FromAddress != address && t.ToAddress != address) {
+			continue
+		}
+		if t.Timestamp.After(after) && !t.Timestamp.After(until) {
+			transactions = append(transactions, t)
+		}
+	}
+	ts.mu.RUnlock()
+
+	slices.SortStableFunc(transactions, func(a, b models.Transaction) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return transactions, nil
+}
+
+// IterateSuccessfulByAddress yields copy of matched transactions,
+// so yield may call storage without deadlock
+func (ts TransactionStorage) IterateSuccessfulByAddress(
+	ctx context.Context,
+	address string,
+	after, until time.Time,
+	yield func(models.Transaction) error,
+) error {
+	transactions, err := ts.GetSuccessfulByAddress(ctx, address, after, until)
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range transactions {
+		if err := yield(transaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ts TransactionStorage) GetChainHead(ctx context.Context) (models.Transaction, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	if len(ts.transactions) == 0 {
+		return models.Transaction{}, storageLayer.ErrNotFound.New("transactions chain is empty")
+	}
+
+	return ts.transactions[len(ts.transactions)-1], nil
+}
+
+// IterateChain yields copy of transactions, so yield may call storage without deadlock
+func (ts TransactionStorage) IterateChain(ctx context.Context, yield func(models.Transaction) error) error {
+	ts.mu.RLock()
+	transactions := slices.Clone(ts.transactions)
+	ts.mu.RUnlock()
+
+	for _, transaction := range transactions {
+		if err := yield(transaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ts TransactionStorage) Insert(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	transaction, err := ts.referenceWallets(transaction)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return ts.insert(transaction), nil
+}
+
+func (ts TransactionStorage) Transfer(ctx context.Context, transaction models.Transaction) (models.Transaction, models.TransferBalances, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	transaction, err := ts.referenceWallets(transaction)
+	if err != nil {
+		return models.Transaction{}, models.TransferBalances{}, err
+	}
+
+	from, fromOk := ts.walletByAddress(transaction.FromAddress)
+	to, toOk := ts.walletByAddress(transaction.ToAddress)
+	if !fromOk || !toOk {
+		return models.Transaction{}, models.TransferBalances{}, storageLayer.ErrNotFound.New(
+			"from = %s, to = %s", transaction.FromAddress, transaction.ToAddress,
+		)
+	}
+
+	if from.Frozen || to.Frozen {
+		return models.Transaction{}, models.TransferBalances{}, storageLayer.ErrFrozen.New(
+			"from = %s, to = %s", transaction.FromAddress, transaction.ToAddress,
+		)
+	}
+
+	fromBalance, err := from.Balance.Sub(transaction.Amount)
+	if err != nil {
+		return models.Transaction{}, models.TransferBalances{}, storageLayer.BalanceErr(err, from.Address)
+	}
+	toBalance, err := to.Balance.Add(transaction.Amount)
+	if err != nil {
+		return models.Transaction{}, models.TransferBalances{}, storageLayer.BalanceErr(err, to.Address)
+	}
+
+	// both wallets are changed under the same lock, so transfer is atomic
+	from.Balance = fromBalance
+	ts.wallets[from.ID] = from
+
+	// transfer to the same wallet leaves its balance as is
+	if to.ID == from.ID {
+		toBalance = to.Balance
+	}
+	to.Balance = toBalance
+	ts.wallets[to.ID] = to
+
+	transaction.Successful = true
+
+	return ts.insert(transaction), models.TransferBalances{From: ts.wallets[from.ID].Balance, To: to.Balance}, nil
+}
+
+// referenceWallets normalizes transaction addresses and checks, that they
+// reference existing wallets like foreign keys do, ts.mu must be held
+func (ts TransactionStorage) referenceWallets(transaction models.Transaction) (models.Transaction, error) {
+	for _, address := range []*string{&transaction.FromAddress, &transaction.ToAddress} {
+		normalized, err := normalizeAddress(*address)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+		if _, ok := ts.walletsByAddress[normalized]; !ok {
+			return models.Transaction{}, storageLayer.ErrNotFound.New("referenced wallet not found, address = %s", normalized)
+		}
+		*address = normalized
+	}
+
+	return transaction, nil
+}
+
+// insert appends transaction with next id chained to
+// the last inserted one, ts.mu must be held
+func (ts TransactionStorage) insert(transaction models.Transaction) models.Transaction {
+	ts.lastTransactionID++
+	transaction.ID = ts.lastTransactionID
+	transaction.Timestamp = transaction.Timestamp.UTC()
+
+	transaction.PrevHash = nil
+	if n := len(ts.transactions); n > 0 {
+		transaction.PrevHash = ts.transactions[n-1].Hash
+	}
+	transaction.Hash = transaction.ChainHash(transaction.PrevHash)
+
+	ts.transactions = append(ts.transactions, transaction)
+
+	return transaction
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+type TreasuryStorage struct {
+	*Storage
+}
+
+var _ storageLayer.TreasuryStorage = TreasuryStorage{}
+
+func (ts TreasuryStorage) Apply(ctx context.Context, operation models.TreasuryOperation) (models.TreasuryOperation, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	treasury, ok := ts.walletByAddress(models.TreasuryAddress)
+	if !ok {
+		// initial snapshot shares timestamp with operation one to not shadow it in history
+		treasury = ts.insertWallet(models.Wallet{Address: models.TreasuryAddress}, operation.Timestamp)
+	}
+
+	var (
+		balance models.Balance
+		err     error
+	)
+	switch operation.Kind {
+	case models.TreasuryMint:
+		balance, err = treasury.Balance.Add(operation.Amount)
+	case models.TreasuryBurn:
+		balance, err = treasury.Balance.Sub(operation.Amount)
+	default:
+		return models.TreasuryOperation{}, storageLayer.ErrInvalid.New("unknown treasury operation %q", operation.Kind)
+	}
+	if err != nil {
+		return models.TreasuryOperation{}, storageLayer.BalanceErr(err, treasury.Address)
+	}
+	treasury.Balance = balance
+
+	ts.wallets[treasury.ID] = treasury
+	ts.insertSnapshot(models.Snapshot{
+		Address:   treasury.Address,
+		Balance:   treasury.Balance,
+		Timestamp: operation.Timestamp,
+	})
+
+	operation.ID = len(ts.treasuryOperations) + 1
+	operation.Balance = treasury.Balance
+	operation.Timestamp = operation.Timestamp.UTC()
+	ts.treasuryOperations = append(ts.treasuryOperations, operation)
+
+	return operation, nil
+}
+
+func (ts TreasuryStorage) List(ctx context.Context, afterID int, limit int) ([]models.TreasuryOperation, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	// ids are sequential and operations are never deleted
+	from := min(max(afterID, 0), len(ts.treasuryOperations))
+	to := min(from+limit, len(ts.treasuryOperations))
+
+	return slices.Clone(ts.treasuryOperations[from:to]), nil
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+type WalletStorage struct {
+	*Storage
+}
+
+var _ storageLayer.WalletStorage = WalletStorage{}
+
+func (ws WalletStorage) GetByID(ctx context.Context, id int) (models.Wallet, error) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	wallet, ok := ws.wallets[id]
+	if !ok {
+		return models.Wallet{}, storageLayer.ErrNotFound.New("wallet not found, id = %d", id)
+	}
+
+	return wallet, nil
+}
+
+func (ws WalletStorage) GetByAddress(ctx context.Context, address string) (models.Wallet, error) {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	wallet, ok := ws.walletByAddress(address)
+	if !ok {
+		return models.Wallet{}, storageLayer.ErrNotFound.New("address = %s", address)
+	}
+
+	return wallet, nil
+}
+
+func (ws WalletStorage) List(ctx context.Context, afterID int, limit int) ([]models.Wallet, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	// ids are sequential and wallets are never deleted
+	wallets := make([]models.Wallet, 0, min(limit, len(ws.wallets)))
+	for id := max(afterID, 0) + 1; id <= ws.lastWalletID && len(wallets) < limit; id++ {
+		wallets = append(wallets, ws.wallets[id])
+	}
+
+	return wallets, nil
+}
+
+func (ws WalletStorage) Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error) {
+	address, err := normalizeAddress(wallet.Address)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if _, ok := ws.walletsByAddress[address]; ok {
+		return models.Wallet{}, storageLayer.ErrUniqueViolation.New("address = %s", address)
+	}
+
+	wallet.Address = address
+
+	return ws.insertWallet(wallet, time.Now()), nil
+}
+
+func (ws WalletStorage) InsertIfMissing(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error) {
+	address, err := normalizeAddress(wallet.Address)
+	if err != nil {
+		return models.Wallet{}, false, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if stored, ok := ws.walletByAddress(address); ok {
+		return stored, false, nil
+	}
+
+	wallet.Address = address
+
+	return ws.insertWallet(wallet, time.Now()), true, nil
+}
+
+func (ws WalletStorage) Upsert(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error) {
+	address, err := normalizeAddress(wallet.Address)
+	if err != nil {
+		return models.Wallet{}, false, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	stored, ok := ws.walletByAddress(address)
+	if !ok {
+		wallet.Address = address
+		return ws.insertWallet(wallet, time.Now()), true, nil
+	}
+
+	if !stored.Balance.Equal(wallet.Balance) {
+		stored.Balance = wallet.Balance
+		ws.wallets[stored.ID] = stored
+		ws.insertSnapshot(models.Snapshot{
+			Address:   stored.Address,
+			Balance:   stored.Balance,
+			Timestamp: time.Now(),
+		})
+	}
+
+	return stored, false, nil
+}
+
+func (ws WalletStorage) UpdateBalance(ctx context.Context, changedWallet models.Wallet) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	wallet, ok := ws.wallets[changedWallet.ID]
+	if !ok {
+		return storageLayer.ErrNotFound.New("id = %d", changedWallet.ID)
+	}
+
+	wallet.Balance = changedWallet.Balance
+	ws.wallets[wallet.ID] = wallet
+
+	return nil
+}
+
+func (ws WalletStorage) SetFrozen(ctx context.Context, address string, frozen bool) error {
+	address, err := normalizeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	wallet, ok := ws.walletByAddress(address)
+	if !ok {
+		return storageLayer.ErrNotFound.New("address = %s", address)
+	}
+
+	wallet.Frozen = frozen
+	ws.wallets[wallet.ID] = wallet
+
+	return nil
+}
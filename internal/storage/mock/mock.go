@@ -1,3 +0,0 @@
-// Package mock contains mock storage
-// for transaction and wallet storage
-package mock
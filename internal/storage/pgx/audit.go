@@ -0,0 +1,124 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/dialect"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+)
+
+type AuditStorage struct {
+	*Storage
+}
+
+var _ storageLayer.AuditStorage = AuditStorage{}
+
+func (as AuditStorage) Insert(ctx context.Context, record models.AuditRecord) (models.AuditRecord, error) {
+	newDBRecord, err := pgxmodels.AuditRecordFromDomain(record)
+	if err != nil {
+		return models.AuditRecord{}, storageLayer.ErrFailedToMarshal.Wrap(err, "audit record = %v", record)
+	}
+
+	// access to pgxpool via embed Storage
+	if err := as.Do(ctx, func(conn *pgxpool.Conn) error {
+		// INSERT INTO newDBRecord.TableName() VALUES newDBRecord.ValuesWithoutID() RETURNING id
+		cte := psql.Insert(
+			im.Into(newDBRecord.TableName(), newDBRecord.FieldsWithoutID()...),
+			im.Values(psql.Arg(newDBRecord.ValuesWithoutID()...)),
+			im.Returning(psql.Quote("id")),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		if err := conn.QueryRow(ctx, stmt, args...).Scan(&newDBRecord.ID); err != nil {
+			return handleError(err, "error on insert audit record")
+		}
+
+		record, err = newDBRecord.ToDomain()
+		if err != nil {
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.AuditRecord = %v", newDBRecord)
+		}
+
+		return nil
+	}); err != nil {
+		return models.AuditRecord{}, err
+	}
+
+	return record, nil
+}
+
+func (as AuditStorage) List(ctx context.Context, filter models.AuditFilter, afterID int, limit int) ([]models.AuditRecord, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	records := make([]models.AuditRecord, 0, limit)
+
+	// access to pgxpool via embed Storage
+	if err := as.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbRecord pgxmodels.AuditRecord
+
+		// SELECT * FROM dbRecord.TableName() WHERE id > $1 AND <filter> ORDER BY id LIMIT $n
+		mods := []bob.Mod[*dialect.SelectQuery]{
+			sm.From(dbRecord.TableName()),
+			sm.Where(psql.Quote("id").GT(psql.Arg(afterID))),
+			sm.OrderBy(psql.Quote("id")),
+			sm.Limit(limit),
+		}
+		if filter.Actor != "" {
+			mods = append(mods, sm.Where(psql.Quote("actor").EQ(psql.Arg(filter.Actor))))
+		}
+		if filter.Wallet != "" {
+			mods = append(mods, sm.Where(psql.Or(
+				psql.Quote("wallet").EQ(psql.Arg(filter.Wallet)),
+				psql.Quote("counterparty").EQ(psql.Arg(filter.Wallet)),
+			)))
+		}
+		if !filter.From.IsZero() {
+			mods = append(mods, sm.Where(psql.Quote("timestamp").GTE(psql.Arg(timestampArg(filter.From)))))
+		}
+		if !filter.To.IsZero() {
+			mods = append(mods, sm.Where(psql.Quote("timestamp").LT(psql.Arg(timestampArg(filter.To)))))
+		}
+
+		stmt, args, err := psql.Select(mods...).Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "error on list audit records")
+		}
+
+		dbRecords, err := pgx.CollectRows(rows, pgx.RowToStructByName[pgxmodels.AuditRecord])
+		if err != nil {
+			return handleError(err, "error on list audit records")
+		}
+
+		for _, dbRecord := range dbRecords {
+			record, err := dbRecord.ToDomain()
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.AuditRecord = %v", dbRecord)
+			}
+			records = append(records, record)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,131 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+)
+
+type CheckpointStorage struct {
+	*Storage
+}
+
+var _ storageLayer.CheckpointStorage = CheckpointStorage{}
+
+func (cs CheckpointStorage) Insert(ctx context.Context, checkpoint models.Checkpoint) (models.Checkpoint, error) {
+	// access to pgxpool via embed Storage
+	if err := cs.Do(ctx, func(conn *pgxpool.Conn) error {
+		newDBCheckpoint := pgxmodels.CheckpointFromDomain(checkpoint)
+
+		// INSERT INTO newDBCheckpoint.TableName() VALUES newDBCheckpoint.ValuesWithoutID() RETURNING id
+		cte := psql.Insert(
+			im.Into(newDBCheckpoint.TableName(), newDBCheckpoint.FieldsWithoutID()...),
+			im.Values(psql.Arg(newDBCheckpoint.ValuesWithoutID()...)),
+			im.Returning(psql.Quote("id")),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		if err := conn.QueryRow(ctx, stmt, args...).Scan(&newDBCheckpoint.ID); err != nil {
+			return handleError(err, "transaction_id = %d", checkpoint.TransactionID)
+		}
+
+		checkpoint = newDBCheckpoint.ToDomain()
+
+		return nil
+	}); err != nil {
+		return models.Checkpoint{}, err
+	}
+
+	return checkpoint, nil
+}
+
+func (cs CheckpointStorage) GetLatest(ctx context.Context) (models.Checkpoint, error) {
+	var checkpoint models.Checkpoint
+
+	if err := cs.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbCheckpoint pgxmodels.Checkpoint
+
+		// SELECT * FROM dbCheckpoint.TableName() ORDER BY id DESC LIMIT 1
+		cte := psql.Select(
+			sm.From(dbCheckpoint.TableName()),
+			sm.OrderBy(psql.Quote("id")).Desc(),
+			sm.Limit(1),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "error on get latest checkpoint")
+		}
+
+		dbCheckpoint, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[pgxmodels.Checkpoint])
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storageLayer.ErrNotFound.New("no checkpoints")
+		}
+		if err != nil {
+			return handleError(err, "error on get latest checkpoint")
+		}
+
+		checkpoint = dbCheckpoint.ToDomain()
+
+		return nil
+	}); err != nil {
+		return models.Checkpoint{}, err
+	}
+
+	return checkpoint, nil
+}
+
+func (cs CheckpointStorage) GetAll(ctx context.Context) ([]models.Checkpoint, error) {
+	var checkpoints []models.Checkpoint
+
+	if err := cs.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbCheckpoint pgxmodels.Checkpoint
+
+		// SELECT * FROM dbCheckpoint.TableName() ORDER BY id
+		cte := psql.Select(
+			sm.From(dbCheckpoint.TableName()),
+			sm.OrderBy(psql.Quote("id")),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "error on get checkpoints")
+		}
+
+		dbCheckpoints, err := pgx.CollectRows(rows, pgx.RowToStructByName[pgxmodels.Checkpoint])
+		if err != nil {
+			return handleError(err, "error on get checkpoints")
+		}
+
+		checkpoints = make([]models.Checkpoint, 0, len(dbCheckpoints))
+		for _, dbCheckpoint := range dbCheckpoints {
+			checkpoints = append(checkpoints, dbCheckpoint.ToDomain())
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
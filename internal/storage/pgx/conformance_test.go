@@ -0,0 +1,22 @@
+package pgx_test
+
+import (
+	"testing"
+
+	"github.com/lunn06/wallet/internal/storage/pgx"
+	"github.com/lunn06/wallet/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	requireDatabase(t)
+	storagetest.Run(t, func(t *testing.T) storagetest.Storages {
+		return storagetest.Storages{
+			Wallet:      walletStorage,
+			Transaction: transactionStorage,
+			Snapshot:    pgx.SnapshotStorage{Storage: storage},
+			Checkpoint:  pgx.CheckpointStorage{Storage: storage},
+			Treasury:    pgx.TreasuryStorage{Storage: storage},
+			Audit:       pgx.AuditStorage{Storage: storage},
+		}
+	})
+}
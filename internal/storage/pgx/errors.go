@@ -1,6 +1,7 @@
 package pgx
 
 import (
+	"context"
 	"errors"
 
 	"github.com/jackc/pgx/v5/pgconn"
@@ -9,12 +10,18 @@ import (
 )
 
 const (
-	uniqueViolationCode = "23505"
-	invalidSyntax       = "22P02"
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+	invalidSyntax           = "22P02"
 )
 
 // handleError handle pgconn.PgError and wrap it in storageLayer errors
 func handleError(err error, message string, args ...any) error {
+	// cancelled or timed out context means that caller stopped waiting
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return storageLayer.ErrUnavailable.Wrap(err, message, args...)
+	}
+
 	var pgxErr *pgconn.PgError
 	if !errors.As(err, &pgxErr) {
 		return storageLayer.UnhandledErr.WrapWithNoMessage(err)
@@ -23,6 +30,8 @@ func handleError(err error, message string, args ...any) error {
 	switch pgxErr.Code {
 	case uniqueViolationCode:
 		return storageLayer.ErrUniqueViolation.New("constraint = %s", pgxErr.ConstraintName)
+	case foreignKeyViolationCode:
+		return storageLayer.ErrNotFound.New("referenced row not found, constraint = %s", pgxErr.ConstraintName)
 	case invalidSyntax:
 		return storageLayer.ErrInvalid.New("constraint = %s", pgxErr.ConstraintName)
 	default:
@@ -0,0 +1,104 @@
+package pgx
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "wallet"
+
+var (
+	poolAcquiredConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "acquired_conns"),
+		"Count of currently acquired connections in the pool",
+		nil, nil,
+	)
+	poolIdleConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "idle_conns"),
+		"Count of currently idle connections in the pool",
+		nil, nil,
+	)
+	poolTotalConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "total_conns"),
+		"Total count of connections in the pool",
+		nil, nil,
+	)
+	poolMaxConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "max_conns"),
+		"Max size of the pool",
+		nil, nil,
+	)
+	poolAcquireCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "acquire_total"),
+		"Count of successful acquires from the pool",
+		nil, nil,
+	)
+	poolAcquireDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "acquire_duration_seconds_total"),
+		"Total duration of all successful acquires from the pool",
+		nil, nil,
+	)
+	poolEmptyAcquireCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "empty_acquire_total"),
+		"Count of acquires that waited for a connection because the pool was empty",
+		nil, nil,
+	)
+	replicaHealthyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_replica", "healthy"),
+		"Whether read-only replica is healthy and receives reads",
+		[]string{"replica"}, nil,
+	)
+	poolCanceledAcquireCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "pgx_pool", "canceled_acquire_total"),
+		"Count of acquires that were canceled by a context",
+		nil, nil,
+	)
+)
+
+func newAcquireWaitHistogram() prometheus.Histogram {
+	return prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "pgx_pool",
+		Name:      "acquire_wait_seconds",
+		Help:      "Time spent in Storage.Do waiting for a connection from the pool",
+		Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+	})
+}
+
+var _ prometheus.Collector = &Storage{}
+
+// Describe implements prometheus.Collector interface
+func (s *Storage) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredConnsDesc
+	ch <- poolIdleConnsDesc
+	ch <- poolTotalConnsDesc
+	ch <- poolMaxConnsDesc
+	ch <- poolAcquireCountDesc
+	ch <- poolAcquireDurationDesc
+	ch <- poolEmptyAcquireCountDesc
+	ch <- poolCanceledAcquireCountDesc
+	ch <- replicaHealthyDesc
+	s.acquireWait.Describe(ch)
+}
+
+// Collect implements prometheus.Collector interface
+// and reports pgxpool.Stat and connection wait time
+func (s *Storage) Collect(ch chan<- prometheus.Metric) {
+	stat := s.Stat()
+
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConnsDesc, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConnsDesc, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalConnsDesc, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConnsDesc, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireCountDesc, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquireCountDesc, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquireCountDesc, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	for _, r := range s.replicas {
+		healthy := 0.
+		if r.healthy.Load() {
+			healthy = 1
+		}
+		ch <- prometheus.MustNewConstMetric(replicaHealthyDesc, prometheus.GaugeValue, healthy, r.name)
+	}
+	s.acquireWait.Collect(ch)
+}
@@ -0,0 +1,171 @@
+package pgx
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+const (
+	createMigrationsTableQuery = `
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    INT PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT now()
+		)
+	`
+	selectMigrationsQuery = "SELECT version FROM schema_migrations"
+	insertMigrationQuery  = "INSERT INTO schema_migrations (version) VALUES ($1)"
+
+	// migrationsLockID is key of advisory lock, that prevents
+	// concurrent migrations from several app instances
+	migrationsLockID = 7_000_001
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	query   string
+}
+
+// loadMigrations reads embedded migrations, named as <version>_<name>.sql
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		versionStr, name, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name %q", entry.Name())
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+
+		query, err := migrationsFS.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{version: version, name: name, query: string(query)})
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int { return a.version - b.version })
+
+	return migrations, nil
+}
+
+// Migrate applies pending embedded migrations, each in its own transaction
+func (s *Storage) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return storageLayer.UnhandledErr.Wrap(err, "failed to load migrations")
+	}
+
+	return s.Do(ctx, func(conn *pgxpool.Conn) error {
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationsLockID); err != nil {
+			return handleError(err, "failed to lock migrations")
+		}
+		defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockID)
+
+		if _, err := conn.Exec(ctx, createMigrationsTableQuery); err != nil {
+			return handleError(err, "failed to create migrations table")
+		}
+
+		applied, err := appliedMigrations(ctx, conn.Conn())
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if slices.Contains(applied, m.version) {
+				continue
+			}
+
+			if err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.query); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, insertMigrationQuery, m.version)
+				return err
+			}); err != nil {
+				return handleError(err, "failed to apply migration %d_%s", m.version, m.name)
+			}
+
+			s.logger.Info("Migration applied", "version", m.version, "name", m.name)
+		}
+
+		return nil
+	})
+}
+
+// PendingMigrations returns versions of embedded migrations,
+// that are not applied to database yet
+func (s *Storage) PendingMigrations(ctx context.Context) ([]int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, storageLayer.UnhandledErr.Wrap(err, "failed to load migrations")
+	}
+
+	var pending []int
+	if err := s.Do(ctx, func(conn *pgxpool.Conn) error {
+		var exists bool
+		if err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+			return handleError(err, "failed to check migrations table")
+		}
+
+		var (
+			applied []int
+			err     error
+		)
+		if exists {
+			applied, err = appliedMigrations(ctx, conn.Conn())
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, m := range migrations {
+			if !slices.Contains(applied, m.version) {
+				pending = append(pending, m.version)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func appliedMigrations(ctx context.Context, conn *pgx.Conn) ([]int, error) {
+	rows, err := conn.Query(ctx, selectMigrationsQuery)
+	if err != nil {
+		return nil, handleError(err, "failed to select migrations")
+	}
+
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, handleError(err, "failed to select migrations")
+	}
+
+	return applied, nil
+}
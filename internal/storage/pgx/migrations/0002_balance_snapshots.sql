@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS balance_snapshots
+(
+    id        SERIAL PRIMARY KEY,
+    address   UUID      NOT NULL REFERENCES wallets (address),
+    balance   NUMERIC   NOT NULL,
+    timestamp TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS balance_snapshots_address_timestamp_idx
+    ON balance_snapshots (address, timestamp DESC);
+
+CREATE INDEX IF NOT EXISTS transactions_from_address_timestamp_idx
+    ON transactions (from_address, timestamp);
+
+CREATE INDEX IF NOT EXISTS transactions_to_address_timestamp_idx
+    ON transactions (to_address, timestamp);
+
+-- existing wallets get initial snapshot of their current balance
+INSERT INTO balance_snapshots (address, balance, timestamp)
+SELECT address, balance, now() AT TIME ZONE 'UTC'
+FROM wallets;
@@ -0,0 +1,15 @@
+-- hash chain over transactions, rows inserted before
+-- this migration stay unchained with NULL hashes
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS prev_hash BYTEA,
+    ADD COLUMN IF NOT EXISTS hash      BYTEA;
+
+CREATE TABLE IF NOT EXISTS chain_checkpoints
+(
+    id             SERIAL PRIMARY KEY,
+    transaction_id INTEGER   NOT NULL REFERENCES transactions (id),
+    hash           BYTEA     NOT NULL,
+    timestamp      TIMESTAMP NOT NULL,
+    -- ed25519 signature of models.Checkpoint.SignedData()
+    signature      BYTEA     NOT NULL
+);
@@ -0,0 +1,16 @@
+-- frozen wallets can neither send nor receive transfers
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- funds issued into and retired from treasury wallet by operators
+CREATE TABLE IF NOT EXISTS treasury_operations
+(
+    id        SERIAL PRIMARY KEY,
+    -- mint or burn
+    kind      TEXT      NOT NULL,
+    amount    NUMERIC   NOT NULL,
+    reason    TEXT      NOT NULL,
+    -- treasury balance after operation
+    balance   NUMERIC   NOT NULL,
+    timestamp TIMESTAMP NOT NULL
+);
@@ -0,0 +1,41 @@
+-- append-only records of state-changing actions
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id           SERIAL PRIMARY KEY,
+    timestamp    TIMESTAMP NOT NULL,
+    actor        TEXT      NOT NULL,
+    source_ip    TEXT      NOT NULL,
+    request_id   TEXT      NOT NULL,
+    action       TEXT      NOT NULL,
+    -- addresses aren't UUID, as records of failed actions keep malformed ones
+    wallet       TEXT      NOT NULL,
+    counterparty TEXT      NOT NULL,
+    state_before JSONB,
+    state_after  JSONB,
+    -- success or failure
+    outcome      TEXT      NOT NULL,
+    error        TEXT      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor, id);
+CREATE INDEX IF NOT EXISTS audit_log_wallet_idx ON audit_log (wallet, id);
+CREATE INDEX IF NOT EXISTS audit_log_counterparty_idx ON audit_log (counterparty, id);
+CREATE INDEX IF NOT EXISTS audit_log_timestamp_idx ON audit_log (timestamp);
+
+-- records can't be changed or deleted even by app itself
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
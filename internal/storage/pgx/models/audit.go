@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+type AuditRecord struct {
+	ID           int              `db:"id"`
+	Timestamp    pgtype.Timestamp `db:"timestamp"`
+	Actor        string           `db:"actor"`
+	SourceIP     string           `db:"source_ip"`
+	RequestID    string           `db:"request_id"`
+	Action       string           `db:"action"`
+	Wallet       string           `db:"wallet"`
+	Counterparty string           `db:"counterparty"`
+	Before       []byte           `db:"state_before"` // json object, nil is NULL
+	After        []byte           `db:"state_after"`
+	Outcome      string           `db:"outcome"`
+	Error        string           `db:"error"`
+}
+
+func (r AuditRecord) TableName() string {
+	return "audit_log"
+}
+
+func (r AuditRecord) Fields() []string {
+	return []string{
+		"id", "timestamp", "actor", "source_ip", "request_id", "action",
+		"wallet", "counterparty", "state_before", "state_after", "outcome", "error",
+	}
+}
+
+func (r AuditRecord) FieldsWithoutID() []string {
+	return r.Fields()[1:]
+}
+
+func (r AuditRecord) Values() []any {
+	return []any{
+		r.ID, r.Timestamp, r.Actor, r.SourceIP, r.RequestID, r.Action,
+		r.Wallet, r.Counterparty, r.Before, r.After, r.Outcome, r.Error,
+	}
+}
+
+func (r AuditRecord) ValuesWithoutID() []any {
+	return r.Values()[1:]
+}
+
+func (r AuditRecord) ToDomain() (models.AuditRecord, error) {
+	before, err := auditStateFromJSON(r.Before)
+	if err != nil {
+		return models.AuditRecord{}, err
+	}
+	after, err := auditStateFromJSON(r.After)
+	if err != nil {
+		return models.AuditRecord{}, err
+	}
+
+	return models.AuditRecord{
+		ID:           r.ID,
+		Timestamp:    r.Timestamp.Time,
+		Actor:        r.Actor,
+		SourceIP:     r.SourceIP,
+		RequestID:    r.RequestID,
+		Action:       r.Action,
+		Wallet:       r.Wallet,
+		Counterparty: r.Counterparty,
+		Before:       before,
+		After:        after,
+		Outcome:      r.Outcome,
+		Error:        r.Error,
+	}, nil
+}
+
+func AuditRecordFromDomain(domain models.AuditRecord) (AuditRecord, error) {
+	before, err := auditStateToJSON(domain.Before)
+	if err != nil {
+		return AuditRecord{}, err
+	}
+	after, err := auditStateToJSON(domain.After)
+	if err != nil {
+		return AuditRecord{}, err
+	}
+
+	return AuditRecord{
+		ID: domain.ID,
+		Timestamp: pgtype.Timestamp{
+			Time:             domain.Timestamp.UTC(),
+			InfinityModifier: pgtype.Finite,
+			Valid:            true,
+		},
+		Actor:        domain.Actor,
+		SourceIP:     domain.SourceIP,
+		RequestID:    domain.RequestID,
+		Action:       domain.Action,
+		Wallet:       domain.Wallet,
+		Counterparty: domain.Counterparty,
+		Before:       before,
+		After:        after,
+		Outcome:      domain.Outcome,
+		Error:        domain.Error,
+	}, nil
+}
+
+// auditStateToJSON encodes state, empty state is stored as NULL
+func auditStateToJSON(state models.AuditState) ([]byte, error) {
+	if len(state) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
+
+func auditStateFromJSON(raw []byte) (models.AuditState, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var state models.AuditState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -4,14 +4,15 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 type Balance struct {
 	decimal.Decimal
 }
 
-func (b Balance) ToDomain() (models.Balance, error) {
-	return models.NewBalanceFromDecimal(b.Decimal)
+func (b Balance) ToDomain(currency money.Currency) (models.Balance, error) {
+	return models.NewBalanceFromDecimal(b.Decimal, currency)
 }
 
 func BalanceFromDomain(b models.Balance) Balance {
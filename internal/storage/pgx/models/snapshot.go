@@ -0,0 +1,66 @@
+package models
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type Snapshot struct {
+	ID        int              `db:"id"`
+	Address   pgtype.UUID      `db:"address"`
+	Balance   Balance          `db:"balance"`
+	Timestamp pgtype.Timestamp `db:"timestamp"`
+}
+
+func (s Snapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+func (s Snapshot) Fields() []string {
+	return []string{"id", "address", "balance", "timestamp"}
+}
+
+func (s Snapshot) FieldsWithoutID() []string {
+	return s.Fields()[1:]
+}
+
+func (s Snapshot) Values() []any {
+	return []any{s.ID, s.Address, s.Balance, s.Timestamp}
+}
+
+func (s Snapshot) ValuesWithoutID() []any {
+	return s.Values()[1:]
+}
+
+func (s Snapshot) ToDomain(currency money.Currency) (models.Snapshot, error) {
+	balance, err := s.Balance.ToDomain(currency)
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	return models.Snapshot{
+		ID:        s.ID,
+		Address:   s.Address.String(),
+		Balance:   balance,
+		Timestamp: s.Timestamp.Time,
+	}, nil
+}
+
+func SnapshotFromDomain(domain models.Snapshot) (Snapshot, error) {
+	var dbUUID pgtype.UUID
+	if err := dbUUID.Scan(domain.Address); err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{
+		ID:      domain.ID,
+		Address: dbUUID,
+		Balance: BalanceFromDomain(domain.Balance),
+		Timestamp: pgtype.Timestamp{
+			Time:             domain.Timestamp.UTC(),
+			InfinityModifier: pgtype.Finite,
+			Valid:            true,
+		},
+	}, nil
+}
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 type Transaction struct {
@@ -13,6 +16,8 @@ type Transaction struct {
 	Amount      Balance          `db:"amount"`
 	Timestamp   pgtype.Timestamp `db:"timestamp"`
 	Successful  bool             `db:"successful"`
+	PrevHash    []byte           `db:"prev_hash"`
+	Hash        []byte           `db:"hash"`
 }
 
 func (t Transaction) TableName() string {
@@ -20,7 +25,7 @@ func (t Transaction) TableName() string {
 }
 
 func (t Transaction) Fields() []string {
-	return []string{"id", "from_address", "to_address", "amount", "timestamp", "successful"}
+	return []string{"id", "from_address", "to_address", "amount", "timestamp", "successful", "prev_hash", "hash"}
 }
 
 func (t Transaction) FieldsWithoutID() []string {
@@ -28,15 +33,15 @@ func (t Transaction) FieldsWithoutID() []string {
 }
 
 func (t Transaction) Values() []any {
-	return []any{t.ID, t.FromAddress, t.ToAddress, t.Amount, t.Timestamp, t.Successful}
+	return []any{t.ID, t.FromAddress, t.ToAddress, t.Amount, t.Timestamp, t.Successful, t.PrevHash, t.Hash}
 }
 
 func (t Transaction) ValuesWithoutID() []any {
 	return t.Values()[1:]
 }
 
-func (t Transaction) ToDomain() (models.Transaction, error) {
-	amount, err := t.Amount.ToDomain()
+func (t Transaction) ToDomain(currency money.Currency) (models.Transaction, error) {
+	amount, err := t.Amount.ToDomain(currency)
 	if err != nil {
 		return models.Transaction{}, err
 	}
@@ -47,6 +52,8 @@ func (t Transaction) ToDomain() (models.Transaction, error) {
 		Amount:      amount,
 		Timestamp:   t.Timestamp.Time,
 		Successful:  t.Successful,
+		PrevHash:    t.PrevHash,
+		Hash:        t.Hash,
 	}, nil
 }
 
@@ -61,8 +68,9 @@ func TransactionFromDomain(domain models.Transaction) (Transaction, error) {
 		return Transaction{}, err
 	}
 
+	// postgres keeps microseconds, so stored value is the hashed one
 	dbTimestamp := pgtype.Timestamp{
-		Time:             domain.Timestamp,
+		Time:             domain.Timestamp.UTC().Truncate(time.Microsecond),
 		InfinityModifier: pgtype.Finite,
 		Valid:            true,
 	}
@@ -74,5 +82,7 @@ func TransactionFromDomain(domain models.Transaction) (Transaction, error) {
 		Amount:      BalanceFromDomain(domain.Amount),
 		Timestamp:   dbTimestamp,
 		Successful:  domain.Successful,
+		PrevHash:    domain.PrevHash,
+		Hash:        domain.Hash,
 	}, nil
 }
@@ -5,12 +5,14 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 type Wallet struct {
 	ID      int         `db:"id"`
 	Address pgtype.UUID `db:"address"`
 	Balance Balance     `db:"balance"`
+	Frozen  bool        `db:"frozen"`
 }
 
 func (w Wallet) TableName() string {
@@ -18,7 +20,7 @@ func (w Wallet) TableName() string {
 }
 
 func (w Wallet) Fields() []string {
-	return []string{"id", "address", "balance"}
+	return []string{"id", "address", "balance", "frozen"}
 }
 
 func (w Wallet) FieldsWithoutID() []string {
@@ -26,20 +28,20 @@ func (w Wallet) FieldsWithoutID() []string {
 }
 
 func (w Wallet) Values() []any {
-	return []any{w.ID, w.Address, w.Balance}
+	return []any{w.ID, w.Address, w.Balance, w.Frozen}
 }
 
 func (w Wallet) ValuesWithoutID() []any {
 	return w.Values()[1:]
 }
 
-func (w Wallet) ToDomain() (models.Wallet, error) {
+func (w Wallet) ToDomain(currency money.Currency) (models.Wallet, error) {
 	pguuid, err := w.Address.UUIDValue()
 	if err != nil {
 		return models.Wallet{}, err
 	}
 
-	balance, err := models.NewBalanceFromDecimal(w.Balance.Decimal)
+	balance, err := models.NewBalanceFromDecimal(w.Balance.Decimal, currency)
 	if err != nil {
 		return models.Wallet{}, err
 	}
@@ -49,6 +51,7 @@ func (w Wallet) ToDomain() (models.Wallet, error) {
 		ID:      w.ID,
 		Address: walletAddress.String(),
 		Balance: balance,
+		Frozen:  w.Frozen,
 	}, nil
 }
 
@@ -62,5 +65,6 @@ func WalletFromDomain(domain models.Wallet) (Wallet, error) {
 		ID:      domain.ID,
 		Address: dbUUID,
 		Balance: BalanceFromDomain(domain.Balance),
+		Frozen:  domain.Frozen,
 	}, nil
 }
@@ -2,30 +2,59 @@ package pgx_test
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"os"
 	"strconv"
 	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit"
+	pgxv5 "github.com/jackc/pgx/v5"
 
 	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/domain/models"
 	"github.com/lunn06/wallet/internal/storage/pgx"
+	"github.com/lunn06/wallet/internal/storage/storagetest"
 	"github.com/lunn06/wallet/internal/utils/pgsql"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 var (
 	storage            *pgx.Storage
 	walletStorage      pgx.WalletStorage
 	transactionStorage pgx.TransactionStorage
+	// dns is dns of throwaway schema
+	dns string
+	// unavailable is reason, why live database can't be used
+	unavailable string
 )
 
-// TestMain define a startup and shutdown resources for tests
+// requireDatabase skips test without live database.
+// In CI (with CI variable set) it fails test instead
+func requireDatabase(tb testing.TB) {
+	tb.Helper()
+
+	if unavailable == "" {
+		return
+	}
+	if os.Getenv("CI") != "" {
+		tb.Fatal(unavailable)
+	}
+	tb.Skip(unavailable)
+}
+
+// TestMain define a startup and shutdown resources for tests.
+// Tests need live database from configs/main.yaml and are skipped without it.
+// They run in throwaway schema, which is dropped afterwards, so data of database is kept intact
 func TestMain(m *testing.M) {
 	cfg, err := config.ReadConfig("../../../configs/main.yaml")
 	if err != nil {
-		panic(err)
+		unavailable = fmt.Sprintf("live database is required, can't read config: %v", err)
+		os.Exit(m.Run())
 	}
 
-	logger := slog.Default()
-	dns := pgsql.BuildDns(
+	baseDNS := pgsql.BuildDns(
 		cfg.Database.Host,
 		strconv.Itoa(int(cfg.Database.Port)),
 		cfg.Database.SSLMode,
@@ -33,17 +62,65 @@ func TestMain(m *testing.M) {
 		cfg.Database.Password,
 		cfg.Database.Name,
 	)
-	storage, err = pgx.NewStorage(dns, logger)
+
+	ctx := context.Background()
+	conn, err := pgxv5.Connect(ctx, baseDNS)
 	if err != nil {
+		unavailable = fmt.Sprintf("live database is required, it is unreachable: %v", err)
+		os.Exit(m.Run())
+	}
+
+	// name has no characters to quote, so it's used as is in statements and search_path
+	schema := fmt.Sprintf("wallet_test_%d", time.Now().UnixNano())
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		panic(err)
+	}
+
+	dns = baseDNS + " search_path=" + schema
+	code := run(m, cfg.Database.Pool)
+
+	if _, err := conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+		panic(err)
+	}
+	if err := conn.Close(ctx); err != nil {
 		panic(err)
 	}
 
-	walletStorage = pgx.WalletStorage{storage}
-	transactionStorage = pgx.TransactionStorage{storage}
+	os.Exit(code)
+}
 
-	m.Run() // run all tests
+// run runs tests with storage of database dns
+func run(m *testing.M, poolCfg config.Pool) int {
+	var err error
+	// replicas don't follow throwaway schema at once, so tests don't use them
+	storage, err = pgx.NewStorage(dns, pgx.PoolConfig{
+		MaxConns:        poolCfg.MaxConns,
+		MinConns:        poolCfg.MinConns,
+		MaxConnLifetime: poolCfg.MaxConnLifetime,
+		MaxConnIdleTime: poolCfg.MaxConnIdleTime,
+		AcquireTimeout:  poolCfg.AcquireTimeout,
+	}, pgx.ReplicaConfig{}, storagetest.Currency, slog.Default())
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := storage.Close(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
 
-	if err := storage.Close(context.Background()); err != nil {
+	if err := storage.Migrate(context.Background()); err != nil {
 		panic(err)
 	}
+
+	walletStorage = pgx.WalletStorage{Storage: storage}
+	transactionStorage = pgx.TransactionStorage{Storage: storage}
+
+	return m.Run()
+}
+
+// randomBalance returns random balance, that fits scale of test currency
+func randomBalance() models.Balance {
+	balance, _ := models.NewBalanceFromMoney(money.FromMinor(int64(gofakeit.Number(0, 100_000_000)), storagetest.Currency))
+	return balance
 }
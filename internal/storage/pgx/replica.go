@@ -0,0 +1,125 @@
+package pgx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+const (
+	defaultReplicaCheckInterval = 5 * time.Second
+	replicaPingTimeout          = 2 * time.Second
+)
+
+// ReplicaConfig describes read-only streaming replicas of primary database
+type ReplicaConfig struct {
+	DSNs          []string
+	CheckInterval time.Duration
+}
+
+// replica is pool to read-only database, which
+// is excluded from reads while it's unhealthy
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+func newReplica(name string, pool *pgxpool.Pool) *replica {
+	r := &replica{name: name, pool: pool}
+	// replica is considered healthy until first failed check
+	r.healthy.Store(true)
+
+	return r
+}
+
+// DoRead is Do for read-only f. It runs f on healthy replica chosen
+// in round-robin order and falls back to primary if there is no healthy
+// replica, replica connection fails or ctx requires primary
+// (see storageLayer.WithPrimary)
+func (s *Storage) DoRead(ctx context.Context, f func(conn *pgxpool.Conn) error) error {
+	r := s.pickReplica(ctx)
+	if r == nil {
+		return s.Do(ctx, f)
+	}
+
+	// failed acquire or broken connection means that replica is down,
+	// errors of f on alive connection are returned as is
+	conn, err := s.acquire(ctx, r.pool)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		s.markReplica(r, false)
+		return s.Do(ctx, f)
+	}
+
+	err = f(conn)
+	connBroken := conn.Conn().IsClosed()
+	conn.Release()
+
+	if err != nil && connBroken && ctx.Err() == nil {
+		s.markReplica(r, false)
+		return s.Do(ctx, f)
+	}
+
+	return err
+}
+
+func (s *Storage) pickReplica(ctx context.Context) *replica {
+	if len(s.replicas) == 0 || storageLayer.PrimaryRequired(ctx) {
+		return nil
+	}
+
+	start := s.nextReplica.Add(1)
+	for i := range uint64(len(s.replicas)) {
+		r := s.replicas[(start+i)%uint64(len(s.replicas))]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// checkReplicas pings replicas every interval until ctx is done
+func (s *Storage) checkReplicas(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultReplicaCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, r := range s.replicas {
+			pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+			err := r.pool.Ping(pingCtx)
+			cancel()
+
+			s.markReplica(r, err == nil)
+		}
+	}
+}
+
+func (s *Storage) markReplica(r *replica, healthy bool) {
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		s.logger.Info("Replica is healthy again", "replica", r.name)
+	} else {
+		s.logger.Warn("Replica is unhealthy, reads fall back to primary", "replica", r.name)
+	}
+}
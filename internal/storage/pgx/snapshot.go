@@ -0,0 +1,151 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/dialect"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+)
+
+// deleteSnapshotsQuery deletes snapshots taken before $1, for which
+// a newer snapshot of the same wallet taken before $1 exists
+const deleteSnapshotsQuery = `
+	DELETE FROM balance_snapshots s
+	WHERE s.timestamp < $1
+	  AND EXISTS (
+		SELECT 1 FROM balance_snapshots n
+		WHERE n.address = s.address
+		  AND n.timestamp > s.timestamp
+		  AND n.timestamp < $1
+	  )
+`
+
+type SnapshotStorage struct {
+	*Storage
+}
+
+var _ storageLayer.SnapshotStorage = SnapshotStorage{}
+
+func (ss SnapshotStorage) Insert(ctx context.Context, snapshot models.Snapshot) (models.Snapshot, error) {
+	// access to pgxpool via embed Storage
+	if err := ss.Do(ctx, func(conn *pgxpool.Conn) error {
+		newDBSnapshot, err := pgxmodels.SnapshotFromDomain(snapshot)
+		if err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "address = %s", snapshot.Address)
+		}
+
+		stmt, args, err := insertSnapshotQuery(newDBSnapshot).Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		if err := conn.QueryRow(ctx, stmt, args...).Scan(&newDBSnapshot.ID); err != nil {
+			return handleError(err, "error on insert snapshot")
+		}
+
+		snapshot, err = newDBSnapshot.ToDomain(ss.currency)
+		if err != nil {
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Snapshot = %v", newDBSnapshot)
+		}
+
+		return nil
+	}); err != nil {
+		return models.Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+func (ss SnapshotStorage) GetLatest(ctx context.Context, address string, moment time.Time) (models.Snapshot, error) {
+	var snapshot models.Snapshot
+
+	// snapshots are written by worker on primary and
+	// are compared with transactions, so replica isn't used
+	if err := ss.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbSnapshot pgxmodels.Snapshot
+
+		// SELECT * FROM dbSnapshot.TableName() WHERE address = $1 AND timestamp <= $2 ORDER BY timestamp DESC, id DESC LIMIT 1
+		cte := psql.Select(
+			sm.From(dbSnapshot.TableName()),
+			sm.Where(psql.Quote("address").EQ(psql.Arg(address))),
+			sm.Where(psql.Quote("timestamp").LTE(psql.Arg(timestampArg(moment)))),
+			sm.OrderBy(psql.Quote("timestamp")).Desc(),
+			sm.OrderBy(psql.Quote("id")).Desc(),
+			sm.Limit(1),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+		}
+
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "address = %s", address)
+		}
+
+		dbSnapshot, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[pgxmodels.Snapshot])
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storageLayer.ErrNotFound.New("snapshot not found, address = %s, moment = %s", address, moment)
+			}
+			return handleError(err, "address = %s", address)
+		}
+
+		snapshot, err = dbSnapshot.ToDomain(ss.currency)
+		if err != nil {
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Snapshot = %v", dbSnapshot)
+		}
+
+		return nil
+	}); err != nil {
+		return models.Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+func (ss SnapshotStorage) DeleteBefore(ctx context.Context, moment time.Time) (int, error) {
+	var deleted int
+
+	// access to pgxpool via embed Storage
+	if err := ss.Do(ctx, func(conn *pgxpool.Conn) error {
+		command, err := conn.Exec(ctx, deleteSnapshotsQuery, timestampArg(moment))
+		if err != nil {
+			return handleError(err, "moment = %s", moment)
+		}
+		deleted = int(command.RowsAffected())
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// insertSnapshotQuery builds
+// INSERT INTO newDBSnapshot.TableName() VALUES newDBSnapshot.ValuesWithoutID() RETURNING id
+func insertSnapshotQuery(newDBSnapshot pgxmodels.Snapshot) bob.BaseQuery[*dialect.InsertQuery] {
+	return psql.Insert(
+		im.Into(newDBSnapshot.TableName(), newDBSnapshot.FieldsWithoutID()...),
+		im.Values(psql.Arg(newDBSnapshot.ValuesWithoutID()...)),
+		im.Returning(psql.Quote("id")),
+	)
+}
+
+// timestampArg converts moment to TIMESTAMP column value, that stores UTC time
+func timestampArg(moment time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: moment.UTC(), InfinityModifier: pgtype.Finite, Valid: true}
+}
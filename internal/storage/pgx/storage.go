@@ -2,31 +2,95 @@ package pgx
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	pgxdecimal "github.com/jackc/pgx-shopspring-decimal"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 
-	"github.com/lunn06/wallet/pkg/semaphore"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/pkg/money"
 
 	_ "github.com/lib/pq"
 )
 
-var (
-	maxCons = runtime.NumCPU() - runtime.NumCPU()/4
-)
+// PoolConfig describes pgxpool.Pool settings. Zero values mean
+// pgxpool defaults (MaxConns is the greater of 4 and count of CPUs).
+// AcquireTimeout bounds time of waiting for free connection in Do
+type PoolConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	AcquireTimeout  time.Duration
+}
 
-// Storage is pgxpool.Pool wrapper (that need to embed in other storages)
-// access to which is regulated by semaphore.Semaphore
+// Storage is pgxpool.Pool wrapper (that need to embed in other storages).
+// Concurrent access is bounded by pool size. Stored amounts are in currency,
+// amounts with more digits than it allows are reported as ErrFailedToUnmarshal
 type Storage struct {
-	pool   *pgxpool.Pool
-	logger *slog.Logger
-	sem    *semaphore.Semaphore
+	currency       money.Currency
+	pool           *pgxpool.Pool
+	replicas       []*replica
+	nextReplica    atomic.Uint64
+	logger         *slog.Logger
+	acquireTimeout time.Duration
+	acquireWait    prometheus.Histogram
+	stopChecks     context.CancelFunc
+}
+
+// NewStorage creates pool to primary database from dns and pools
+// to read-only replicas, whose health is checked in background
+func NewStorage(dns string, poolCfg PoolConfig, replicaCfg ReplicaConfig, currency money.Currency, logger *slog.Logger) (*Storage, error) {
+	db, err := newPool(dns, poolCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make([]*replica, 0, len(replicaCfg.DSNs))
+	for i, replicaDNS := range replicaCfg.DSNs {
+		replicaDB, err := newPool(replicaDNS, poolCfg)
+		if err != nil {
+			db.Close()
+			for _, r := range replicas {
+				r.pool.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, newReplica(strconv.Itoa(i), replicaDB))
+	}
+
+	checksCtx, stopChecks := context.WithCancel(context.Background())
+	storage := &Storage{
+		currency:       currency,
+		pool:           db,
+		replicas:       replicas,
+		logger:         logger,
+		acquireTimeout: poolCfg.AcquireTimeout,
+		acquireWait:    newAcquireWaitHistogram(),
+		stopChecks:     stopChecks,
+	}
+
+	if len(replicas) > 0 {
+		go storage.checkReplicas(checksCtx, replicaCfg.CheckInterval)
+	}
+
+	stat := db.Stat()
+	logger.Info(
+		"PgxStorage created",
+		"max_conns", stat.MaxConns(),
+		"replicas", len(replicas),
+	)
+
+	return storage, nil
 }
 
-func NewStorage(dns string, logger *slog.Logger) (*Storage, error) {
+func newPool(dns string, poolCfg PoolConfig) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(dns)
 	if err != nil {
 		return nil, err
@@ -38,37 +102,96 @@ func NewStorage(dns string, logger *slog.Logger) (*Storage, error) {
 		return nil
 	}
 
-	cfg.MaxConns = int32(maxCons)
+	// create span per SQL statement
+	cfg.ConnConfig.Tracer = queryTracer{}
+
+	if poolCfg.MaxConns > 0 {
+		cfg.MaxConns = poolCfg.MaxConns
+	}
+	if poolCfg.MinConns > 0 {
+		cfg.MinConns = min(poolCfg.MinConns, cfg.MaxConns)
+	}
+	if poolCfg.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = poolCfg.MaxConnLifetime
+	}
+	if poolCfg.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = poolCfg.MaxConnIdleTime
+	}
+
+	return pgxpool.NewWithConfig(context.Background(), cfg)
+}
 
-	db, err := pgxpool.NewWithConfig(context.Background(), cfg)
+// Do method acquires connection from inner pgxpool.Pool and pass it to f.
+// Waiting for connection stops on ctx cancellation or after acquire timeout,
+// so f must use the same ctx for its queries
+func (s *Storage) Do(ctx context.Context, f func(conn *pgxpool.Conn) error) error {
+	return s.do(ctx, s.pool, f)
+}
+
+func (s *Storage) do(ctx context.Context, pool *pgxpool.Pool, f func(conn *pgxpool.Conn) error) error {
+	conn, err := s.acquire(ctx, pool)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer conn.Release()
 
-	sem := semaphore.New(maxCons)
-	logger.Info("PgxStorage created")
+	return f(conn)
+}
+
+func (s *Storage) acquire(ctx context.Context, pool *pgxpool.Pool) (*pgxpool.Conn, error) {
+	acquireCtx := ctx
+	if s.acquireTimeout > 0 {
+		var cancel context.CancelFunc
+		acquireCtx, cancel = context.WithTimeout(ctx, s.acquireTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	conn, err := pool.Acquire(acquireCtx)
+	s.acquireWait.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, handleError(err, "failed to acquire connection")
+	}
 
-	return &Storage{pool: db, logger: logger, sem: sem}, nil
+	return conn, nil
 }
 
-// Do method make access to inner pgxpool.Pool via semaphore
-func (s *Storage) Do(f func(db *pgxpool.Pool) error) error {
-	resultCh := make(chan error)
+// Stat returns statistics of inner pgxpool.Pool
+func (s *Storage) Stat() *pgxpool.Stat {
+	return s.pool.Stat()
+}
 
-	go func() {
-		s.sem.Acquire()
-		defer s.sem.Release()
+// Ping checks that database is reachable
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.Do(ctx, func(conn *pgxpool.Conn) error {
+		if err := conn.Ping(ctx); err != nil {
+			return handleError(err, "failed to ping database")
+		}
+		return nil
+	})
+}
 
-		result := f(s.pool)
-		resultCh <- result
-	}()
+// CheckMigrations returns error if some of embedded migrations are not applied
+func (s *Storage) CheckMigrations(ctx context.Context) error {
+	pending, err := s.PendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return storageLayer.ErrInvalid.New("pending migrations: %v", pending)
+	}
 
-	return <-resultCh
+	return nil
 }
 
 func (s *Storage) Close(ctx context.Context) error {
+	s.stopChecks()
+
 	done := make(chan struct{}, 1)
 	go func() {
+		for _, r := range s.replicas {
+			r.pool.Close()
+		}
 		s.pool.Close()
 		done <- struct{}{}
 	}()
@@ -0,0 +1,150 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/pkg/semaphore"
+)
+
+const benchQuery = "SELECT 1"
+
+func TestStorage_Do(t *testing.T) {
+	requireDatabase(t)
+	t.Run("cancelled context stops waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		called := false
+		err := storage.Do(ctx, func(conn *pgxpool.Conn) error {
+			called = true
+			return nil
+		})
+
+		assert.False(t, called)
+		assert.True(t, storageLayer.IsInternalErr(err))
+		assert.ErrorContains(t, err, storageLayer.ErrUnavailable.String())
+	})
+	t.Run("deadline while pool is exhausted", func(t *testing.T) {
+		// hold every connection of the pool
+		size := int(storage.Stat().MaxConns())
+		release := make(chan struct{})
+		held := make(chan struct{}, size)
+		for range size {
+			go storage.Do(context.Background(), func(conn *pgxpool.Conn) error {
+				held <- struct{}{}
+				<-release
+				return nil
+			})
+		}
+		for range size {
+			<-held
+		}
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := storage.Do(ctx, func(conn *pgxpool.Conn) error { return nil })
+
+		assert.ErrorContains(t, err, storageLayer.ErrUnavailable.String())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+// BenchmarkStorage_Do measures throughput of current Do,
+// bounded by pgxpool only
+func BenchmarkStorage_Do(b *testing.B) {
+	requireDatabase(b)
+	ctx := context.Background()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := storage.Do(ctx, func(conn *pgxpool.Conn) error {
+				_, err := conn.Exec(ctx, benchQuery)
+				return err
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// legacyStorage is previous Storage, that bounded access
+// to pool by semaphore of pool size instead of pgxpool itself
+type legacyStorage struct {
+	pool    *pgxpool.Pool
+	sem     *semaphore.Semaphore
+	semWait prometheus.Histogram
+}
+
+func newLegacyStorage(b *testing.B, size int32) *legacyStorage {
+	b.Helper()
+
+	cfg, err := pgxpool.ParseConfig(dns)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cfg.MaxConns = size
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+
+	return &legacyStorage{
+		pool: pool,
+		sem:  semaphore.New(int(size)),
+		semWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "legacy_semaphore_wait_duration_seconds",
+			Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+		}),
+	}
+}
+
+// Do is previous Storage.Do, which spawned goroutine per call
+func (s *legacyStorage) Do(f func(db *pgxpool.Pool) error) error {
+	resultCh := make(chan error)
+
+	go func() {
+		start := time.Now()
+		s.sem.Acquire()
+		defer s.sem.Release()
+		s.semWait.Observe(time.Since(start).Seconds())
+
+		result := f(s.pool)
+		resultCh <- result
+	}()
+
+	return <-resultCh
+}
+
+// BenchmarkStorage_LegacyDo measures throughput of previous Do
+// with pool of the same size as pool of current one
+func BenchmarkStorage_LegacyDo(b *testing.B) {
+	requireDatabase(b)
+	ctx := context.Background()
+	legacy := newLegacyStorage(b, storage.Stat().MaxConns())
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := legacy.Do(func(db *pgxpool.Pool) error {
+				_, err := db.Exec(ctx, benchQuery)
+				return err
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
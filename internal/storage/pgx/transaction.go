@@ -2,22 +2,32 @@ package pgx
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob"
 	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/dialect"
 	"github.com/stephenafamo/bob/dialect/psql/im"
 	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/stephenafamo/bob/dialect/psql/um"
 
 	"github.com/lunn06/wallet/internal/domain/models"
 	storageLayer "github.com/lunn06/wallet/internal/storage"
 	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 type TransactionStorage struct {
 	*Storage
 }
 
+var _ storageLayer.TransactionStorage = TransactionStorage{}
+
 func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) ([]models.Transaction, error) {
 	if limit < 1 {
 		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
@@ -25,15 +35,16 @@ func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) (
 
 	transactions := make([]models.Transaction, 0, limit)
 
-	// access to pgxpool via embed Storage
-	if err := ts.Do(func(db *pgxpool.Pool) error {
+	// read access to pgxpool via embed Storage
+	if err := ts.DoRead(ctx, func(conn *pgxpool.Conn) error {
 		var dbTransaction pgxmodels.Transaction
 
-		// SELECT * FROM dbTransaction.TableName() WHERE successful = true ORDER BY timestamp DESC LIMIT $1
+		// SELECT * FROM dbTransaction.TableName() WHERE successful = true ORDER BY timestamp DESC, id DESC LIMIT $1
 		cte := psql.Select(
 			sm.From(dbTransaction.TableName()),
 			sm.Where(psql.Quote("successful").EQ(psql.Arg(true))),
 			sm.OrderBy(psql.Quote("timestamp")).Desc(),
+			sm.OrderBy(psql.Quote("id")).Desc(),
 			sm.Limit(limit),
 		)
 		stmt, args, err := cte.Build(ctx)
@@ -41,7 +52,7 @@ func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) (
 			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
 
-		rows, err := db.Query(ctx, stmt, args...)
+		rows, err := conn.Query(ctx, stmt, args...)
 		if err != nil {
 			return handleError(err, "error on GetLastSuccessful transaction")
 		}
@@ -54,7 +65,7 @@ func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) (
 				return storageLayer.ErrFailedToMarshal.Wrap(err, "pgx.Transactions = %v", dbTransaction)
 			}
 
-			transaction, err := dbTransaction.ToDomain()
+			transaction, err := dbTransaction.ToDomain(ts.currency)
 			if err != nil {
 				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", dbTransaction)
 			}
@@ -72,11 +83,135 @@ func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) (
 	return transactions, nil
 }
 
+func (ts TransactionStorage) GetSuccessfulByAddress(
+	ctx context.Context,
+	address string,
+	after, until time.Time,
+) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+
+	stmt, args, err := successfulByAddressQuery(address, after, until).Build(ctx)
+	if err != nil {
+		return nil, storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	// compared with snapshots written on primary, so replica isn't used
+	if err := ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "address = %s", address)
+		}
+
+		dbTransactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[pgxmodels.Transaction])
+		if err != nil {
+			return handleError(err, "address = %s", address)
+		}
+
+		transactions = make([]models.Transaction, 0, len(dbTransactions))
+		for _, dbTransaction := range dbTransactions {
+			transaction, err := dbTransaction.ToDomain(ts.currency)
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", dbTransaction)
+			}
+			transactions = append(transactions, transaction)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// cursorFetchSize is count of rows fetched from cursor at once
+const cursorFetchSize = 500
+
+const declareTransactionsCursorQuery = "DECLARE transactions_cursor NO SCROLL CURSOR FOR "
+
+var fetchTransactionsCursorQuery = fmt.Sprintf("FETCH FORWARD %d FROM transactions_cursor", cursorFetchSize)
+
+// IterateSuccessfulByAddress reads transactions through server-side cursor
+// in read-only transaction, so only cursorFetchSize rows are held in memory
+func (ts TransactionStorage) IterateSuccessfulByAddress(
+	ctx context.Context,
+	address string,
+	after, until time.Time,
+	yield func(models.Transaction) error,
+) error {
+	stmt, args, err := successfulByAddressQuery(address, after, until).Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	return ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		return iterateCursor(ctx, conn, ts.currency, stmt, args, yield)
+	})
+}
+
+// iterateCursor passes transactions selected by stmt to yield, reading them
+// through server-side cursor in read-only transaction by cursorFetchSize rows
+func iterateCursor(ctx context.Context, conn *pgxpool.Conn, currency money.Currency, stmt string, args []any, yield func(models.Transaction) error) error {
+	return pgx.BeginTxFunc(ctx, conn, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, declareTransactionsCursorQuery+stmt, args...); err != nil {
+			return handleError(err, "error on declare cursor")
+		}
+
+		for {
+			rows, err := tx.Query(ctx, fetchTransactionsCursorQuery)
+			if err != nil {
+				return handleError(err, "error on fetch cursor")
+			}
+
+			dbTransactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[pgxmodels.Transaction])
+			if err != nil {
+				return handleError(err, "error on fetch cursor")
+			}
+
+			for _, dbTransaction := range dbTransactions {
+				transaction, err := dbTransaction.ToDomain(currency)
+				if err != nil {
+					return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", dbTransaction)
+				}
+				if err := yield(transaction); err != nil {
+					return err
+				}
+			}
+
+			if len(dbTransactions) < cursorFetchSize {
+				return nil
+			}
+		}
+	})
+}
+
+// successfulByAddressQuery selects successful transactions
+// from or to address with timestamp in (after, until]
+func successfulByAddressQuery(address string, after, until time.Time) bob.BaseQuery[*dialect.SelectQuery] {
+	var dbTransaction pgxmodels.Transaction
+
+	// SELECT * FROM dbTransaction.TableName()
+	// WHERE successful = true AND (from_address = $1 OR to_address = $1) AND timestamp > $2 AND timestamp <= $3
+	// ORDER BY timestamp, id
+	return psql.Select(
+		sm.From(dbTransaction.TableName()),
+		sm.Where(psql.Quote("successful").EQ(psql.Arg(true))),
+		sm.Where(psql.Or(
+			psql.Quote("from_address").EQ(psql.Arg(address)),
+			psql.Quote("to_address").EQ(psql.Arg(address)),
+		)),
+		sm.Where(psql.Quote("timestamp").GT(psql.Arg(timestampArg(after)))),
+		sm.Where(psql.Quote("timestamp").LTE(psql.Arg(timestampArg(until)))),
+		sm.OrderBy(psql.Quote("timestamp")),
+		sm.OrderBy(psql.Quote("id")),
+	)
+}
+
 func (ts TransactionStorage) GetByID(ctx context.Context, id int) (models.Transaction, error) {
 	var transaction models.Transaction
 
-	// access to pgxpool via embed Storage
-	if err := ts.Do(func(db *pgxpool.Pool) error {
+	// read access to pgxpool via embed Storage
+	if err := ts.DoRead(ctx, func(conn *pgxpool.Conn) error {
 		var dbTransaction pgxmodels.Transaction
 
 		// SELECT * FROM dbTransaction.TableName() WHERE id=$1 LIMIT 1
@@ -90,7 +225,7 @@ func (ts TransactionStorage) GetByID(ctx context.Context, id int) (models.Transa
 			return storageLayer.ErrFailedStmtBuild.Wrap(err, "id = %d", id)
 		}
 
-		rows, err := db.Query(ctx, stmt, args...)
+		rows, err := conn.Query(ctx, stmt, args...)
 		if err != nil {
 			return handleError(err, "id = %d", id)
 		}
@@ -110,7 +245,7 @@ func (ts TransactionStorage) GetByID(ctx context.Context, id int) (models.Transa
 			return storageLayer.ErrFailedToMarshal.Wrap(err, "pgx.Transaction = %v", dbTransaction)
 		}
 
-		transaction, err = dbTransaction.ToDomain()
+		transaction, err = dbTransaction.ToDomain(ts.currency)
 		if err != nil {
 			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", dbTransaction)
 		}
@@ -125,45 +260,172 @@ func (ts TransactionStorage) GetByID(ctx context.Context, id int) (models.Transa
 
 func (ts TransactionStorage) Insert(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
 	// access to pgxpool via embed Storage
-	if err := ts.Do(func(db *pgxpool.Pool) error {
+	if err := ts.Do(ctx, func(conn *pgxpool.Conn) error {
 		newDBTransaction, err := pgxmodels.TransactionFromDomain(transaction)
 		if err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "pgx.Transaction = %v", transaction)
+		}
+
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if err := lockChain(ctx, tx); err != nil {
+				return err
+			}
+
+			transaction, err = insertChained(ctx, tx, ts.currency, newDBTransaction)
 			return err
+		})
+	}); err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+func (ts TransactionStorage) Transfer(ctx context.Context, transaction models.Transaction) (models.Transaction, models.TransferBalances, error) {
+	var balances models.TransferBalances
+	// access to pgxpool via embed Storage
+	if err := ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		newDBTransaction, err := pgxmodels.TransactionFromDomain(transaction)
+		if err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "pgx.Transaction = %v", transaction)
 		}
+		newDBTransaction.Successful = true
+
+		var dbWallet pgxmodels.Wallet
 
-		// INSERT INTO newDBWTransaction.TableName() VALUES newDBTransaction.ValuesWithoutID() RETURNING id
-		cte := psql.Insert(
-			im.Into(newDBTransaction.TableName(), newDBTransaction.FieldsWithoutID()...),
-			im.Values(psql.Arg(newDBTransaction.ValuesWithoutID()...)),
-			im.Returning(psql.Quote("id")),
+		// SELECT frozen FROM dbWallet.TableName() WHERE address IN ($1, $2) ORDER BY address FOR NO KEY UPDATE
+		// wallets are locked in the same order by every transfer to avoid deadlocks. The lock doesn't
+		// conflict with key share locks of foreign keys, so transfers holding chain lock never wait for it
+		lockCte := psql.Select(
+			sm.Columns(psql.Quote("frozen")),
+			sm.From(dbWallet.TableName()),
+			sm.Where(psql.Quote("address").In(
+				psql.Arg(newDBTransaction.FromAddress),
+				psql.Arg(newDBTransaction.ToAddress),
+			)),
+			sm.OrderBy(psql.Quote("address")),
+			sm.ForNoKeyUpdate(),
 		)
-		stmt, args, err := cte.Build(ctx)
+		lockStmt, lockArgs, err := lockCte.Build(ctx)
 		if err != nil {
 			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
 
-		rows, err := db.Query(ctx, stmt, args...)
+		// UPDATE dbWallet.TableName() SET balance = balance - $1 WHERE address = $2 AND balance >= $1 RETURNING balance
+		debitCte := psql.Update(
+			um.Table(dbWallet.TableName()),
+			um.SetCol("balance").To(psql.Quote("balance").Minus(psql.Arg(newDBTransaction.Amount))),
+			um.Where(psql.Quote("address").EQ(psql.Arg(newDBTransaction.FromAddress))),
+			um.Where(psql.Quote("balance").GTE(psql.Arg(newDBTransaction.Amount))),
+			um.Returning(psql.Quote("balance")),
+		)
+		debitStmt, debitArgs, err := debitCte.Build(ctx)
 		if err != nil {
-			return handleError(err, "error on insert transaction")
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
-		defer rows.Close()
 
-		// if no rows it means that Transaction not found or err
-		if ok := rows.Next(); !ok {
-			if err := rows.Err(); err != nil {
-				return handleError(err, "error on insert transaction")
+		// UPDATE dbWallet.TableName() SET balance = balance + $1 WHERE address = $2 RETURNING balance
+		creditCte := psql.Update(
+			um.Table(dbWallet.TableName()),
+			um.SetCol("balance").To(psql.Quote("balance").OP("+", psql.Arg(newDBTransaction.Amount))),
+			um.Where(psql.Quote("address").EQ(psql.Arg(newDBTransaction.ToAddress))),
+			um.Returning(psql.Quote("balance")),
+		)
+		creditStmt, creditArgs, err := creditCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			rows, err := tx.Query(ctx, lockStmt, lockArgs...)
+			if err != nil {
+				return handleError(err, "error on lock wallets")
+			}
+			frozen, err := pgx.CollectRows(rows, pgx.RowTo[bool])
+			if err != nil {
+				return handleError(err, "error on lock wallets")
+			}
+			if len(frozen) < 2 {
+				return storageLayer.ErrNotFound.New(
+					"from = %s, to = %s", transaction.FromAddress, transaction.ToAddress,
+				)
+			}
+			if slices.Contains(frozen, true) {
+				return storageLayer.ErrFrozen.New(
+					"from = %s, to = %s", transaction.FromAddress, transaction.ToAddress,
+				)
+			}
+
+			var fromBalance, toBalance pgxmodels.Balance
+			err = tx.QueryRow(ctx, debitStmt, debitArgs...).Scan(&fromBalance)
+			// wallet is locked and exists, so no updated row means lack of balance
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storageLayer.ErrInsufficientFunds.New("address = %s", transaction.FromAddress)
+			}
+			if err != nil {
+				return handleError(err, "address = %s", transaction.FromAddress)
+			}
+
+			if err := tx.QueryRow(ctx, creditStmt, creditArgs...).Scan(&toBalance); err != nil {
+				return handleError(err, "address = %s", transaction.ToAddress)
+			}
+			if balances.From, err = fromBalance.ToDomain(ts.currency); err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "address = %s", transaction.FromAddress)
 			}
+			if balances.To, err = toBalance.ToDomain(ts.currency); err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "address = %s", transaction.ToAddress)
+			}
+
+			// chain is locked after balances are changed, so transfers of
+			// different wallets are serialized only by insert of transaction
+			if err := lockChain(ctx, tx); err != nil {
+				return err
+			}
+
+			transaction, err = insertChained(ctx, tx, ts.currency, newDBTransaction)
+			return err
+		})
+	}); err != nil {
+		return models.Transaction{}, models.TransferBalances{}, err
+	}
+
+	return transaction, balances, nil
+}
+
+func (ts TransactionStorage) GetChainHead(ctx context.Context) (models.Transaction, error) {
+	var transaction models.Transaction
+
+	// chain is extended on primary, so replica isn't used
+	if err := ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbTransaction pgxmodels.Transaction
+
+		// SELECT * FROM dbTransaction.TableName() ORDER BY id DESC LIMIT 1
+		cte := psql.Select(
+			sm.From(dbTransaction.TableName()),
+			sm.OrderBy(psql.Quote("id")).Desc(),
+			sm.Limit(1),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
 
-		// Marshall query output to pgxmodels.Transaction
-		err = rows.Scan(&newDBTransaction.ID)
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "error on get chain head")
+		}
+
+		dbTransaction, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[pgxmodels.Transaction])
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storageLayer.ErrNotFound.New("transactions chain is empty")
+		}
 		if err != nil {
-			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "error on insert transaction")
+			return handleError(err, "error on get chain head")
 		}
 
-		transaction, err = newDBTransaction.ToDomain()
+		transaction, err = dbTransaction.ToDomain(ts.currency)
 		if err != nil {
-			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", newDBTransaction)
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", dbTransaction)
 		}
 
 		return nil
@@ -173,3 +435,87 @@ func (ts TransactionStorage) Insert(ctx context.Context, transaction models.Tran
 
 	return transaction, nil
 }
+
+// IterateChain reads transactions through server-side cursor
+// in read-only transaction, so only cursorFetchSize rows are held in memory
+func (ts TransactionStorage) IterateChain(ctx context.Context, yield func(models.Transaction) error) error {
+	var dbTransaction pgxmodels.Transaction
+
+	// SELECT * FROM dbTransaction.TableName() ORDER BY id
+	stmt, args, err := psql.Select(
+		sm.From(dbTransaction.TableName()),
+		sm.OrderBy(psql.Quote("id")),
+	).Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	return ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		return iterateCursor(ctx, conn, ts.currency, stmt, args, yield)
+	})
+}
+
+// chainLockKey is key of transaction-level advisory lock, that serializes
+// extending of chain, so chain order is the same as order of ids. The key is
+// global, so all inserts of transactions are serialized until commit: chain
+// throughput is bound by one insert and commit at a time (see
+// BenchmarkTransactionStorage_Transfer). Locks of wallets are taken before it,
+// so the critical section is only read of chain head and insert
+const chainLockKey = 0x636861696e // "chain"
+
+const (
+	chainLockQuery         = `SELECT pg_advisory_xact_lock($1)`
+	chainHeadHashQuery     = `SELECT hash FROM transactions ORDER BY id DESC LIMIT 1`
+	nextTransactionIDQuery = `SELECT nextval(pg_get_serial_sequence('transactions', 'id'))`
+)
+
+// lockChain takes chain lock until the end of tx. Row locks held by tx
+// must not conflict with key share locks of foreign keys to avoid deadlocks,
+// so wallets are locked FOR NO KEY UPDATE
+func lockChain(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, chainLockQuery, chainLockKey); err != nil {
+		return handleError(err, "error on lock chain")
+	}
+
+	return nil
+}
+
+// insertChained inserts transaction chained to the last one in tx, that must
+// hold chain lock. Hash of the last transaction is NULL if it was inserted
+// before chain was introduced
+func insertChained(ctx context.Context, tx pgx.Tx, currency money.Currency, newDBTransaction pgxmodels.Transaction) (models.Transaction, error) {
+	var prevHash []byte
+	err := tx.QueryRow(ctx, chainHeadHashQuery).Scan(&prevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.Transaction{}, handleError(err, "error on get chain head")
+	}
+
+	// hash covers id, so it's taken from sequence before insert
+	if err := tx.QueryRow(ctx, nextTransactionIDQuery).Scan(&newDBTransaction.ID); err != nil {
+		return models.Transaction{}, handleError(err, "error on get next transaction id")
+	}
+
+	transaction, err := newDBTransaction.ToDomain(currency)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Transaction = %v", newDBTransaction)
+	}
+	transaction.PrevHash = prevHash
+	transaction.Hash = transaction.ChainHash(prevHash)
+	newDBTransaction.PrevHash = transaction.PrevHash
+	newDBTransaction.Hash = transaction.Hash
+
+	// INSERT INTO newDBTransaction.TableName() VALUES newDBTransaction.Values()
+	stmt, args, err := psql.Insert(
+		im.Into(newDBTransaction.TableName(), newDBTransaction.Fields()...),
+		im.Values(psql.Arg(newDBTransaction.Values()...)),
+	).Build(ctx)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if _, err := tx.Exec(ctx, stmt, args...); err != nil {
+		return models.Transaction{}, handleError(err, "error on insert transaction")
+	}
+
+	return transaction, nil
+}
@@ -2,11 +2,9 @@ package pgx_test
 
 import (
 	"context"
-	"math"
 	"testing"
 	"time"
 
-	"github.com/brianvoe/gofakeit"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +13,8 @@ import (
 	"github.com/lunn06/wallet/internal/domain/models"
 	storageLayer "github.com/lunn06/wallet/internal/storage"
 	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+	"github.com/lunn06/wallet/internal/storage/storagetest"
+	"github.com/lunn06/wallet/pkg/money"
 )
 
 const (
@@ -29,6 +29,7 @@ const (
 )
 
 func TestTransactionStorage_GetByID(t *testing.T) {
+	requireDatabase(t)
 	t.Run("get transaction by id", func(t *testing.T) {
 		// Prepare input wallets
 		var (
@@ -36,8 +37,8 @@ func TestTransactionStorage_GetByID(t *testing.T) {
 			wallet2 models.Wallet
 		)
 		// Insert input wallets
-		err := storage.Do(func(db *pgxpool.Pool) error {
-			balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		err := storage.Do(context.Background(), func(db *pgxpool.Conn) error {
+			balance := randomBalance()
 			wallet1 = models.Wallet{
 				Address: uuid.NewString(),
 				Balance: balance,
@@ -67,7 +68,7 @@ func TestTransactionStorage_GetByID(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		transaction := models.Transaction{
 			FromAddress: wallet1.Address,
 			ToAddress:   wallet2.Address,
@@ -77,7 +78,7 @@ func TestTransactionStorage_GetByID(t *testing.T) {
 		}
 
 		// Insert transaction and scan its id
-		storage.Do(func(db *pgxpool.Pool) error {
+		storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			dbTransaction, err := pgxmodels.TransactionFromDomain(transaction)
 			values := dbTransaction.ValuesWithoutID()
 			err = db.QueryRow(context.Background(), transactionInsertQuery, values...).Scan(&transaction.ID)
@@ -86,7 +87,7 @@ func TestTransactionStorage_GetByID(t *testing.T) {
 		require.NoError(t, err)
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.Background(), transactionDeleteQuery, transaction.ID)
 			db.Exec(context.Background(), walletDeleteQuery, wallet1.ID)
 			db.Exec(context.Background(), walletDeleteQuery, wallet2.ID)
@@ -115,6 +116,7 @@ func TestTransactionStorage_GetByID(t *testing.T) {
 }
 
 func TestTransactionStorage_GetLastSuccessful(t *testing.T) {
+	requireDatabase(t)
 	t.Run("get last successful transactions", func(t *testing.T) {
 		// Prepare input wallets
 		var (
@@ -122,8 +124,8 @@ func TestTransactionStorage_GetLastSuccessful(t *testing.T) {
 			wallet2 models.Wallet
 		)
 		// Insert input wallets
-		err := storage.Do(func(db *pgxpool.Pool) error {
-			balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		err := storage.Do(context.Background(), func(db *pgxpool.Conn) error {
+			balance := randomBalance()
 			wallet1 = models.Wallet{
 				Address: uuid.NewString(),
 				Balance: balance,
@@ -155,9 +157,9 @@ func TestTransactionStorage_GetLastSuccessful(t *testing.T) {
 
 		// Insert 10 transactions
 		transactions := make([]models.Transaction, 10)
-		storage.Do(func(db *pgxpool.Pool) error {
+		storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			for i := 0; i < 10; i++ {
-				balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+				balance := randomBalance()
 				transaction := models.Transaction{
 					FromAddress: wallet1.Address,
 					ToAddress:   wallet2.Address,
@@ -180,7 +182,7 @@ func TestTransactionStorage_GetLastSuccessful(t *testing.T) {
 		require.NoError(t, err)
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.Background(), walletDeleteQuery, wallet1.ID)
 			db.Exec(context.Background(), walletDeleteQuery, wallet2.ID)
 			for _, transaction := range transactions {
@@ -212,6 +214,7 @@ func TestTransactionStorage_GetLastSuccessful(t *testing.T) {
 }
 
 func TestTransactionStorage_Insert(t *testing.T) {
+	requireDatabase(t)
 	t.Run("insert valid wallet", func(t *testing.T) {
 		// Prepare input wallets
 		var (
@@ -219,8 +222,8 @@ func TestTransactionStorage_Insert(t *testing.T) {
 			wallet2 models.Wallet
 		)
 		// Insert input wallets
-		err := storage.Do(func(db *pgxpool.Pool) error {
-			balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		err := storage.Do(context.Background(), func(db *pgxpool.Conn) error {
+			balance := randomBalance()
 			wallet1 = models.Wallet{
 				Address: uuid.NewString(),
 				Balance: balance,
@@ -250,7 +253,7 @@ func TestTransactionStorage_Insert(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		transaction := models.Transaction{
 			FromAddress: wallet1.Address,
 			ToAddress:   wallet2.Address,
@@ -263,7 +266,7 @@ func TestTransactionStorage_Insert(t *testing.T) {
 		result1, err := transactionStorage.Insert(context.Background(), transaction)
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.Background(), walletDeleteQuery, wallet1.ID)
 			db.Exec(context.Background(), walletDeleteQuery, wallet2.ID)
 			db.Exec(context.Background(), transactionDeleteQuery, result1.ID)
@@ -273,7 +276,7 @@ func TestTransactionStorage_Insert(t *testing.T) {
 
 		// Scan inserted fields
 		var dbTransaction pgxmodels.Transaction
-		err = storage.Do(func(db *pgxpool.Pool) error {
+		err = storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			dbTransaction, _ = pgxmodels.TransactionFromDomain(result1)
 			return db.QueryRow(
 				context.Background(),
@@ -289,7 +292,7 @@ func TestTransactionStorage_Insert(t *testing.T) {
 			)
 		})
 
-		result2, err := dbTransaction.ToDomain()
+		result2, err := dbTransaction.ToDomain(storagetest.Currency)
 		assert.NoError(t, err)
 
 		// Comparison inserted and scanned transactions fields
@@ -301,3 +304,33 @@ func TestTransactionStorage_Insert(t *testing.T) {
 		assert.True(t, transaction.Amount.Equal(result2.Amount))
 	})
 }
+
+// BenchmarkTransactionStorage_Transfer measures throughput of parallel
+// transfers between disjoint wallets, that are serialized by chain lock only
+func BenchmarkTransactionStorage_Transfer(b *testing.B) {
+	requireDatabase(b)
+	ctx := context.Background()
+	balance, err := models.NewBalanceFromMoney(money.FromMinor(1_000_000_000, storagetest.Currency))
+	require.NoError(b, err)
+	amount, err := models.NewBalanceFromMoney(money.FromMinor(1, storagetest.Currency))
+	require.NoError(b, err)
+
+	b.RunParallel(func(pb *testing.PB) {
+		from, err := walletStorage.Insert(ctx, models.Wallet{Address: uuid.NewString(), Balance: balance})
+		require.NoError(b, err)
+		to, err := walletStorage.Insert(ctx, models.Wallet{Address: uuid.NewString(), Balance: balance})
+		require.NoError(b, err)
+
+		for pb.Next() {
+			_, _, err := transactionStorage.Transfer(ctx, models.Transaction{
+				FromAddress: from.Address,
+				ToAddress:   to.Address,
+				Amount:      amount,
+				Timestamp:   time.Now(),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
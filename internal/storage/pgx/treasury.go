@@ -0,0 +1,214 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/stephenafamo/bob/dialect/psql/um"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type TreasuryStorage struct {
+	*Storage
+}
+
+var _ storageLayer.TreasuryStorage = TreasuryStorage{}
+
+func (ts TreasuryStorage) Apply(ctx context.Context, operation models.TreasuryOperation) (models.TreasuryOperation, error) {
+	if operation.Kind != models.TreasuryMint && operation.Kind != models.TreasuryBurn {
+		return models.TreasuryOperation{}, storageLayer.ErrInvalid.New("unknown treasury operation %q", operation.Kind)
+	}
+
+	// access to pgxpool via embed Storage
+	if err := ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		treasury, err := pgxmodels.WalletFromDomain(models.Wallet{Address: models.TreasuryAddress})
+		if err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "address = %s", models.TreasuryAddress)
+		}
+
+		// INSERT INTO wallets (address, balance, frozen) VALUES ($1, $2, $3) ON CONFLICT (address) DO NOTHING RETURNING id
+		createCte := psql.Insert(
+			im.Into(treasury.TableName(), treasury.FieldsWithoutID()...),
+			im.Values(psql.Arg(treasury.ValuesWithoutID()...)),
+			im.OnConflict(psql.Quote("address")).DoNothing(),
+			im.Returning(psql.Quote("id")),
+		)
+		createStmt, createArgs, err := createCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		// initial snapshot makes balance computable from the moment of creation,
+		// it shares timestamp with operation one to not shadow it in history
+		initialSnapshotStmt, initialSnapshotArgs, err := insertSnapshotQuery(pgxmodels.Snapshot{
+			Address:   treasury.Address,
+			Balance:   treasury.Balance,
+			Timestamp: timestampArg(operation.Timestamp),
+		}).Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		// SELECT balance FROM wallets WHERE address = $1 FOR UPDATE
+		lockCte := psql.Select(
+			sm.Columns(psql.Quote("balance")),
+			sm.From(treasury.TableName()),
+			sm.Where(psql.Quote("address").EQ(psql.Arg(treasury.Address))),
+			sm.ForUpdate(),
+		)
+		lockStmt, lockArgs, err := lockCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			var id int
+			err := tx.QueryRow(ctx, createStmt, createArgs...).Scan(&id)
+			switch {
+			case err == nil:
+				if _, err := tx.Exec(ctx, initialSnapshotStmt, initialSnapshotArgs...); err != nil {
+					return handleError(err, "error on insert treasury snapshot")
+				}
+			case !errors.Is(err, pgx.ErrNoRows):
+				return handleError(err, "error on create treasury")
+			}
+
+			var dbBalance pgxmodels.Balance
+			if err := tx.QueryRow(ctx, lockStmt, lockArgs...).Scan(&dbBalance); err != nil {
+				return handleError(err, "error on lock treasury")
+			}
+			balance, err := dbBalance.ToDomain(ts.currency)
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "balance = %s", dbBalance)
+			}
+
+			switch operation.Kind {
+			case models.TreasuryMint:
+				operation.Balance, err = balance.Add(operation.Amount)
+			case models.TreasuryBurn:
+				operation.Balance, err = balance.Sub(operation.Amount)
+			}
+			if err != nil {
+				return storageLayer.BalanceErr(err, models.TreasuryAddress)
+			}
+
+			return applyTreasuryOperation(ctx, tx, ts.currency, treasury.Address, &operation)
+		})
+	}); err != nil {
+		return models.TreasuryOperation{}, err
+	}
+
+	return operation, nil
+}
+
+// applyTreasuryOperation sets treasury balance to operation balance,
+// records its snapshot and inserts operation, setting its id
+func applyTreasuryOperation(ctx context.Context, tx pgx.Tx, currency money.Currency, address pgtype.UUID, operation *models.TreasuryOperation) error {
+	newDBOperation := pgxmodels.TreasuryOperationFromDomain(*operation)
+
+	// UPDATE wallets SET balance = $1 WHERE address = $2
+	updateCte := psql.Update(
+		um.Table(pgxmodels.Wallet{}.TableName()),
+		um.SetCol("balance").ToArg(newDBOperation.Balance),
+		um.Where(psql.Quote("address").EQ(psql.Arg(address))),
+	)
+	updateStmt, updateArgs, err := updateCte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+	if _, err := tx.Exec(ctx, updateStmt, updateArgs...); err != nil {
+		return handleError(err, "error on update treasury balance")
+	}
+
+	snapshotStmt, snapshotArgs, err := insertSnapshotQuery(pgxmodels.Snapshot{
+		Address:   address,
+		Balance:   newDBOperation.Balance,
+		Timestamp: newDBOperation.Timestamp,
+	}).Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+	if _, err := tx.Exec(ctx, snapshotStmt, snapshotArgs...); err != nil {
+		return handleError(err, "error on insert treasury snapshot")
+	}
+
+	// INSERT INTO newDBOperation.TableName() VALUES newDBOperation.ValuesWithoutID() RETURNING id
+	insertCte := psql.Insert(
+		im.Into(newDBOperation.TableName(), newDBOperation.FieldsWithoutID()...),
+		im.Values(psql.Arg(newDBOperation.ValuesWithoutID()...)),
+		im.Returning(psql.Quote("id")),
+	)
+	insertStmt, insertArgs, err := insertCte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+	if err := tx.QueryRow(ctx, insertStmt, insertArgs...).Scan(&newDBOperation.ID); err != nil {
+		return handleError(err, "error on insert treasury operation")
+	}
+
+	*operation, err = newDBOperation.ToDomain(currency)
+	if err != nil {
+		return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.TreasuryOperation = %v", newDBOperation)
+	}
+
+	return nil
+}
+
+func (ts TreasuryStorage) List(ctx context.Context, afterID int, limit int) ([]models.TreasuryOperation, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	operations := make([]models.TreasuryOperation, 0, limit)
+
+	// access to pgxpool via embed Storage
+	if err := ts.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbOperation pgxmodels.TreasuryOperation
+
+		// SELECT * FROM dbOperation.TableName() WHERE id > $1 ORDER BY id LIMIT $2
+		cte := psql.Select(
+			sm.From(dbOperation.TableName()),
+			sm.Where(psql.Quote("id").GT(psql.Arg(afterID))),
+			sm.OrderBy(psql.Quote("id")),
+			sm.Limit(limit),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "error on list treasury operations")
+		}
+
+		dbOperations, err := pgx.CollectRows(rows, pgx.RowToStructByName[pgxmodels.TreasuryOperation])
+		if err != nil {
+			return handleError(err, "error on list treasury operations")
+		}
+
+		for _, dbOperation := range dbOperations {
+			operation, err := dbOperation.ToDomain(ts.currency)
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.TreasuryOperation = %v", dbOperation)
+			}
+			operations = append(operations, operation)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return operations, nil
+}
@@ -2,8 +2,11 @@ package pgx
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stephenafamo/bob/dialect/psql"
 	"github.com/stephenafamo/bob/dialect/psql/im"
@@ -19,11 +22,13 @@ type WalletStorage struct {
 	*Storage
 }
 
+var _ storageLayer.WalletStorage = WalletStorage{}
+
 func (ws WalletStorage) GetByID(ctx context.Context, id int) (models.Wallet, error) {
 	var wallet models.Wallet
 
-	// access to pgxpool via embed Storage
-	if err := ws.Do(func(db *pgxpool.Pool) error {
+	// read access to pgxpool via embed Storage
+	if err := ws.DoRead(ctx, func(conn *pgxpool.Conn) error {
 		var dbWallet pgxmodels.Wallet
 
 		// SELECT * FROM dbWallet.TableName() WHERE id=$1 LIMIT 1
@@ -37,7 +42,7 @@ func (ws WalletStorage) GetByID(ctx context.Context, id int) (models.Wallet, err
 			return storageLayer.ErrFailedStmtBuild.Wrap(err, "id = %d", id)
 		}
 
-		rows, err := db.Query(ctx, stmt, args...)
+		rows, err := conn.Query(ctx, stmt, args...)
 		if err != nil {
 			return handleError(err, "id = %d", id)
 		}
@@ -56,7 +61,7 @@ func (ws WalletStorage) GetByID(ctx context.Context, id int) (models.Wallet, err
 			return storageLayer.ErrFailedToMarshal.Wrap(err, "pgx.User = %v", dbWallet)
 		}
 
-		wallet, err = dbWallet.ToDomain()
+		wallet, err = dbWallet.ToDomain(ws.currency)
 		if err != nil {
 			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.User = %v", dbWallet)
 		}
@@ -72,8 +77,8 @@ func (ws WalletStorage) GetByID(ctx context.Context, id int) (models.Wallet, err
 func (ws WalletStorage) GetByAddress(ctx context.Context, address string) (models.Wallet, error) {
 	var wallet models.Wallet
 
-	// access to pgxpool via embed Storage
-	if err := ws.Do(func(db *pgxpool.Pool) error {
+	// read access to pgxpool via embed Storage
+	if err := ws.DoRead(ctx, func(conn *pgxpool.Conn) error {
 		var dbWallet pgxmodels.Wallet
 
 		// SELECT * FROM dbWallet.TableName() WHERE address=$1 LIMIT 1
@@ -87,7 +92,7 @@ func (ws WalletStorage) GetByAddress(ctx context.Context, address string) (model
 			return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
 		}
 
-		rows, err := db.Query(ctx, stmt, args...)
+		rows, err := conn.Query(ctx, stmt, args...)
 		if err != nil {
 			return handleError(err, "address = %s", address)
 		}
@@ -106,7 +111,7 @@ func (ws WalletStorage) GetByAddress(ctx context.Context, address string) (model
 			return storageLayer.ErrFailedToMarshal.Wrap(err, "pgx.Wallet = %v", dbWallet)
 		}
 
-		wallet, err = dbWallet.ToDomain()
+		wallet, err = dbWallet.ToDomain(ws.currency)
 		if err != nil {
 			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Wallet = %v", dbWallet)
 		}
@@ -119,12 +124,61 @@ func (ws WalletStorage) GetByAddress(ctx context.Context, address string) (model
 	return wallet, nil
 }
 
+func (ws WalletStorage) List(ctx context.Context, afterID int, limit int) ([]models.Wallet, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	wallets := make([]models.Wallet, 0, limit)
+
+	// read access to pgxpool via embed Storage
+	if err := ws.DoRead(ctx, func(conn *pgxpool.Conn) error {
+		var dbWallet pgxmodels.Wallet
+
+		// SELECT * FROM dbWallet.TableName() WHERE id > $1 ORDER BY id LIMIT $2
+		cte := psql.Select(
+			sm.From(dbWallet.TableName()),
+			sm.Where(psql.Quote("id").GT(psql.Arg(afterID))),
+			sm.OrderBy(psql.Quote("id")),
+			sm.Limit(limit),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		rows, err := conn.Query(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "error on list wallets")
+		}
+
+		dbWallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[pgxmodels.Wallet])
+		if err != nil {
+			return handleError(err, "error on list wallets")
+		}
+
+		for _, dbWallet := range dbWallets {
+			wallet, err := dbWallet.ToDomain(ws.currency)
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Wallet = %v", dbWallet)
+			}
+			wallets = append(wallets, wallet)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
 func (ws WalletStorage) Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error) {
 	// access to pgxpool via embed Storage
-	if err := ws.Do(func(db *pgxpool.Pool) error {
+	if err := ws.Do(ctx, func(conn *pgxpool.Conn) error {
 		newDBWallet, err := pgxmodels.WalletFromDomain(wallet)
 		if err != nil {
-			return err
+			return storageLayer.ErrInvalid.Wrap(err, "address = %s", wallet.Address)
 		}
 
 		// INSERT INTO newDBWallet.TableName() VALUES newDBWallet.ValuesWithoutID() RETURNING id
@@ -138,41 +192,227 @@ func (ws WalletStorage) Insert(ctx context.Context, wallet models.Wallet) (model
 			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
 
-		rows, err := db.Query(ctx, stmt, args...)
+		// initial snapshot makes balance computable from the moment of creation
+		newDBSnapshot := pgxmodels.Snapshot{
+			Address: newDBWallet.Address,
+			Balance: newDBWallet.Balance,
+			Timestamp: pgtype.Timestamp{
+				Time:             time.Now().UTC(),
+				InfinityModifier: pgtype.Finite,
+				Valid:            true,
+			},
+		}
+		snapshotStmt, snapshotArgs, err := insertSnapshotQuery(newDBSnapshot).Build(ctx)
 		if err != nil {
-			return handleError(err, "error on insert wallet")
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
-		defer rows.Close()
 
-		if ok := rows.Next(); !ok {
-			if err := rows.Err(); err != nil {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if err := tx.QueryRow(ctx, stmt, args...).Scan(&newDBWallet.ID); err != nil {
 				return handleError(err, "error on insert wallet")
 			}
-			return storageLayer.ErrFailedToInsert.Wrap(err, "error on insert wallet")
+
+			if err := tx.QueryRow(ctx, snapshotStmt, snapshotArgs...).Scan(&newDBSnapshot.ID); err != nil {
+				return handleError(err, "error on insert wallet snapshot")
+			}
+
+			wallet, err = newDBWallet.ToDomain(ws.currency)
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "error on insert wallet")
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return models.Wallet{}, err
+	}
+
+	return wallet, nil
+}
+
+func (ws WalletStorage) InsertIfMissing(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error) {
+	inserted := false
+	// access to pgxpool via embed Storage
+	if err := ws.Do(ctx, func(conn *pgxpool.Conn) error {
+		newDBWallet, err := pgxmodels.WalletFromDomain(wallet)
+		if err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "address = %s", wallet.Address)
 		}
 
-		// Marshall query output to pgxmodels.Wallet
-		err = rows.Scan(&newDBWallet.ID)
+		// INSERT INTO wallets (address, balance, frozen) VALUES ($1, $2, $3) ON CONFLICT (address) DO NOTHING RETURNING id
+		insertCte := psql.Insert(
+			im.Into(newDBWallet.TableName(), newDBWallet.FieldsWithoutID()...),
+			im.Values(psql.Arg(newDBWallet.ValuesWithoutID()...)),
+			im.OnConflict(psql.Quote("address")).DoNothing(),
+			im.Returning(psql.Quote("id")),
+		)
+		insertStmt, insertArgs, err := insertCte.Build(ctx)
 		if err != nil {
-			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "error on insert wallet")
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
 
-		wallet, err = newDBWallet.ToDomain()
+		snapshotStmt, snapshotArgs, err := insertSnapshotQuery(pgxmodels.Snapshot{
+			Address:   newDBWallet.Address,
+			Balance:   newDBWallet.Balance,
+			Timestamp: timestampArg(time.Now()),
+		}).Build(ctx)
 		if err != nil {
-			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "error on insert wallet")
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
 		}
 
-		return nil
+		// SELECT * FROM wallets WHERE address = $1
+		selectCte := psql.Select(
+			sm.From(newDBWallet.TableName()),
+			sm.Where(psql.Quote("address").EQ(psql.Arg(newDBWallet.Address))),
+		)
+		selectStmt, selectArgs, err := selectCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			err := tx.QueryRow(ctx, insertStmt, insertArgs...).Scan(&newDBWallet.ID)
+			switch {
+			case err == nil:
+				if _, err := tx.Exec(ctx, snapshotStmt, snapshotArgs...); err != nil {
+					return handleError(err, "error on insert wallet snapshot")
+				}
+				if wallet, err = newDBWallet.ToDomain(ws.currency); err != nil {
+					return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Wallet = %v", newDBWallet)
+				}
+				inserted = true
+				return nil
+			case !errors.Is(err, pgx.ErrNoRows):
+				return handleError(err, "error on insert wallet")
+			}
+
+			// existing wallet is kept as is
+			rows, err := tx.Query(ctx, selectStmt, selectArgs...)
+			if err != nil {
+				return handleError(err, "error on get wallet")
+			}
+			storedDBWallet, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[pgxmodels.Wallet])
+			if err != nil {
+				return handleError(err, "error on get wallet")
+			}
+			if wallet, err = storedDBWallet.ToDomain(ws.currency); err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Wallet = %v", storedDBWallet)
+			}
+
+			return nil
+		})
 	}); err != nil {
-		return models.Wallet{}, err
+		return models.Wallet{}, false, err
 	}
 
-	return wallet, nil
+	return wallet, inserted, nil
+}
+
+func (ws WalletStorage) Upsert(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error) {
+	inserted := false
+	// access to pgxpool via embed Storage
+	if err := ws.Do(ctx, func(conn *pgxpool.Conn) error {
+		newDBWallet, err := pgxmodels.WalletFromDomain(wallet)
+		if err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "address = %s", wallet.Address)
+		}
+
+		// INSERT INTO wallets (address, balance, frozen) VALUES ($1, $2, $3) ON CONFLICT (address) DO NOTHING RETURNING id
+		insertCte := psql.Insert(
+			im.Into(newDBWallet.TableName(), newDBWallet.FieldsWithoutID()...),
+			im.Values(psql.Arg(newDBWallet.ValuesWithoutID()...)),
+			im.OnConflict(psql.Quote("address")).DoNothing(),
+			im.Returning(psql.Quote("id")),
+		)
+		insertStmt, insertArgs, err := insertCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		// snapshot of inserted wallet or of changed balance of existing one
+		snapshotStmt, snapshotArgs, err := insertSnapshotQuery(pgxmodels.Snapshot{
+			Address:   newDBWallet.Address,
+			Balance:   newDBWallet.Balance,
+			Timestamp: timestampArg(time.Now()),
+		}).Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		// SELECT * FROM wallets WHERE address = $1 FOR UPDATE
+		lockCte := psql.Select(
+			sm.From(newDBWallet.TableName()),
+			sm.Where(psql.Quote("address").EQ(psql.Arg(newDBWallet.Address))),
+			sm.ForUpdate(),
+		)
+		lockStmt, lockArgs, err := lockCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		// UPDATE wallets SET balance = $1 WHERE address = $2
+		updateCte := psql.Update(
+			um.Table(newDBWallet.TableName()),
+			um.SetCol("balance").ToArg(newDBWallet.Balance),
+			um.Where(psql.Quote("address").EQ(psql.Arg(newDBWallet.Address))),
+		)
+		updateStmt, updateArgs, err := updateCte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			err := tx.QueryRow(ctx, insertStmt, insertArgs...).Scan(&newDBWallet.ID)
+			switch {
+			case err == nil:
+				if _, err := tx.Exec(ctx, snapshotStmt, snapshotArgs...); err != nil {
+					return handleError(err, "error on insert wallet snapshot")
+				}
+				if wallet, err = newDBWallet.ToDomain(ws.currency); err != nil {
+					return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Wallet = %v", newDBWallet)
+				}
+				inserted = true
+				return nil
+			case !errors.Is(err, pgx.ErrNoRows):
+				return handleError(err, "error on insert wallet")
+			}
+
+			rows, err := tx.Query(ctx, lockStmt, lockArgs...)
+			if err != nil {
+				return handleError(err, "error on lock wallet")
+			}
+			storedDBWallet, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[pgxmodels.Wallet])
+			if err != nil {
+				return handleError(err, "error on lock wallet")
+			}
+			stored, err := storedDBWallet.ToDomain(ws.currency)
+			if err != nil {
+				return storageLayer.ErrFailedToUnmarshal.Wrap(err, "pgx.Wallet = %v", storedDBWallet)
+			}
+
+			if !stored.Balance.Equal(wallet.Balance) {
+				if _, err := tx.Exec(ctx, updateStmt, updateArgs...); err != nil {
+					return handleError(err, "error on update wallet balance")
+				}
+				if _, err := tx.Exec(ctx, snapshotStmt, snapshotArgs...); err != nil {
+					return handleError(err, "error on insert wallet snapshot")
+				}
+				stored.Balance = wallet.Balance
+			}
+			wallet = stored
+
+			return nil
+		})
+	}); err != nil {
+		return models.Wallet{}, false, err
+	}
+
+	return wallet, inserted, nil
 }
 
 func (ws WalletStorage) UpdateBalance(ctx context.Context, changedWallet models.Wallet) error {
 	// access to pgxpool via embed Storage
-	if err := ws.Do(func(db *pgxpool.Pool) error {
+	if err := ws.Do(ctx, func(conn *pgxpool.Conn) error {
 		changedDBWallet, err := pgxmodels.WalletFromDomain(changedWallet)
 		if err != nil {
 			return err
@@ -189,7 +429,7 @@ func (ws WalletStorage) UpdateBalance(ctx context.Context, changedWallet models.
 			return storageLayer.ErrFailedStmtBuild.Wrap(err, "id = %d", changedWallet.ID)
 		}
 
-		command, err := db.Exec(ctx, stmt, args...)
+		command, err := conn.Exec(ctx, stmt, args...)
 		if err != nil {
 			return handleError(err, "id = %d", changedWallet.ID)
 		}
@@ -206,3 +446,36 @@ func (ws WalletStorage) UpdateBalance(ctx context.Context, changedWallet models.
 
 	return nil
 }
+
+func (ws WalletStorage) SetFrozen(ctx context.Context, address string, frozen bool) error {
+	// access to pgxpool via embed Storage
+	return ws.Do(ctx, func(conn *pgxpool.Conn) error {
+		var dbAddress pgtype.UUID
+		if err := dbAddress.Scan(address); err != nil {
+			return storageLayer.ErrInvalid.Wrap(err, "address = %s", address)
+		}
+
+		// UPDATE wallets SET frozen = $1 WHERE address = $2
+		cte := psql.Update(
+			um.Table(pgxmodels.Wallet{}.TableName()),
+			um.SetCol("frozen").ToArg(frozen),
+			um.Where(psql.Quote("address").EQ(psql.Arg(dbAddress))),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+		}
+
+		command, err := conn.Exec(ctx, stmt, args...)
+		if err != nil {
+			return handleError(err, "address = %s", address)
+		}
+
+		// If zero rows affected it means that wallet not found
+		if command.RowsAffected() == 0 {
+			return storageLayer.ErrNotFound.New("address = %s", address)
+		}
+
+		return nil
+	})
+}
@@ -2,10 +2,8 @@ package pgx_test
 
 import (
 	"context"
-	"math"
 	"testing"
 
-	"github.com/brianvoe/gofakeit"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +12,7 @@ import (
 	"github.com/lunn06/wallet/internal/domain/models"
 	storageLayer "github.com/lunn06/wallet/internal/storage"
 	pgxmodels "github.com/lunn06/wallet/internal/storage/pgx/models"
+	"github.com/lunn06/wallet/internal/storage/storagetest"
 )
 
 const (
@@ -23,22 +22,23 @@ const (
 )
 
 func TestWalletStorage_GetByID(t *testing.T) {
+	requireDatabase(t)
 	t.Run("get wallet by id", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		wallet := models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
 		}
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.Background(), walletDeleteQuery, wallet.Address)
 
 			return nil
 		})
 
 		// Insert predefined wallet
-		err := storage.Do(func(db *pgxpool.Pool) error {
+		err := storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			newDBWallet, _ := pgxmodels.WalletFromDomain(wallet)
 
 			values := newDBWallet.ValuesWithoutID()
@@ -65,21 +65,22 @@ func TestWalletStorage_GetByID(t *testing.T) {
 }
 
 func TestWalletStorage_GetByAddress(t *testing.T) {
+	requireDatabase(t)
 	t.Run("get wallet by address", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		wallet := models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
 		}
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.Background(), walletDeleteQuery, wallet.Address)
 
 			return nil
 		})
 
-		err := storage.Do(func(db *pgxpool.Pool) error {
+		err := storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			newDBUser, _ := pgxmodels.WalletFromDomain(wallet)
 
 			values := newDBUser.ValuesWithoutID()
@@ -104,15 +105,16 @@ func TestWalletStorage_GetByAddress(t *testing.T) {
 }
 
 func TestWalletStorage_Insert(t *testing.T) {
+	requireDatabase(t)
 	t.Run("insert valid wallet", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		wallet := models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
 		}
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.Background(), walletDeleteQuery, wallet.Address)
 			return nil
 		})
@@ -125,7 +127,7 @@ func TestWalletStorage_Insert(t *testing.T) {
 
 		// Scan inserted fields
 		var dbWallet pgxmodels.Wallet
-		err = storage.Do(func(db *pgxpool.Pool) error {
+		err = storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			dbWallet, _ = pgxmodels.WalletFromDomain(wallet)
 			return db.QueryRow(
 				context.Background(),
@@ -134,7 +136,7 @@ func TestWalletStorage_Insert(t *testing.T) {
 			).Scan(&dbWallet.ID, &dbWallet.Address, &dbWallet.Balance)
 		})
 
-		result2, err := dbWallet.ToDomain()
+		result2, err := dbWallet.ToDomain(storagetest.Currency)
 		assert.NoError(t, err)
 
 		// Comparison inserted and scanned fields
@@ -145,20 +147,21 @@ func TestWalletStorage_Insert(t *testing.T) {
 }
 
 func TestWalletStorage_UpdateBalance(t *testing.T) {
+	requireDatabase(t)
 	t.Run("update wallet", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		wallet := models.Wallet{
 			Address: uuid.NewString(),
 			Balance: balance,
 		}
 
 		// defer cleanup func
-		defer storage.Do(func(db *pgxpool.Pool) error {
+		defer storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			db.Exec(context.TODO(), walletDeleteQuery, wallet.Address)
 			return nil
 		})
 
-		err := storage.Do(func(db *pgxpool.Pool) error {
+		err := storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			newDBWallet, _ := pgxmodels.WalletFromDomain(wallet)
 			values := newDBWallet.ValuesWithoutID()
 			err := db.QueryRow(context.Background(), walletInsertQuery, values...).Scan(&wallet.ID)
@@ -166,8 +169,9 @@ func TestWalletStorage_UpdateBalance(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		amount, _ := models.NewBalanceFromFloat(10.)
-		wallet.Balance = wallet.Balance.Add(amount)
+		amount, _ := models.NewBalanceFromString("10", storagetest.Currency)
+		wallet.Balance, err = wallet.Balance.Add(amount)
+		require.NoError(t, err)
 
 		err = walletStorage.UpdateBalance(context.Background(), wallet)
 
@@ -175,7 +179,7 @@ func TestWalletStorage_UpdateBalance(t *testing.T) {
 
 		// Scan updated fields
 		var dbWallet pgxmodels.Wallet
-		err = storage.Do(func(db *pgxpool.Pool) error {
+		err = storage.Do(context.Background(), func(db *pgxpool.Conn) error {
 			dbWallet, _ = pgxmodels.WalletFromDomain(wallet)
 			return db.QueryRow(
 				context.Background(),
@@ -184,7 +188,7 @@ func TestWalletStorage_UpdateBalance(t *testing.T) {
 			).Scan(&dbWallet.ID, &dbWallet.Address, &dbWallet.Balance)
 		})
 
-		result, err := dbWallet.ToDomain()
+		result, err := dbWallet.ToDomain(storagetest.Currency)
 		assert.NoError(t, err)
 
 		// Comparison updated and scanned fields
@@ -193,7 +197,7 @@ func TestWalletStorage_UpdateBalance(t *testing.T) {
 		assert.True(t, wallet.Balance.Equal(result.Balance))
 	})
 	t.Run("wallet not found", func(t *testing.T) {
-		balance, _ := models.NewBalanceFromFloat(math.Abs(gofakeit.Float64()))
+		balance := randomBalance()
 		wallet := models.Wallet{
 			ID:      999_999_999,
 			Address: uuid.NewString(),
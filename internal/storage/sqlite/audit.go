@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/stephenafamo/bob"
+	"github.com/stephenafamo/bob/dialect/sqlite"
+	"github.com/stephenafamo/bob/dialect/sqlite/dialect"
+	"github.com/stephenafamo/bob/dialect/sqlite/im"
+	"github.com/stephenafamo/bob/dialect/sqlite/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	sqlitemodels "github.com/lunn06/wallet/internal/storage/sqlite/models"
+)
+
+type AuditStorage struct {
+	*Storage
+}
+
+var _ storageLayer.AuditStorage = AuditStorage{}
+
+func (as AuditStorage) Insert(ctx context.Context, record models.AuditRecord) (models.AuditRecord, error) {
+	newDBRecord, err := sqlitemodels.AuditRecordFromDomain(record)
+	if err != nil {
+		return models.AuditRecord{}, storageLayer.ErrFailedToMarshal.Wrap(err, "audit record = %v", record)
+	}
+
+	// INSERT INTO newDBRecord.TableName() VALUES newDBRecord.ValuesWithoutID() RETURNING id
+	cte := sqlite.Insert(
+		im.Into(newDBRecord.TableName(), newDBRecord.FieldsWithoutID()...),
+		im.Values(sqlite.Arg(newDBRecord.ValuesWithoutID()...)),
+		im.Returning(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.AuditRecord{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if err := as.db.QueryRowContext(ctx, stmt, args...).Scan(&newDBRecord.ID); err != nil {
+		return models.AuditRecord{}, handleError(err, "error on insert audit record")
+	}
+
+	inserted, err := newDBRecord.ToDomain()
+	if err != nil {
+		return models.AuditRecord{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.AuditRecord = %v", newDBRecord)
+	}
+
+	return inserted, nil
+}
+
+func (as AuditStorage) List(ctx context.Context, filter models.AuditFilter, afterID int, limit int) ([]models.AuditRecord, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	var dbRecord sqlitemodels.AuditRecord
+
+	// SELECT ... FROM dbRecord.TableName() WHERE id > ? AND <filter> ORDER BY id LIMIT ?
+	mods := []bob.Mod[*dialect.SelectQuery]{
+		sm.Columns(quoteAll(dbRecord.Fields())...),
+		sm.From(dbRecord.TableName()),
+		sm.Where(sqlite.Quote("id").GT(sqlite.Arg(afterID))),
+		sm.OrderBy(sqlite.Quote("id")),
+		sm.Limit(limit),
+	}
+	if filter.Actor != "" {
+		mods = append(mods, sm.Where(sqlite.Quote("actor").EQ(sqlite.Arg(filter.Actor))))
+	}
+	if filter.Wallet != "" {
+		mods = append(mods, sm.Where(sqlite.Or(
+			sqlite.Quote("wallet").EQ(sqlite.Arg(filter.Wallet)),
+			sqlite.Quote("counterparty").EQ(sqlite.Arg(filter.Wallet)),
+		)))
+	}
+	if !filter.From.IsZero() {
+		mods = append(mods, sm.Where(sqlite.Quote("timestamp").GTE(sqlite.Arg(filter.From.UnixNano()))))
+	}
+	if !filter.To.IsZero() {
+		mods = append(mods, sm.Where(sqlite.Quote("timestamp").LT(sqlite.Arg(filter.To.UnixNano()))))
+	}
+
+	stmt, args, err := sqlite.Select(mods...).Build(ctx)
+	if err != nil {
+		return nil, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	rows, err := as.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, handleError(err, "error on list audit records")
+	}
+	defer rows.Close()
+
+	records := make([]models.AuditRecord, 0, limit)
+	for rows.Next() {
+		if err := rows.Scan(dbRecord.Pointers()...); err != nil {
+			return nil, storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.AuditRecord = %v", dbRecord)
+		}
+
+		record, err := dbRecord.ToDomain()
+		if err != nil {
+			return nil, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.AuditRecord = %v", dbRecord)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, handleError(err, "error on list audit records")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/stephenafamo/bob/dialect/sqlite"
+	"github.com/stephenafamo/bob/dialect/sqlite/im"
+	"github.com/stephenafamo/bob/dialect/sqlite/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	sqlitemodels "github.com/lunn06/wallet/internal/storage/sqlite/models"
+)
+
+type CheckpointStorage struct {
+	*Storage
+}
+
+var _ storageLayer.CheckpointStorage = CheckpointStorage{}
+
+func (cs CheckpointStorage) Insert(ctx context.Context, checkpoint models.Checkpoint) (models.Checkpoint, error) {
+	newDBCheckpoint := sqlitemodels.CheckpointFromDomain(checkpoint)
+
+	// INSERT INTO newDBCheckpoint.TableName() VALUES newDBCheckpoint.ValuesWithoutID() RETURNING id
+	cte := sqlite.Insert(
+		im.Into(newDBCheckpoint.TableName(), newDBCheckpoint.FieldsWithoutID()...),
+		im.Values(sqlite.Arg(newDBCheckpoint.ValuesWithoutID()...)),
+		im.Returning(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Checkpoint{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if err := cs.db.QueryRowContext(ctx, stmt, args...).Scan(&newDBCheckpoint.ID); err != nil {
+		return models.Checkpoint{}, handleError(err, "transaction_id = %d", checkpoint.TransactionID)
+	}
+
+	return newDBCheckpoint.ToDomain(), nil
+}
+
+func (cs CheckpointStorage) GetLatest(ctx context.Context) (models.Checkpoint, error) {
+	var dbCheckpoint sqlitemodels.Checkpoint
+
+	// SELECT ... FROM dbCheckpoint.TableName() ORDER BY id DESC LIMIT 1
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbCheckpoint.Fields())...),
+		sm.From(dbCheckpoint.TableName()),
+		sm.OrderBy(sqlite.Quote("id")).Desc(),
+		sm.Limit(1),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Checkpoint{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	err = cs.db.QueryRowContext(ctx, stmt, args...).Scan(dbCheckpoint.Pointers()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Checkpoint{}, storageLayer.ErrNotFound.New("no checkpoints")
+	}
+	if err != nil {
+		return models.Checkpoint{}, handleError(err, "error on get latest checkpoint")
+	}
+
+	return dbCheckpoint.ToDomain(), nil
+}
+
+func (cs CheckpointStorage) GetAll(ctx context.Context) ([]models.Checkpoint, error) {
+	var dbCheckpoint sqlitemodels.Checkpoint
+
+	// SELECT ... FROM dbCheckpoint.TableName() ORDER BY id
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbCheckpoint.Fields())...),
+		sm.From(dbCheckpoint.TableName()),
+		sm.OrderBy(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return nil, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	rows, err := cs.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, handleError(err, "error on get checkpoints")
+	}
+	defer rows.Close()
+
+	var checkpoints []models.Checkpoint
+	for rows.Next() {
+		if err := rows.Scan(dbCheckpoint.Pointers()...); err != nil {
+			return nil, storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.Checkpoint = %v", dbCheckpoint)
+		}
+		checkpoints = append(checkpoints, dbCheckpoint.ToDomain())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, handleError(err, "error on get checkpoints")
+	}
+
+	return checkpoints, nil
+}
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+// handleError handle sqlite.Error and wrap it in storageLayer errors
+func handleError(err error, message string, args ...any) error {
+	// cancelled or timed out context means that caller stopped waiting
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return storageLayer.ErrUnavailable.Wrap(err, message, args...)
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return storageLayer.UnhandledErr.Wrap(err, message, args...)
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return storageLayer.ErrUniqueViolation.New("%s", sqliteErr.Error())
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return storageLayer.ErrNotFound.New("referenced row not found, %s", sqliteErr.Error())
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		// lock wasn't released during busy timeout
+		return storageLayer.ErrUnavailable.Wrap(sqliteErr, message, args...)
+	default:
+		return storageLayer.UnhandledErr.Wrap(sqliteErr, message, args...)
+	}
+}
@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+)
+
+const (
+	createMigrationsTableQuery = `
+		CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version    INTEGER PRIMARY KEY,
+			applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	selectMigrationsQuery = "SELECT version FROM schema_migrations"
+	insertMigrationQuery  = "INSERT INTO schema_migrations (version) VALUES (?)"
+	migrationsTableQuery  = "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	query   string
+}
+
+// loadMigrations reads embedded migrations, named as <version>_<name>.sql
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		versionStr, name, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name %q", entry.Name())
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+
+		query, err := migrationsFS.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{version: version, name: name, query: string(query)})
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int { return a.version - b.version })
+
+	return migrations, nil
+}
+
+// Migrate applies pending embedded migrations in one transaction.
+// Its write lock prevents concurrent migrations from several app instances
+func (s *Storage) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return storageLayer.UnhandledErr.Wrap(err, "failed to load migrations")
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, createMigrationsTableQuery); err != nil {
+			return handleError(err, "failed to create migrations table")
+		}
+
+		applied, err := appliedMigrations(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if slices.Contains(applied, m.version) {
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, m.query); err != nil {
+				return handleError(err, "failed to apply migration %d_%s", m.version, m.name)
+			}
+			if _, err := tx.ExecContext(ctx, insertMigrationQuery, m.version); err != nil {
+				return handleError(err, "failed to apply migration %d_%s", m.version, m.name)
+			}
+
+			s.logger.Info("Migration applied", "version", m.version, "name", m.name)
+		}
+
+		return nil
+	})
+}
+
+// PendingMigrations returns versions of embedded migrations,
+// that are not applied to database yet
+func (s *Storage) PendingMigrations(ctx context.Context) ([]int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, storageLayer.UnhandledErr.Wrap(err, "failed to load migrations")
+	}
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, migrationsTableQuery).Scan(&exists); err != nil {
+		return nil, handleError(err, "failed to check migrations table")
+	}
+
+	var applied []int
+	if exists {
+		applied, err = appliedMigrations(ctx, s.db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pending []int
+	for _, m := range migrations {
+		if !slices.Contains(applied, m.version) {
+			pending = append(pending, m.version)
+		}
+	}
+
+	return pending, nil
+}
+
+// querier is common interface of sql.DB and sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func appliedMigrations(ctx context.Context, q querier) ([]int, error) {
+	rows, err := q.QueryContext(ctx, selectMigrationsQuery)
+	if err != nil {
+		return nil, handleError(err, "failed to select migrations")
+	}
+	defer rows.Close()
+
+	var applied []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, handleError(err, "failed to select migrations")
+		}
+		applied = append(applied, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, handleError(err, "failed to select migrations")
+	}
+
+	return applied, nil
+}
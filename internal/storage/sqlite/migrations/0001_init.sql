@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS wallets
+(
+    id      INTEGER PRIMARY KEY AUTOINCREMENT,
+    address TEXT NOT NULL UNIQUE,
+    -- exact decimal string, REAL would lose precision
+    balance TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transactions
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    from_address TEXT    NOT NULL REFERENCES wallets (address),
+    to_address   TEXT    NOT NULL REFERENCES wallets (address),
+    -- exact decimal string, REAL would lose precision
+    amount       TEXT    NOT NULL,
+    -- unix time in nanoseconds
+    timestamp    INTEGER NOT NULL,
+    successful   INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS transactions_successful_timestamp_idx
+    ON transactions (successful, timestamp DESC);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS balance_snapshots
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    address   TEXT    NOT NULL REFERENCES wallets (address),
+    -- exact decimal string, REAL would lose precision
+    balance   TEXT    NOT NULL,
+    -- unix time in nanoseconds
+    timestamp INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS balance_snapshots_address_timestamp_idx
+    ON balance_snapshots (address, timestamp DESC);
+
+CREATE INDEX IF NOT EXISTS transactions_from_address_timestamp_idx
+    ON transactions (from_address, timestamp);
+
+CREATE INDEX IF NOT EXISTS transactions_to_address_timestamp_idx
+    ON transactions (to_address, timestamp);
+
+-- existing wallets get initial snapshot of their current balance
+INSERT INTO balance_snapshots (address, balance, timestamp)
+SELECT address, balance, CAST(unixepoch('now', 'subsec') * 1000000000 AS INTEGER)
+FROM wallets;
@@ -0,0 +1,15 @@
+-- hash chain over transactions, rows inserted before
+-- this migration stay unchained with NULL hashes
+ALTER TABLE transactions ADD COLUMN prev_hash BLOB;
+ALTER TABLE transactions ADD COLUMN hash BLOB;
+
+CREATE TABLE IF NOT EXISTS chain_checkpoints
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id),
+    hash           BLOB    NOT NULL,
+    -- unix time in nanoseconds
+    timestamp      INTEGER NOT NULL,
+    -- ed25519 signature of models.Checkpoint.SignedData()
+    signature      BLOB    NOT NULL
+);
@@ -0,0 +1,17 @@
+-- frozen wallets can neither send nor receive transfers
+ALTER TABLE wallets ADD COLUMN frozen INTEGER NOT NULL DEFAULT 0;
+
+-- funds issued into and retired from treasury wallet by operators
+CREATE TABLE IF NOT EXISTS treasury_operations
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- mint or burn
+    kind      TEXT    NOT NULL,
+    -- exact decimal string, REAL would lose precision
+    amount    TEXT    NOT NULL,
+    reason    TEXT    NOT NULL,
+    -- treasury balance after operation
+    balance   TEXT    NOT NULL,
+    -- unix time in nanoseconds
+    timestamp INTEGER NOT NULL
+);
@@ -0,0 +1,37 @@
+-- append-only records of state-changing actions
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    -- unix time in nanoseconds
+    timestamp    INTEGER NOT NULL,
+    actor        TEXT    NOT NULL,
+    source_ip    TEXT    NOT NULL,
+    request_id   TEXT    NOT NULL,
+    action       TEXT    NOT NULL,
+    wallet       TEXT    NOT NULL,
+    counterparty TEXT    NOT NULL,
+    -- json objects, null if state is empty
+    state_before TEXT,
+    state_after  TEXT,
+    -- success or failure
+    outcome      TEXT    NOT NULL,
+    error        TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor, id);
+CREATE INDEX IF NOT EXISTS audit_log_wallet_idx ON audit_log (wallet, id);
+CREATE INDEX IF NOT EXISTS audit_log_counterparty_idx ON audit_log (counterparty, id);
+CREATE INDEX IF NOT EXISTS audit_log_timestamp_idx ON audit_log (timestamp);
+
+-- records can't be changed or deleted even by app itself
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+type AuditRecord struct {
+	ID           int            `db:"id"`
+	Timestamp    int64          `db:"timestamp"` // unix time in nanoseconds
+	Actor        string         `db:"actor"`
+	SourceIP     string         `db:"source_ip"`
+	RequestID    string         `db:"request_id"`
+	Action       string         `db:"action"`
+	Wallet       string         `db:"wallet"`
+	Counterparty string         `db:"counterparty"`
+	Before       sql.NullString `db:"state_before"` // json object
+	After        sql.NullString `db:"state_after"`
+	Outcome      string         `db:"outcome"`
+	Error        string         `db:"error"`
+}
+
+func (r AuditRecord) TableName() string {
+	return "audit_log"
+}
+
+func (r AuditRecord) Fields() []string {
+	return []string{
+		"id", "timestamp", "actor", "source_ip", "request_id", "action",
+		"wallet", "counterparty", "state_before", "state_after", "outcome", "error",
+	}
+}
+
+func (r AuditRecord) FieldsWithoutID() []string {
+	return r.Fields()[1:]
+}
+
+func (r AuditRecord) Values() []any {
+	return []any{
+		r.ID, r.Timestamp, r.Actor, r.SourceIP, r.RequestID, r.Action,
+		r.Wallet, r.Counterparty, r.Before, r.After, r.Outcome, r.Error,
+	}
+}
+
+func (r AuditRecord) ValuesWithoutID() []any {
+	return r.Values()[1:]
+}
+
+// Pointers returns scan destinations in order of Fields
+func (r *AuditRecord) Pointers() []any {
+	return []any{
+		&r.ID, &r.Timestamp, &r.Actor, &r.SourceIP, &r.RequestID, &r.Action,
+		&r.Wallet, &r.Counterparty, &r.Before, &r.After, &r.Outcome, &r.Error,
+	}
+}
+
+func (r AuditRecord) ToDomain() (models.AuditRecord, error) {
+	before, err := auditStateFromJSON(r.Before)
+	if err != nil {
+		return models.AuditRecord{}, err
+	}
+	after, err := auditStateFromJSON(r.After)
+	if err != nil {
+		return models.AuditRecord{}, err
+	}
+
+	return models.AuditRecord{
+		ID:           r.ID,
+		Timestamp:    time.Unix(0, r.Timestamp).UTC(),
+		Actor:        r.Actor,
+		SourceIP:     r.SourceIP,
+		RequestID:    r.RequestID,
+		Action:       r.Action,
+		Wallet:       r.Wallet,
+		Counterparty: r.Counterparty,
+		Before:       before,
+		After:        after,
+		Outcome:      r.Outcome,
+		Error:        r.Error,
+	}, nil
+}
+
+func AuditRecordFromDomain(domain models.AuditRecord) (AuditRecord, error) {
+	before, err := auditStateToJSON(domain.Before)
+	if err != nil {
+		return AuditRecord{}, err
+	}
+	after, err := auditStateToJSON(domain.After)
+	if err != nil {
+		return AuditRecord{}, err
+	}
+
+	return AuditRecord{
+		ID:           domain.ID,
+		Timestamp:    domain.Timestamp.UnixNano(),
+		Actor:        domain.Actor,
+		SourceIP:     domain.SourceIP,
+		RequestID:    domain.RequestID,
+		Action:       domain.Action,
+		Wallet:       domain.Wallet,
+		Counterparty: domain.Counterparty,
+		Before:       before,
+		After:        after,
+		Outcome:      domain.Outcome,
+		Error:        domain.Error,
+	}, nil
+}
+
+// auditStateToJSON encodes state, empty state is stored as NULL
+func auditStateToJSON(state models.AuditState) (sql.NullString, error) {
+	if len(state) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(raw), Valid: true}, nil
+}
+
+func auditStateFromJSON(raw sql.NullString) (models.AuditState, error) {
+	if !raw.Valid {
+		return nil, nil
+	}
+
+	var state models.AuditState
+	if err := json.Unmarshal([]byte(raw.String), &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
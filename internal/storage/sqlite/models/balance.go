@@ -0,0 +1,22 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// Balance is stored as exact decimal text, decimal.Decimal
+// implements driver.Valuer as string and sql.Scanner from it
+type Balance struct {
+	decimal.Decimal
+}
+
+func (b Balance) ToDomain(currency money.Currency) (models.Balance, error) {
+	return models.NewBalanceFromDecimal(b.Decimal, currency)
+}
+
+func BalanceFromDomain(b models.Balance) Balance {
+	return Balance{b.Decimal()}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+type Checkpoint struct {
+	ID            int    `db:"id"`
+	TransactionID int    `db:"transaction_id"`
+	Hash          []byte `db:"hash"`
+	Timestamp     int64  `db:"timestamp"` // unix time in nanoseconds
+	Signature     []byte `db:"signature"`
+}
+
+func (c Checkpoint) TableName() string {
+	return "chain_checkpoints"
+}
+
+func (c Checkpoint) Fields() []string {
+	return []string{"id", "transaction_id", "hash", "timestamp", "signature"}
+}
+
+func (c Checkpoint) FieldsWithoutID() []string {
+	return c.Fields()[1:]
+}
+
+func (c Checkpoint) Values() []any {
+	return []any{c.ID, c.TransactionID, c.Hash, c.Timestamp, c.Signature}
+}
+
+func (c Checkpoint) ValuesWithoutID() []any {
+	return c.Values()[1:]
+}
+
+// Pointers returns scan destinations in order of Fields
+func (c *Checkpoint) Pointers() []any {
+	return []any{&c.ID, &c.TransactionID, &c.Hash, &c.Timestamp, &c.Signature}
+}
+
+func (c Checkpoint) ToDomain() models.Checkpoint {
+	return models.Checkpoint{
+		ID:            c.ID,
+		TransactionID: c.TransactionID,
+		Hash:          c.Hash,
+		Timestamp:     time.Unix(0, c.Timestamp).UTC(),
+		Signature:     c.Signature,
+	}
+}
+
+func CheckpointFromDomain(domain models.Checkpoint) Checkpoint {
+	return Checkpoint{
+		ID:            domain.ID,
+		TransactionID: domain.TransactionID,
+		Hash:          domain.Hash,
+		Timestamp:     domain.Timestamp.UnixNano(),
+		Signature:     domain.Signature,
+	}
+}
@@ -0,0 +1,2 @@
+// Package models presents sqlite models of storage layer
+package models
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type Snapshot struct {
+	ID        int     `db:"id"`
+	Address   string  `db:"address"`
+	Balance   Balance `db:"balance"`
+	Timestamp int64   `db:"timestamp"` // unix time in nanoseconds
+}
+
+func (s Snapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+func (s Snapshot) Fields() []string {
+	return []string{"id", "address", "balance", "timestamp"}
+}
+
+func (s Snapshot) FieldsWithoutID() []string {
+	return s.Fields()[1:]
+}
+
+func (s Snapshot) Values() []any {
+	return []any{s.ID, s.Address, s.Balance, s.Timestamp}
+}
+
+func (s Snapshot) ValuesWithoutID() []any {
+	return s.Values()[1:]
+}
+
+// Pointers returns scan destinations in order of Fields
+func (s *Snapshot) Pointers() []any {
+	return []any{&s.ID, &s.Address, &s.Balance, &s.Timestamp}
+}
+
+func (s Snapshot) ToDomain(currency money.Currency) (models.Snapshot, error) {
+	balance, err := s.Balance.ToDomain(currency)
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	return models.Snapshot{
+		ID:        s.ID,
+		Address:   s.Address,
+		Balance:   balance,
+		Timestamp: time.Unix(0, s.Timestamp).UTC(),
+	}, nil
+}
+
+func SnapshotFromDomain(domain models.Snapshot) (Snapshot, error) {
+	address, err := NormalizeAddress(domain.Address)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{
+		ID:        domain.ID,
+		Address:   address,
+		Balance:   BalanceFromDomain(domain.Balance),
+		Timestamp: domain.Timestamp.UnixNano(),
+	}, nil
+}
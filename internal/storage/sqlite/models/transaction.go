@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type Transaction struct {
+	ID          int     `db:"id"`
+	FromAddress string  `db:"from_address"`
+	ToAddress   string  `db:"to_address"`
+	Amount      Balance `db:"amount"`
+	Timestamp   int64   `db:"timestamp"` // unix time in nanoseconds
+	Successful  bool    `db:"successful"`
+	PrevHash    []byte  `db:"prev_hash"`
+	Hash        []byte  `db:"hash"`
+}
+
+func (t Transaction) TableName() string {
+	return "transactions"
+}
+
+func (t Transaction) Fields() []string {
+	return []string{"id", "from_address", "to_address", "amount", "timestamp", "successful", "prev_hash", "hash"}
+}
+
+func (t Transaction) FieldsWithoutID() []string {
+	return t.Fields()[1:]
+}
+
+func (t Transaction) Values() []any {
+	return []any{t.ID, t.FromAddress, t.ToAddress, t.Amount, t.Timestamp, t.Successful, t.PrevHash, t.Hash}
+}
+
+func (t Transaction) ValuesWithoutID() []any {
+	return t.Values()[1:]
+}
+
+// Pointers returns scan destinations in order of Fields
+func (t *Transaction) Pointers() []any {
+	return []any{&t.ID, &t.FromAddress, &t.ToAddress, &t.Amount, &t.Timestamp, &t.Successful, &t.PrevHash, &t.Hash}
+}
+
+func (t Transaction) ToDomain(currency money.Currency) (models.Transaction, error) {
+	amount, err := t.Amount.ToDomain(currency)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	return models.Transaction{
+		ID:          t.ID,
+		FromAddress: t.FromAddress,
+		ToAddress:   t.ToAddress,
+		Amount:      amount,
+		Timestamp:   time.Unix(0, t.Timestamp).UTC(),
+		Successful:  t.Successful,
+		PrevHash:    t.PrevHash,
+		Hash:        t.Hash,
+	}, nil
+}
+
+func TransactionFromDomain(domain models.Transaction) (Transaction, error) {
+	fromAddress, err := NormalizeAddress(domain.FromAddress)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	toAddress, err := NormalizeAddress(domain.ToAddress)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return Transaction{
+		ID:          domain.ID,
+		FromAddress: fromAddress,
+		ToAddress:   toAddress,
+		Amount:      BalanceFromDomain(domain.Amount),
+		Timestamp:   domain.Timestamp.UnixNano(),
+		Successful:  domain.Successful,
+		PrevHash:    domain.PrevHash,
+		Hash:        domain.Hash,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type TreasuryOperation struct {
+	ID        int     `db:"id"`
+	Kind      string  `db:"kind"`
+	Amount    Balance `db:"amount"`
+	Reason    string  `db:"reason"`
+	Balance   Balance `db:"balance"`
+	Timestamp int64   `db:"timestamp"` // unix time in nanoseconds
+}
+
+func (o TreasuryOperation) TableName() string {
+	return "treasury_operations"
+}
+
+func (o TreasuryOperation) Fields() []string {
+	return []string{"id", "kind", "amount", "reason", "balance", "timestamp"}
+}
+
+func (o TreasuryOperation) FieldsWithoutID() []string {
+	return o.Fields()[1:]
+}
+
+func (o TreasuryOperation) Values() []any {
+	return []any{o.ID, o.Kind, o.Amount, o.Reason, o.Balance, o.Timestamp}
+}
+
+func (o TreasuryOperation) ValuesWithoutID() []any {
+	return o.Values()[1:]
+}
+
+// Pointers returns scan destinations in order of Fields
+func (o *TreasuryOperation) Pointers() []any {
+	return []any{&o.ID, &o.Kind, &o.Amount, &o.Reason, &o.Balance, &o.Timestamp}
+}
+
+func (o TreasuryOperation) ToDomain(currency money.Currency) (models.TreasuryOperation, error) {
+	amount, err := o.Amount.ToDomain(currency)
+	if err != nil {
+		return models.TreasuryOperation{}, err
+	}
+	balance, err := o.Balance.ToDomain(currency)
+	if err != nil {
+		return models.TreasuryOperation{}, err
+	}
+
+	return models.TreasuryOperation{
+		ID:        o.ID,
+		Kind:      o.Kind,
+		Amount:    amount,
+		Reason:    o.Reason,
+		Balance:   balance,
+		Timestamp: time.Unix(0, o.Timestamp).UTC(),
+	}, nil
+}
+
+func TreasuryOperationFromDomain(domain models.TreasuryOperation) TreasuryOperation {
+	return TreasuryOperation{
+		ID:        domain.ID,
+		Kind:      domain.Kind,
+		Amount:    BalanceFromDomain(domain.Amount),
+		Reason:    domain.Reason,
+		Balance:   BalanceFromDomain(domain.Balance),
+		Timestamp: domain.Timestamp.UnixNano(),
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type Wallet struct {
+	ID      int     `db:"id"`
+	Address string  `db:"address"`
+	Balance Balance `db:"balance"`
+	Frozen  bool    `db:"frozen"`
+}
+
+func (w Wallet) TableName() string {
+	return "wallets"
+}
+
+func (w Wallet) Fields() []string {
+	return []string{"id", "address", "balance", "frozen"}
+}
+
+func (w Wallet) FieldsWithoutID() []string {
+	return w.Fields()[1:]
+}
+
+func (w Wallet) Values() []any {
+	return []any{w.ID, w.Address, w.Balance, w.Frozen}
+}
+
+func (w Wallet) ValuesWithoutID() []any {
+	return w.Values()[1:]
+}
+
+// Pointers returns scan destinations in order of Fields
+func (w *Wallet) Pointers() []any {
+	return []any{&w.ID, &w.Address, &w.Balance, &w.Frozen}
+}
+
+func (w Wallet) ToDomain(currency money.Currency) (models.Wallet, error) {
+	balance, err := w.Balance.ToDomain(currency)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+	return models.Wallet{
+		ID:      w.ID,
+		Address: w.Address,
+		Balance: balance,
+		Frozen:  w.Frozen,
+	}, nil
+}
+
+// WalletFromDomain converts wallet with address in canonical uuid form,
+// as sqlite has no uuid type to do it
+func WalletFromDomain(domain models.Wallet) (Wallet, error) {
+	address, err := NormalizeAddress(domain.Address)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	return Wallet{
+		ID:      domain.ID,
+		Address: address,
+		Balance: BalanceFromDomain(domain.Balance),
+		Frozen:  domain.Frozen,
+	}, nil
+}
+
+// NormalizeAddress validates address as uuid and returns its canonical form
+func NormalizeAddress(address string) (string, error) {
+	parsed, err := uuid.Parse(address)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.String(), nil
+}
@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/stephenafamo/bob/dialect/sqlite"
+	"github.com/stephenafamo/bob/dialect/sqlite/im"
+	"github.com/stephenafamo/bob/dialect/sqlite/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	sqlitemodels "github.com/lunn06/wallet/internal/storage/sqlite/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// deleteSnapshotsQuery deletes snapshots taken before ?1, for which
+// a newer snapshot of the same wallet taken before ?1 exists
+const deleteSnapshotsQuery = `
+	DELETE FROM balance_snapshots AS s
+	WHERE s.timestamp < ?1
+	  AND EXISTS (
+		SELECT 1 FROM balance_snapshots AS n
+		WHERE n.address = s.address
+		  AND n.timestamp > s.timestamp
+		  AND n.timestamp < ?1
+	  )
+`
+
+type SnapshotStorage struct {
+	*Storage
+}
+
+var _ storageLayer.SnapshotStorage = SnapshotStorage{}
+
+func (ss SnapshotStorage) Insert(ctx context.Context, snapshot models.Snapshot) (models.Snapshot, error) {
+	newDBSnapshot, err := sqlitemodels.SnapshotFromDomain(snapshot)
+	if err != nil {
+		return models.Snapshot{}, storageLayer.ErrInvalid.Wrap(err, "address = %s", snapshot.Address)
+	}
+
+	return insertSnapshot(ctx, ss.db, ss.currency, newDBSnapshot)
+}
+
+func (ss SnapshotStorage) GetLatest(ctx context.Context, address string, moment time.Time) (models.Snapshot, error) {
+	normalized, err := sqlitemodels.NormalizeAddress(address)
+	if err != nil {
+		return models.Snapshot{}, storageLayer.ErrInvalid.Wrap(err, "address = %s", address)
+	}
+
+	var dbSnapshot sqlitemodels.Snapshot
+
+	// SELECT ... FROM dbSnapshot.TableName() WHERE address = ? AND timestamp <= ? ORDER BY timestamp DESC, id DESC LIMIT 1
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbSnapshot.Fields())...),
+		sm.From(dbSnapshot.TableName()),
+		sm.Where(sqlite.Quote("address").EQ(sqlite.Arg(normalized))),
+		sm.Where(sqlite.Quote("timestamp").LTE(sqlite.Arg(moment.UnixNano()))),
+		sm.OrderBy(sqlite.Quote("timestamp")).Desc(),
+		sm.OrderBy(sqlite.Quote("id")).Desc(),
+		sm.Limit(1),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Snapshot{}, storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	err = ss.db.QueryRowContext(ctx, stmt, args...).Scan(dbSnapshot.Pointers()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Snapshot{}, storageLayer.ErrNotFound.New("snapshot not found, address = %s, moment = %s", address, moment)
+	}
+	if err != nil {
+		return models.Snapshot{}, handleError(err, "address = %s", address)
+	}
+
+	snapshot, err := dbSnapshot.ToDomain(ss.currency)
+	if err != nil {
+		return models.Snapshot{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Snapshot = %v", dbSnapshot)
+	}
+
+	return snapshot, nil
+}
+
+func (ss SnapshotStorage) DeleteBefore(ctx context.Context, moment time.Time) (int, error) {
+	result, err := ss.db.ExecContext(ctx, deleteSnapshotsQuery, moment.UnixNano())
+	if err != nil {
+		return 0, handleError(err, "moment = %s", moment)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, handleError(err, "moment = %s", moment)
+	}
+
+	return int(deleted), nil
+}
+
+func insertSnapshot(ctx context.Context, q execQuerier, currency money.Currency, newDBSnapshot sqlitemodels.Snapshot) (models.Snapshot, error) {
+	// INSERT INTO newDBSnapshot.TableName() VALUES newDBSnapshot.ValuesWithoutID() RETURNING id
+	cte := sqlite.Insert(
+		im.Into(newDBSnapshot.TableName(), newDBSnapshot.FieldsWithoutID()...),
+		im.Values(sqlite.Arg(newDBSnapshot.ValuesWithoutID()...)),
+		im.Returning(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Snapshot{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if err := q.QueryRowContext(ctx, stmt, args...).Scan(&newDBSnapshot.ID); err != nil {
+		return models.Snapshot{}, handleError(err, "error on insert snapshot")
+	}
+
+	snapshot, err := newDBSnapshot.ToDomain(currency)
+	if err != nil {
+		return models.Snapshot{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Snapshot = %v", newDBSnapshot)
+	}
+
+	return snapshot, nil
+}
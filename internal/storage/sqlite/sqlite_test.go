@@ -0,0 +1,109 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/internal/storage/sqlite"
+	"github.com/lunn06/wallet/internal/storage/storagetest"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// newStorage creates migrated storage in temporary database file
+func newStorage(t *testing.T) *sqlite.Storage {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "wallet.db"), 5*time.Second, storagetest.Currency, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close(context.Background()) })
+
+	require.NoError(t, storage.Migrate(context.Background()))
+
+	return storage
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storages {
+		storage := newStorage(t)
+		return storagetest.Storages{
+			Wallet:      sqlite.WalletStorage{Storage: storage},
+			Transaction: sqlite.TransactionStorage{Storage: storage},
+			Snapshot:    sqlite.SnapshotStorage{Storage: storage},
+			Checkpoint:  sqlite.CheckpointStorage{Storage: storage},
+			Treasury:    sqlite.TreasuryStorage{Storage: storage},
+			Audit:       sqlite.AuditStorage{Storage: storage},
+		}
+	})
+}
+
+func TestStorage_Migrate(t *testing.T) {
+	storage := newStorage(t)
+
+	// repeated migration is no-op
+	require.NoError(t, storage.Migrate(context.Background()))
+	require.NoError(t, storage.CheckMigrations(context.Background()))
+}
+
+func TestWalletStorage_CurrencyScale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.db")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage, err := sqlite.NewStorage(path, 5*time.Second, storagetest.Currency, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close(context.Background()) })
+	require.NoError(t, storage.Migrate(context.Background()))
+
+	balance, err := models.NewBalanceFromString("1.005", storagetest.Currency)
+	require.NoError(t, err)
+	wallet, err := sqlite.WalletStorage{Storage: storage}.Insert(context.Background(), models.Wallet{
+		Address: uuid.NewString(),
+		Balance: balance,
+	})
+	require.NoError(t, err)
+
+	// amounts stored with more digits than currency allows are never rounded
+	cents, err := sqlite.NewStorage(path, 5*time.Second, money.Currency{Code: "USD", Scale: 2}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { cents.Close(context.Background()) })
+
+	_, err = sqlite.WalletStorage{Storage: cents}.GetByID(context.Background(), wallet.ID)
+	assert.True(t, errorx.IsOfType(err, storageLayer.ErrFailedToUnmarshal), "expected unmarshal error, got %v", err)
+}
+
+func TestAuditStorage_AppendOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.db")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage, err := sqlite.NewStorage(path, 5*time.Second, storagetest.Currency, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close(context.Background()) })
+	require.NoError(t, storage.Migrate(context.Background()))
+
+	_, err = sqlite.AuditStorage{Storage: storage}.Insert(context.Background(), models.AuditRecord{
+		Timestamp: time.Now(),
+		Actor:     "ops",
+		Action:    models.AuditWalletCreate,
+		Outcome:   models.AuditSuccess,
+	})
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`UPDATE audit_log SET actor = 'intruder'`)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = db.Exec(`DELETE FROM audit_log`)
+	assert.ErrorContains(t, err, "append-only")
+}
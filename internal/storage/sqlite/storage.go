@@ -0,0 +1,136 @@
+// Package sqlite contains implementation of storage layer on SQLite
+// for small self-hosted deployments, amounts are stored as exact decimal text
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/pkg/money"
+
+	_ "modernc.org/sqlite"
+)
+
+// Storage is sql.DB wrapper (that need to embed in other storages).
+// Every transaction takes write lock on begin, so transactions
+// are serialized and balance checks can't race with each other. Stored amounts are in
+// currency, amounts with more digits than it allows are reported as ErrFailedToUnmarshal
+type Storage struct {
+	currency money.Currency
+	db       *sql.DB
+	logger   *slog.Logger
+	dbStats  prometheus.Collector
+}
+
+// NewStorage opens database file at path, creating it if needed.
+// Busy timeout bounds waiting for lock held by other connection
+func NewStorage(path string, busyTimeout time.Duration, currency money.Currency, logger *slog.Logger) (*Storage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", buildDSN(path, busyTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logger.Info("SQLiteStorage created", "path", path)
+
+	return &Storage{
+		currency: currency,
+		db:       db,
+		logger:   logger,
+		dbStats:  collectors.NewDBStatsCollector(db, "sqlite"),
+	}, nil
+}
+
+func buildDSN(path string, busyTimeout time.Duration) string {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()))
+	// BEGIN IMMEDIATE takes write lock at once instead of upgrading read lock
+	// inside transaction, which fails with SQLITE_BUSY without waiting
+	query.Set("_txlock", "immediate")
+
+	return "file:" + path + "?" + query.Encode()
+}
+
+// inTx runs f in transaction, that is committed if f returns nil
+func (s *Storage) inTx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return handleError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return handleError(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// Ping checks that database file is accessible
+func (s *Storage) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return handleError(err, "failed to ping database")
+	}
+	return nil
+}
+
+// CheckMigrations returns error if some of embedded migrations are not applied
+func (s *Storage) CheckMigrations(ctx context.Context) error {
+	pending, err := s.PendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return storageLayer.ErrInvalid.New("pending migrations: %v", pending)
+	}
+
+	return nil
+}
+
+// Describe implements prometheus.Collector with sql.DB stats
+func (s *Storage) Describe(ch chan<- *prometheus.Desc) {
+	s.dbStats.Describe(ch)
+}
+
+// Collect implements prometheus.Collector with sql.DB stats
+func (s *Storage) Collect(ch chan<- prometheus.Metric) {
+	s.dbStats.Collect(ch)
+}
+
+func (s *Storage) Close(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- s.db.Close()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
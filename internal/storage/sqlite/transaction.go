@@ -0,0 +1,407 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/stephenafamo/bob/dialect/sqlite"
+	"github.com/stephenafamo/bob/dialect/sqlite/im"
+	"github.com/stephenafamo/bob/dialect/sqlite/sm"
+	"github.com/stephenafamo/bob/dialect/sqlite/um"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	sqlitemodels "github.com/lunn06/wallet/internal/storage/sqlite/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type TransactionStorage struct {
+	*Storage
+}
+
+var _ storageLayer.TransactionStorage = TransactionStorage{}
+
+func (ts TransactionStorage) GetLastSuccessful(ctx context.Context, limit int) ([]models.Transaction, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	var dbTransaction sqlitemodels.Transaction
+
+	// SELECT ... FROM dbTransaction.TableName() WHERE successful = true ORDER BY timestamp DESC, id DESC LIMIT ?
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbTransaction.Fields())...),
+		sm.From(dbTransaction.TableName()),
+		sm.Where(sqlite.Quote("successful").EQ(sqlite.Arg(true))),
+		sm.OrderBy(sqlite.Quote("timestamp")).Desc(),
+		sm.OrderBy(sqlite.Quote("id")).Desc(),
+		sm.Limit(limit),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return nil, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	rows, err := ts.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, handleError(err, "error on GetLastSuccessful transaction")
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0, limit)
+	for rows.Next() {
+		if err := rows.Scan(dbTransaction.Pointers()...); err != nil {
+			return nil, storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+		}
+
+		transaction, err := dbTransaction.ToDomain(ts.currency)
+		if err != nil {
+			return nil, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, handleError(err, "error on GetLastSuccessful transaction")
+	}
+
+	return transactions, nil
+}
+
+func (ts TransactionStorage) GetSuccessfulByAddress(
+	ctx context.Context,
+	address string,
+	after, until time.Time,
+) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	if err := ts.IterateSuccessfulByAddress(ctx, address, after, until, func(transaction models.Transaction) error {
+		transactions = append(transactions, transaction)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (ts TransactionStorage) IterateSuccessfulByAddress(
+	ctx context.Context,
+	address string,
+	after, until time.Time,
+	yield func(models.Transaction) error,
+) error {
+	normalized, err := sqlitemodels.NormalizeAddress(address)
+	if err != nil {
+		return storageLayer.ErrInvalid.Wrap(err, "address = %s", address)
+	}
+
+	var dbTransaction sqlitemodels.Transaction
+
+	// SELECT ... FROM dbTransaction.TableName()
+	// WHERE successful = true AND (from_address = ? OR to_address = ?) AND timestamp > ? AND timestamp <= ?
+	// ORDER BY timestamp, id
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbTransaction.Fields())...),
+		sm.From(dbTransaction.TableName()),
+		sm.Where(sqlite.Quote("successful").EQ(sqlite.Arg(true))),
+		sm.Where(sqlite.Or(
+			sqlite.Quote("from_address").EQ(sqlite.Arg(normalized)),
+			sqlite.Quote("to_address").EQ(sqlite.Arg(normalized)),
+		)),
+		sm.Where(sqlite.Quote("timestamp").GT(sqlite.Arg(after.UnixNano()))),
+		sm.Where(sqlite.Quote("timestamp").LTE(sqlite.Arg(until.UnixNano()))),
+		sm.OrderBy(sqlite.Quote("timestamp")),
+		sm.OrderBy(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	// rows are stepped one by one, so result isn't loaded into memory
+	rows, err := ts.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return handleError(err, "address = %s", address)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(dbTransaction.Pointers()...); err != nil {
+			return storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+		}
+
+		transaction, err := dbTransaction.ToDomain(ts.currency)
+		if err != nil {
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+		}
+		if err := yield(transaction); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return handleError(err, "address = %s", address)
+	}
+
+	return nil
+}
+
+func (ts TransactionStorage) GetByID(ctx context.Context, id int) (models.Transaction, error) {
+	var dbTransaction sqlitemodels.Transaction
+
+	// SELECT ... FROM dbTransaction.TableName() WHERE id = ? LIMIT 1
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbTransaction.Fields())...),
+		sm.From(dbTransaction.TableName()),
+		sm.Where(sqlite.Quote("id").EQ(sqlite.Arg(id))),
+		sm.Limit(1),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedStmtBuild.Wrap(err, "id = %d", id)
+	}
+
+	err = ts.db.QueryRowContext(ctx, stmt, args...).Scan(dbTransaction.Pointers()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Transaction{}, storageLayer.ErrNotFound.New("transaction not found, id = %d", id)
+	}
+	if err != nil {
+		return models.Transaction{}, handleError(err, "id = %d", id)
+	}
+
+	transaction, err := dbTransaction.ToDomain(ts.currency)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+	}
+
+	return transaction, nil
+}
+
+func (ts TransactionStorage) Insert(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	newDBTransaction, err := sqlitemodels.TransactionFromDomain(transaction)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrInvalid.Wrap(err, "sqlite.Transaction = %v", transaction)
+	}
+
+	if err := ts.inTx(ctx, func(tx *sql.Tx) error {
+		transaction, err = insertTransaction(ctx, tx, ts.currency, newDBTransaction)
+		return err
+	}); err != nil {
+		return models.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+func (ts TransactionStorage) GetChainHead(ctx context.Context) (models.Transaction, error) {
+	var dbTransaction sqlitemodels.Transaction
+
+	// SELECT ... FROM dbTransaction.TableName() ORDER BY id DESC LIMIT 1
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbTransaction.Fields())...),
+		sm.From(dbTransaction.TableName()),
+		sm.OrderBy(sqlite.Quote("id")).Desc(),
+		sm.Limit(1),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	err = ts.db.QueryRowContext(ctx, stmt, args...).Scan(dbTransaction.Pointers()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Transaction{}, storageLayer.ErrNotFound.New("transactions chain is empty")
+	}
+	if err != nil {
+		return models.Transaction{}, handleError(err, "error on get chain head")
+	}
+
+	transaction, err := dbTransaction.ToDomain(ts.currency)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+	}
+
+	return transaction, nil
+}
+
+func (ts TransactionStorage) IterateChain(ctx context.Context, yield func(models.Transaction) error) error {
+	var dbTransaction sqlitemodels.Transaction
+
+	// SELECT ... FROM dbTransaction.TableName() ORDER BY id
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbTransaction.Fields())...),
+		sm.From(dbTransaction.TableName()),
+		sm.OrderBy(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	rows, err := ts.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return handleError(err, "error on iterate chain")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(dbTransaction.Pointers()...); err != nil {
+			return storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+		}
+
+		transaction, err := dbTransaction.ToDomain(ts.currency)
+		if err != nil {
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Transaction = %v", dbTransaction)
+		}
+		if err := yield(transaction); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return handleError(err, "error on iterate chain")
+	}
+
+	return nil
+}
+
+func (ts TransactionStorage) Transfer(ctx context.Context, transaction models.Transaction) (models.Transaction, models.TransferBalances, error) {
+	newDBTransaction, err := sqlitemodels.TransactionFromDomain(transaction)
+	if err != nil {
+		return models.Transaction{}, models.TransferBalances{}, storageLayer.ErrInvalid.Wrap(err, "sqlite.Transaction = %v", transaction)
+	}
+	newDBTransaction.Successful = true
+
+	var balances models.TransferBalances
+
+	// transaction holds write lock from begin, so balance
+	// can't be changed between check and update
+	if err := ts.inTx(ctx, func(tx *sql.Tx) error {
+		from, err := getWalletByAddress(ctx, tx, ts.currency, newDBTransaction.FromAddress)
+		if err != nil {
+			return err
+		}
+		to, err := getWalletByAddress(ctx, tx, ts.currency, newDBTransaction.ToAddress)
+		if err != nil {
+			return err
+		}
+
+		if from.Frozen || to.Frozen {
+			return storageLayer.ErrFrozen.New("from = %s, to = %s", from.Address, to.Address)
+		}
+
+		// decimal arithmetic is done here, as sqlite would compare text or lose precision in REAL
+		fromBalance, err := from.Balance.Sub(transaction.Amount)
+		if err != nil {
+			return storageLayer.BalanceErr(err, from.Address)
+		}
+		if err := updateBalance(ctx, tx, from.Address, fromBalance); err != nil {
+			return err
+		}
+		balances.From = fromBalance
+
+		// re-read in case of transfer to the same wallet
+		to, err = getWalletByAddress(ctx, tx, ts.currency, to.Address)
+		if err != nil {
+			return err
+		}
+		if balances.To, err = to.Balance.Add(transaction.Amount); err != nil {
+			return storageLayer.BalanceErr(err, to.Address)
+		}
+		if err := updateBalance(ctx, tx, to.Address, balances.To); err != nil {
+			return err
+		}
+		// transfer to the same wallet leaves its balance as is
+		if from.Address == to.Address {
+			balances.From = balances.To
+		}
+
+		transaction, err = insertTransaction(ctx, tx, ts.currency, newDBTransaction)
+		return err
+	}); err != nil {
+		return models.Transaction{}, models.TransferBalances{}, err
+	}
+
+	return transaction, balances, nil
+}
+
+// execQuerier is common interface of sql.DB and sql.Tx
+type execQuerier interface {
+	querier
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// chainHeadHashQuery selects hash of the last transaction, that is NULL
+// if it was inserted before chain was introduced
+const chainHeadHashQuery = `SELECT hash FROM transactions ORDER BY id DESC LIMIT 1`
+
+// insertTransaction inserts transaction chained to the last one. q must be write
+// transaction, that holds lock from begin, so chain can't be forked by concurrent insert
+func insertTransaction(ctx context.Context, q execQuerier, currency money.Currency, newDBTransaction sqlitemodels.Transaction) (models.Transaction, error) {
+	var prevHash []byte
+	err := q.QueryRowContext(ctx, chainHeadHashQuery).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.Transaction{}, handleError(err, "error on get chain head")
+	}
+	newDBTransaction.PrevHash = prevHash
+	newDBTransaction.Hash = nil
+
+	// INSERT INTO newDBTransaction.TableName() VALUES newDBTransaction.ValuesWithoutID() RETURNING id
+	cte := sqlite.Insert(
+		im.Into(newDBTransaction.TableName(), newDBTransaction.FieldsWithoutID()...),
+		im.Values(sqlite.Arg(newDBTransaction.ValuesWithoutID()...)),
+		im.Returning(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if err := q.QueryRowContext(ctx, stmt, args...).Scan(&newDBTransaction.ID); err != nil {
+		return models.Transaction{}, handleError(err, "error on insert transaction")
+	}
+
+	transaction, err := newDBTransaction.ToDomain(currency)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Transaction = %v", newDBTransaction)
+	}
+
+	// hash covers id, so it's known only after insert
+	transaction.Hash = transaction.ChainHash(prevHash)
+
+	// UPDATE transactions SET hash = ? WHERE id = ?
+	hashCte := sqlite.Update(
+		um.Table(newDBTransaction.TableName()),
+		um.SetCol("hash").ToArg(transaction.Hash),
+		um.Where(sqlite.Quote("id").EQ(sqlite.Arg(transaction.ID))),
+	)
+	hashStmt, hashArgs, err := hashCte.Build(ctx)
+	if err != nil {
+		return models.Transaction{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if _, err := q.ExecContext(ctx, hashStmt, hashArgs...); err != nil {
+		return models.Transaction{}, handleError(err, "error on chain transaction, id = %d", transaction.ID)
+	}
+
+	return transaction, nil
+}
+
+func updateBalance(ctx context.Context, q execQuerier, address string, balance models.Balance) error {
+	// UPDATE wallets SET balance = ? WHERE address = ?
+	cte := sqlite.Update(
+		um.Table(sqlitemodels.Wallet{}.TableName()),
+		um.SetCol("balance").ToArg(sqlitemodels.BalanceFromDomain(balance)),
+		um.Where(sqlite.Quote("address").EQ(sqlite.Arg(address))),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	if _, err := q.ExecContext(ctx, stmt, args...); err != nil {
+		return handleError(err, "address = %s", address)
+	}
+
+	return nil
+}
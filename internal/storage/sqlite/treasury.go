@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/stephenafamo/bob/dialect/sqlite"
+	"github.com/stephenafamo/bob/dialect/sqlite/im"
+	"github.com/stephenafamo/bob/dialect/sqlite/sm"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	sqlitemodels "github.com/lunn06/wallet/internal/storage/sqlite/models"
+)
+
+type TreasuryStorage struct {
+	*Storage
+}
+
+var _ storageLayer.TreasuryStorage = TreasuryStorage{}
+
+func (ts TreasuryStorage) Apply(ctx context.Context, operation models.TreasuryOperation) (models.TreasuryOperation, error) {
+	if operation.Kind != models.TreasuryMint && operation.Kind != models.TreasuryBurn {
+		return models.TreasuryOperation{}, storageLayer.ErrInvalid.New("unknown treasury operation %q", operation.Kind)
+	}
+
+	// transaction holds write lock from begin, so balance
+	// can't be changed between check and update
+	if err := ts.inTx(ctx, func(tx *sql.Tx) error {
+		treasury, err := getWalletByAddress(ctx, tx, ts.currency, models.TreasuryAddress)
+		if storageLayer.IsNotFoundErr(err) {
+			// initial snapshot shares timestamp with operation one to not shadow it in history
+			treasury, err = insertWallet(ctx, tx, ts.currency, sqlitemodels.Wallet{Address: models.TreasuryAddress}, operation.Timestamp)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch operation.Kind {
+		case models.TreasuryMint:
+			operation.Balance, err = treasury.Balance.Add(operation.Amount)
+		case models.TreasuryBurn:
+			operation.Balance, err = treasury.Balance.Sub(operation.Amount)
+		}
+		if err != nil {
+			return storageLayer.BalanceErr(err, treasury.Address)
+		}
+		if err := updateBalance(ctx, tx, treasury.Address, operation.Balance); err != nil {
+			return err
+		}
+
+		if _, err := insertSnapshot(ctx, tx, ts.currency, sqlitemodels.Snapshot{
+			Address:   treasury.Address,
+			Balance:   sqlitemodels.BalanceFromDomain(operation.Balance),
+			Timestamp: operation.Timestamp.UnixNano(),
+		}); err != nil {
+			return err
+		}
+
+		newDBOperation := sqlitemodels.TreasuryOperationFromDomain(operation)
+
+		// INSERT INTO newDBOperation.TableName() VALUES newDBOperation.ValuesWithoutID() RETURNING id
+		cte := sqlite.Insert(
+			im.Into(newDBOperation.TableName(), newDBOperation.FieldsWithoutID()...),
+			im.Values(sqlite.Arg(newDBOperation.ValuesWithoutID()...)),
+			im.Returning(sqlite.Quote("id")),
+		)
+		stmt, args, err := cte.Build(ctx)
+		if err != nil {
+			return storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+		}
+
+		if err := tx.QueryRowContext(ctx, stmt, args...).Scan(&newDBOperation.ID); err != nil {
+			return handleError(err, "error on insert treasury operation")
+		}
+
+		operation, err = newDBOperation.ToDomain(ts.currency)
+		if err != nil {
+			return storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.TreasuryOperation = %v", newDBOperation)
+		}
+
+		return nil
+	}); err != nil {
+		return models.TreasuryOperation{}, err
+	}
+
+	return operation, nil
+}
+
+func (ts TreasuryStorage) List(ctx context.Context, afterID int, limit int) ([]models.TreasuryOperation, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	var dbOperation sqlitemodels.TreasuryOperation
+
+	// SELECT ... FROM dbOperation.TableName() WHERE id > ? ORDER BY id LIMIT ?
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbOperation.Fields())...),
+		sm.From(dbOperation.TableName()),
+		sm.Where(sqlite.Quote("id").GT(sqlite.Arg(afterID))),
+		sm.OrderBy(sqlite.Quote("id")),
+		sm.Limit(limit),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return nil, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	rows, err := ts.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, handleError(err, "error on list treasury operations")
+	}
+	defer rows.Close()
+
+	operations := make([]models.TreasuryOperation, 0, limit)
+	for rows.Next() {
+		if err := rows.Scan(dbOperation.Pointers()...); err != nil {
+			return nil, storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.TreasuryOperation = %v", dbOperation)
+		}
+
+		operation, err := dbOperation.ToDomain(ts.currency)
+		if err != nil {
+			return nil, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.TreasuryOperation = %v", dbOperation)
+		}
+		operations = append(operations, operation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, handleError(err, "error on list treasury operations")
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,335 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/stephenafamo/bob/dialect/sqlite"
+	"github.com/stephenafamo/bob/dialect/sqlite/im"
+	"github.com/stephenafamo/bob/dialect/sqlite/sm"
+	"github.com/stephenafamo/bob/dialect/sqlite/um"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	sqlitemodels "github.com/lunn06/wallet/internal/storage/sqlite/models"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+type WalletStorage struct {
+	*Storage
+}
+
+var _ storageLayer.WalletStorage = WalletStorage{}
+
+func (ws WalletStorage) GetByID(ctx context.Context, id int) (models.Wallet, error) {
+	var dbWallet sqlitemodels.Wallet
+
+	// SELECT id, address, balance FROM dbWallet.TableName() WHERE id = ? LIMIT 1
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbWallet.Fields())...),
+		sm.From(dbWallet.TableName()),
+		sm.Where(sqlite.Quote("id").EQ(sqlite.Arg(id))),
+		sm.Limit(1),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedStmtBuild.Wrap(err, "id = %d", id)
+	}
+
+	err = ws.db.QueryRowContext(ctx, stmt, args...).Scan(dbWallet.Pointers()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Wallet{}, storageLayer.ErrNotFound.New("wallet not found, id = %d", id)
+	}
+	if err != nil {
+		return models.Wallet{}, handleError(err, "id = %d", id)
+	}
+
+	wallet, err := dbWallet.ToDomain(ws.currency)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Wallet = %v", dbWallet)
+	}
+
+	return wallet, nil
+}
+
+func (ws WalletStorage) GetByAddress(ctx context.Context, address string) (models.Wallet, error) {
+	return getWalletByAddress(ctx, ws.db, ws.currency, address)
+}
+
+func (ws WalletStorage) List(ctx context.Context, afterID int, limit int) ([]models.Wallet, error) {
+	if limit < 1 {
+		return nil, storageLayer.ErrInvalid.New("limit must be greater than zero")
+	}
+
+	var dbWallet sqlitemodels.Wallet
+
+	// SELECT id, address, balance FROM dbWallet.TableName() WHERE id > ? ORDER BY id LIMIT ?
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbWallet.Fields())...),
+		sm.From(dbWallet.TableName()),
+		sm.Where(sqlite.Quote("id").GT(sqlite.Arg(afterID))),
+		sm.OrderBy(sqlite.Quote("id")),
+		sm.Limit(limit),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return nil, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	rows, err := ws.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, handleError(err, "error on list wallets")
+	}
+	defer rows.Close()
+
+	wallets := make([]models.Wallet, 0, limit)
+	for rows.Next() {
+		if err := rows.Scan(dbWallet.Pointers()...); err != nil {
+			return nil, storageLayer.ErrFailedToMarshal.Wrap(err, "sqlite.Wallet = %v", dbWallet)
+		}
+
+		wallet, err := dbWallet.ToDomain(ws.currency)
+		if err != nil {
+			return nil, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Wallet = %v", dbWallet)
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, handleError(err, "error on list wallets")
+	}
+
+	return wallets, nil
+}
+
+func (ws WalletStorage) Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error) {
+	newDBWallet, err := sqlitemodels.WalletFromDomain(wallet)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrInvalid.Wrap(err, "address = %s", wallet.Address)
+	}
+
+	if err := ws.inTx(ctx, func(tx *sql.Tx) error {
+		wallet, err = insertWallet(ctx, tx, ws.currency, newDBWallet, time.Now())
+		return err
+	}); err != nil {
+		return models.Wallet{}, err
+	}
+
+	return wallet, nil
+}
+
+func (ws WalletStorage) InsertIfMissing(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error) {
+	newDBWallet, err := sqlitemodels.WalletFromDomain(wallet)
+	if err != nil {
+		return models.Wallet{}, false, storageLayer.ErrInvalid.Wrap(err, "address = %s", wallet.Address)
+	}
+
+	// transaction holds write lock from begin, so wallet
+	// can't be inserted between select and insert
+	inserted := false
+	if err := ws.inTx(ctx, func(tx *sql.Tx) error {
+		stored, err := getWalletByAddress(ctx, tx, ws.currency, newDBWallet.Address)
+		if storageLayer.IsNotFoundErr(err) {
+			wallet, err = insertWallet(ctx, tx, ws.currency, newDBWallet, time.Now())
+			inserted = err == nil
+			return err
+		}
+		wallet = stored
+
+		return err
+	}); err != nil {
+		return models.Wallet{}, false, err
+	}
+
+	return wallet, inserted, nil
+}
+
+func (ws WalletStorage) Upsert(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error) {
+	newDBWallet, err := sqlitemodels.WalletFromDomain(wallet)
+	if err != nil {
+		return models.Wallet{}, false, storageLayer.ErrInvalid.Wrap(err, "address = %s", wallet.Address)
+	}
+
+	// transaction holds write lock from begin, so wallet
+	// can't be inserted or changed between select and write
+	inserted := false
+	if err := ws.inTx(ctx, func(tx *sql.Tx) error {
+		stored, err := getWalletByAddress(ctx, tx, ws.currency, newDBWallet.Address)
+		if storageLayer.IsNotFoundErr(err) {
+			wallet, err = insertWallet(ctx, tx, ws.currency, newDBWallet, time.Now())
+			inserted = err == nil
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if !stored.Balance.Equal(wallet.Balance) {
+			if err := updateBalance(ctx, tx, stored.Address, wallet.Balance); err != nil {
+				return err
+			}
+			if _, err := insertSnapshot(ctx, tx, ws.currency, sqlitemodels.Snapshot{
+				Address:   newDBWallet.Address,
+				Balance:   newDBWallet.Balance,
+				Timestamp: time.Now().UnixNano(),
+			}); err != nil {
+				return err
+			}
+			stored.Balance = wallet.Balance
+		}
+		wallet = stored
+
+		return nil
+	}); err != nil {
+		return models.Wallet{}, false, err
+	}
+
+	return wallet, inserted, nil
+}
+
+func (ws WalletStorage) UpdateBalance(ctx context.Context, changedWallet models.Wallet) error {
+	changedDBBalance := sqlitemodels.BalanceFromDomain(changedWallet.Balance)
+
+	// UPDATE wallets SET balance = ? WHERE id = ?
+	cte := sqlite.Update(
+		um.Table(sqlitemodels.Wallet{}.TableName()),
+		um.SetCol("balance").ToArg(changedDBBalance),
+		um.Where(sqlite.Quote("id").EQ(sqlite.Arg(changedWallet.ID))),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.Wrap(err, "id = %d", changedWallet.ID)
+	}
+
+	result, err := ws.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return handleError(err, "id = %d", changedWallet.ID)
+	}
+
+	// If zero rows affected it means that wallet not found
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return storageLayer.ErrNotFound.New("id = %d", changedWallet.ID)
+	}
+
+	return nil
+}
+
+func (ws WalletStorage) SetFrozen(ctx context.Context, address string, frozen bool) error {
+	normalized, err := sqlitemodels.NormalizeAddress(address)
+	if err != nil {
+		return storageLayer.ErrInvalid.Wrap(err, "address = %s", address)
+	}
+
+	// UPDATE wallets SET frozen = ? WHERE address = ?
+	cte := sqlite.Update(
+		um.Table(sqlitemodels.Wallet{}.TableName()),
+		um.SetCol("frozen").ToArg(frozen),
+		um.Where(sqlite.Quote("address").EQ(sqlite.Arg(normalized))),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	result, err := ws.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return handleError(err, "address = %s", address)
+	}
+
+	// If zero rows affected it means that wallet not found
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return storageLayer.ErrNotFound.New("address = %s", address)
+	}
+
+	return nil
+}
+
+// insertWallet inserts wallet with its initial snapshot, q must be transaction
+func insertWallet(ctx context.Context, q execQuerier, currency money.Currency, newDBWallet sqlitemodels.Wallet, created time.Time) (models.Wallet, error) {
+	// INSERT INTO newDBWallet.TableName() VALUES newDBWallet.ValuesWithoutID() RETURNING id
+	cte := sqlite.Insert(
+		im.Into(newDBWallet.TableName(), newDBWallet.FieldsWithoutID()...),
+		im.Values(sqlite.Arg(newDBWallet.ValuesWithoutID()...)),
+		im.Returning(sqlite.Quote("id")),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedStmtBuild.WrapWithNoMessage(err)
+	}
+
+	if err := q.QueryRowContext(ctx, stmt, args...).Scan(&newDBWallet.ID); err != nil {
+		return models.Wallet{}, handleError(err, "error on insert wallet")
+	}
+
+	// initial snapshot makes balance computable from the moment of creation
+	if _, err := insertSnapshot(ctx, q, currency, sqlitemodels.Snapshot{
+		Address:   newDBWallet.Address,
+		Balance:   newDBWallet.Balance,
+		Timestamp: created.UnixNano(),
+	}); err != nil {
+		return models.Wallet{}, err
+	}
+
+	wallet, err := newDBWallet.ToDomain(currency)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "error on insert wallet")
+	}
+
+	return wallet, nil
+}
+
+// getWalletByAddress selects wallet via q, that can be
+// sql.DB or sql.Tx, to share query with Transfer
+func getWalletByAddress(ctx context.Context, q querier, currency money.Currency, address string) (models.Wallet, error) {
+	normalized, err := sqlitemodels.NormalizeAddress(address)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrInvalid.Wrap(err, "address = %s", address)
+	}
+
+	var dbWallet sqlitemodels.Wallet
+
+	// SELECT id, address, balance FROM dbWallet.TableName() WHERE address = ? LIMIT 1
+	cte := sqlite.Select(
+		sm.Columns(quoteAll(dbWallet.Fields())...),
+		sm.From(dbWallet.TableName()),
+		sm.Where(sqlite.Quote("address").EQ(sqlite.Arg(normalized))),
+		sm.Limit(1),
+	)
+	stmt, args, err := cte.Build(ctx)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedStmtBuild.Wrap(err, "address = %s", address)
+	}
+
+	rows, err := q.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return models.Wallet{}, handleError(err, "address = %s", address)
+	}
+	defer rows.Close()
+
+	if ok := rows.Next(); !ok {
+		if err := rows.Err(); err != nil {
+			return models.Wallet{}, handleError(err, "address = %s", address)
+		}
+		return models.Wallet{}, storageLayer.ErrNotFound.New("address = %s", address)
+	}
+
+	if err := rows.Scan(dbWallet.Pointers()...); err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedToMarshal.Wrap(err, "address = %s", address)
+	}
+
+	wallet, err := dbWallet.ToDomain(currency)
+	if err != nil {
+		return models.Wallet{}, storageLayer.ErrFailedToUnmarshal.Wrap(err, "sqlite.Wallet = %v", dbWallet)
+	}
+
+	return wallet, nil
+}
+
+func quoteAll(columns []string) []any {
+	quoted := make([]any, len(columns))
+	for i, column := range columns {
+		quoted[i] = sqlite.Quote(column)
+	}
+	return quoted
+}
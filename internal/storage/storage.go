@@ -0,0 +1,91 @@
+// Package storage defines storage layer contract,
+// that every storage backend implements, and its errors
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+)
+
+// WalletStorage stores wallets. Missing wallets are reported by ErrNotFound,
+// duplicate addresses by ErrUniqueViolation and malformed addresses by ErrInvalid.
+// Insert also records initial Snapshot of wallet balance. InsertIfMissing inserts
+// wallet like Insert unless wallet with the same address exists, which is kept
+// unchanged, and returns stored wallet and whether it was inserted. Upsert is like
+// InsertIfMissing, but sets balance of existing wallet, recording Snapshot if it's changed.
+// List returns up to limit wallets with id greater than afterID ordered by id.
+// SetFrozen freezes or unfreezes wallet, Transfer refuses frozen wallets with ErrFrozen
+type WalletStorage interface {
+	GetByID(ctx context.Context, id int) (models.Wallet, error)
+	GetByAddress(ctx context.Context, address string) (models.Wallet, error)
+	List(ctx context.Context, afterID int, limit int) ([]models.Wallet, error)
+	Insert(ctx context.Context, wallet models.Wallet) (models.Wallet, error)
+	InsertIfMissing(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error)
+	Upsert(ctx context.Context, wallet models.Wallet) (models.Wallet, bool, error)
+	UpdateBalance(ctx context.Context, wallet models.Wallet) error
+	SetFrozen(ctx context.Context, address string, frozen bool) error
+}
+
+// TransactionStorage stores transactions between wallets.
+// Transfer atomically moves amount from one wallet to another and inserts
+// successful transaction, or changes nothing and returns ErrNotFound
+// for missing wallet and ErrInsufficientFunds for lack of balance.
+// Transfer also returns balances of wallets it has committed.
+// Insert and Transfer chain inserted transaction to the previous one
+// (see models.Transaction.ChainHash), so chain order is the order of ids
+type TransactionStorage interface {
+	GetByID(ctx context.Context, id int) (models.Transaction, error)
+	GetLastSuccessful(ctx context.Context, limit int) ([]models.Transaction, error)
+	Insert(ctx context.Context, transaction models.Transaction) (models.Transaction, error)
+	Transfer(ctx context.Context, transaction models.Transaction) (models.Transaction, models.TransferBalances, error)
+	// GetSuccessfulByAddress returns successful transactions from or to address
+	// with timestamp in (after, until] ordered by timestamp
+	GetSuccessfulByAddress(ctx context.Context, address string, after, until time.Time) ([]models.Transaction, error)
+	// IterateSuccessfulByAddress passes the same transactions to yield one by one
+	// without loading them into memory. Error of yield stops iteration and is returned as is
+	IterateSuccessfulByAddress(ctx context.Context, address string, after, until time.Time, yield func(models.Transaction) error) error
+	// GetChainHead returns transaction with the greatest id and ErrNotFound if there is none
+	GetChainHead(ctx context.Context) (models.Transaction, error)
+	// IterateChain passes all transactions to yield ordered by id
+	IterateChain(ctx context.Context, yield func(models.Transaction) error) error
+}
+
+// SnapshotStorage stores wallet balance snapshots.
+// GetLatest returns the newest snapshot of wallet taken at or before moment
+// and ErrNotFound if there is none. DeleteBefore deletes snapshots taken before
+// moment, except the newest of them per wallet, so balance at any moment after
+// it can still be computed, and returns count of deleted snapshots
+type SnapshotStorage interface {
+	Insert(ctx context.Context, snapshot models.Snapshot) (models.Snapshot, error)
+	GetLatest(ctx context.Context, address string, moment time.Time) (models.Snapshot, error)
+	DeleteBefore(ctx context.Context, moment time.Time) (int, error)
+}
+
+// CheckpointStorage stores signed checkpoints of transactions chain head.
+// GetLatest returns ErrNotFound if there are no checkpoints,
+// GetAll returns checkpoints ordered by id
+type CheckpointStorage interface {
+	Insert(ctx context.Context, checkpoint models.Checkpoint) (models.Checkpoint, error)
+	GetLatest(ctx context.Context) (models.Checkpoint, error)
+	GetAll(ctx context.Context) ([]models.Checkpoint, error)
+}
+
+// TreasuryStorage issues and retires funds of treasury wallet (models.TreasuryAddress),
+// that is created with zero balance on the first operation. Apply atomically changes
+// treasury balance by operation amount, records its balance snapshot and operation itself.
+// Burn of more than treasury balance returns ErrInsufficientFunds.
+// List returns up to limit operations with id greater than afterID ordered by id
+type TreasuryStorage interface {
+	Apply(ctx context.Context, operation models.TreasuryOperation) (models.TreasuryOperation, error)
+	List(ctx context.Context, afterID int, limit int) ([]models.TreasuryOperation, error)
+}
+
+// AuditStorage stores append-only audit records, stored records
+// are never changed or deleted. List returns up to limit records
+// matching filter with id greater than afterID ordered by id
+type AuditStorage interface {
+	Insert(ctx context.Context, record models.AuditRecord) (models.AuditRecord, error)
+	List(ctx context.Context, filter models.AuditFilter, afterID int, limit int) ([]models.AuditRecord, error)
+}
@@ -0,0 +1,1143 @@
+// Package storagetest implements conformance suite, that every storage
+// backend must pass to be interchangeable with others
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/domain/models"
+	storageLayer "github.com/lunn06/wallet/internal/storage"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+// Currency is currency of storages under test, its scale fits every amount of suite
+var Currency = money.Currency{Code: "XTS", Scale: 9}
+
+// Storages are storages of one backend sharing the same data
+type Storages struct {
+	Wallet      storageLayer.WalletStorage
+	Transaction storageLayer.TransactionStorage
+	Snapshot    storageLayer.SnapshotStorage
+	Checkpoint  storageLayer.CheckpointStorage
+	Treasury    storageLayer.TreasuryStorage
+	Audit       storageLayer.AuditStorage
+}
+
+// Factory returns storages of backend under test. Storages may contain
+// data of other tests, so suite creates its own wallets with random addresses
+type Factory func(t *testing.T) Storages
+
+// Run runs conformance suite against storages created by newStorages
+func Run(t *testing.T, newStorages Factory) {
+	t.Run("wallet", func(t *testing.T) {
+		t.Run("insert and get", func(t *testing.T) { testWalletInsertAndGet(t, newStorages) })
+		t.Run("insert errors", func(t *testing.T) { testWalletInsertErrors(t, newStorages) })
+		t.Run("get errors", func(t *testing.T) { testWalletGetErrors(t, newStorages) })
+		t.Run("update balance", func(t *testing.T) { testWalletUpdateBalance(t, newStorages) })
+		t.Run("list", func(t *testing.T) { testWalletList(t, newStorages) })
+		t.Run("set frozen", func(t *testing.T) { testWalletSetFrozen(t, newStorages) })
+		t.Run("insert if missing", func(t *testing.T) { testWalletInsertIfMissing(t, newStorages) })
+		t.Run("upsert", func(t *testing.T) { testWalletUpsert(t, newStorages) })
+	})
+	t.Run("transaction", func(t *testing.T) {
+		t.Run("insert and get", func(t *testing.T) { testTransactionInsertAndGet(t, newStorages) })
+		t.Run("insert errors", func(t *testing.T) { testTransactionInsertErrors(t, newStorages) })
+		t.Run("get last successful", func(t *testing.T) { testGetLastSuccessful(t, newStorages) })
+		t.Run("transfer", func(t *testing.T) { testTransfer(t, newStorages) })
+		t.Run("transfer errors", func(t *testing.T) { testTransferErrors(t, newStorages) })
+		t.Run("concurrent transfers", func(t *testing.T) { testConcurrentTransfers(t, newStorages) })
+		t.Run("get successful by address", func(t *testing.T) { testGetSuccessfulByAddress(t, newStorages) })
+		t.Run("iterate successful by address", func(t *testing.T) { testIterateSuccessfulByAddress(t, newStorages) })
+	})
+	t.Run("snapshot", func(t *testing.T) {
+		t.Run("initial snapshot", func(t *testing.T) { testInitialSnapshot(t, newStorages) })
+		t.Run("insert and get latest", func(t *testing.T) { testSnapshotGetLatest(t, newStorages) })
+		t.Run("insert errors", func(t *testing.T) { testSnapshotInsertErrors(t, newStorages) })
+		t.Run("delete before", func(t *testing.T) { testSnapshotDeleteBefore(t, newStorages) })
+	})
+	t.Run("chain", func(t *testing.T) {
+		t.Run("insert and transfer are chained", func(t *testing.T) { testChain(t, newStorages) })
+		t.Run("iterate chain", func(t *testing.T) { testIterateChain(t, newStorages) })
+		t.Run("checkpoints", func(t *testing.T) { testCheckpoints(t, newStorages) })
+	})
+	t.Run("treasury", func(t *testing.T) {
+		t.Run("mint and burn", func(t *testing.T) { testTreasuryApply(t, newStorages) })
+		t.Run("list", func(t *testing.T) { testTreasuryList(t, newStorages) })
+	})
+	t.Run("audit", func(t *testing.T) {
+		t.Run("insert and list", func(t *testing.T) { testAuditInsertAndList(t, newStorages) })
+		t.Run("filters", func(t *testing.T) { testAuditFilters(t, newStorages) })
+	})
+}
+
+func balance(t *testing.T, s string) models.Balance {
+	t.Helper()
+
+	b, err := models.NewBalanceFromString(s, Currency)
+	require.NoError(t, err)
+
+	return b
+}
+
+func add(t *testing.T, a, b models.Balance) models.Balance {
+	t.Helper()
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+
+	return sum
+}
+
+func insertWallet(t *testing.T, ws storageLayer.WalletStorage, b string) models.Wallet {
+	t.Helper()
+
+	wallet, err := ws.Insert(context.Background(), models.Wallet{
+		Address: uuid.NewString(),
+		Balance: balance(t, b),
+	})
+	require.NoError(t, err)
+
+	return wallet
+}
+
+func getBalance(t *testing.T, ws storageLayer.WalletStorage, id int) models.Balance {
+	t.Helper()
+
+	wallet, err := ws.GetByID(context.Background(), id)
+	require.NoError(t, err)
+
+	return wallet.Balance
+}
+
+// assertWalletEqual compares balances by value, as their inner representation may differ after scan
+func assertWalletEqual(t *testing.T, expected, actual models.Wallet) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.Address, actual.Address)
+	assertBalanceEqual(t, expected.Balance, actual.Balance)
+}
+
+// assertTransactionEqual compares timestamps with database precision
+func assertTransactionEqual(t *testing.T, expected, actual models.Transaction) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.FromAddress, actual.FromAddress)
+	assert.Equal(t, expected.ToAddress, actual.ToAddress)
+	assertBalanceEqual(t, expected.Amount, actual.Amount)
+	assert.WithinDuration(t, expected.Timestamp, actual.Timestamp, time.Microsecond)
+	assert.Equal(t, expected.Successful, actual.Successful)
+	assert.Equal(t, expected.PrevHash, actual.PrevHash)
+	assert.Equal(t, expected.Hash, actual.Hash)
+}
+
+func assertBalanceEqual(t *testing.T, expected, actual models.Balance) {
+	t.Helper()
+
+	assert.True(t, expected.Equal(actual), "balance %s != %s", expected, actual)
+}
+
+func assertErrorOfType(t *testing.T, err error, errType *errorx.Type) {
+	t.Helper()
+
+	assert.True(t, errorx.IsOfType(err, errType), "expected %s error, got %v", errType, err)
+}
+
+func testWalletInsertAndGet(t *testing.T, newStorages Factory) {
+	ws := newStorages(t).Wallet
+
+	address := uuid.NewString()
+	inserted, err := ws.Insert(context.Background(), models.Wallet{
+		Address: strings.ToUpper(address),
+		Balance: balance(t, "10.123456789"),
+	})
+	require.NoError(t, err)
+	assert.Positive(t, inserted.ID)
+	assert.Equal(t, address, inserted.Address, "address must be normalized")
+	assertBalanceEqual(t, balance(t, "10.123456789"), inserted.Balance)
+
+	byID, err := ws.GetByID(context.Background(), inserted.ID)
+	require.NoError(t, err)
+	assertWalletEqual(t, inserted, byID)
+
+	byAddress, err := ws.GetByAddress(context.Background(), address)
+	require.NoError(t, err)
+	assertWalletEqual(t, inserted, byAddress)
+
+	other := insertWallet(t, ws, "0")
+	assert.NotEqual(t, inserted.ID, other.ID)
+}
+
+func testWalletInsertErrors(t *testing.T, newStorages Factory) {
+	ws := newStorages(t).Wallet
+
+	wallet := insertWallet(t, ws, "1")
+
+	_, err := ws.Insert(context.Background(), models.Wallet{Address: wallet.Address})
+	assert.True(t, storageLayer.IsDuplicateErr(err), "expected duplicate error, got %v", err)
+
+	_, err = ws.Insert(context.Background(), models.Wallet{Address: "wrong"})
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testWalletGetErrors(t *testing.T, newStorages Factory) {
+	ws := newStorages(t).Wallet
+
+	_, err := ws.GetByID(context.Background(), -1)
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+
+	_, err = ws.GetByAddress(context.Background(), uuid.NewString())
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+
+	_, err = ws.GetByAddress(context.Background(), "wrong")
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testWalletUpdateBalance(t *testing.T, newStorages Factory) {
+	ws := newStorages(t).Wallet
+
+	wallet := insertWallet(t, ws, "1")
+
+	wallet.Balance = balance(t, "2.5")
+	require.NoError(t, ws.UpdateBalance(context.Background(), wallet))
+	assertBalanceEqual(t, wallet.Balance, getBalance(t, ws, wallet.ID))
+
+	wallet.ID = -1
+	err := ws.UpdateBalance(context.Background(), wallet)
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+}
+
+func testWalletSetFrozen(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	from := insertWallet(t, ws, "1")
+	to := insertWallet(t, ws, "1")
+
+	require.NoError(t, ws.SetFrozen(context.Background(), strings.ToUpper(to.Address), true))
+	got, err := ws.GetByAddress(context.Background(), to.Address)
+	require.NoError(t, err)
+	assert.True(t, got.Frozen)
+
+	// frozen wallet can neither receive nor send
+	for _, pair := range [][2]models.Wallet{{from, to}, {to, from}} {
+		_, _, err = ts.Transfer(context.Background(), models.Transaction{
+			FromAddress: pair[0].Address,
+			ToAddress:   pair[1].Address,
+			Amount:      balance(t, "0.5"),
+			Timestamp:   time.Now().UTC(),
+		})
+		assert.True(t, storageLayer.IsFrozenErr(err), "expected frozen error, got %v", err)
+	}
+	assertBalanceEqual(t, from.Balance, getBalance(t, ws, from.ID))
+	assertBalanceEqual(t, to.Balance, getBalance(t, ws, to.ID))
+
+	require.NoError(t, ws.SetFrozen(context.Background(), to.Address, false))
+	_, _, err = ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: from.Address,
+		ToAddress:   to.Address,
+		Amount:      balance(t, "0.5"),
+		Timestamp:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	err = ws.SetFrozen(context.Background(), uuid.NewString(), true)
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+	err = ws.SetFrozen(context.Background(), "not-uuid", true)
+	assert.True(t, storageLayer.IsExternalErr(err), "expected external error, got %v", err)
+}
+
+func testWalletInsertIfMissing(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ss := storages.Wallet, storages.Snapshot
+
+	address := uuid.NewString()
+	inserted, created, err := ws.InsertIfMissing(context.Background(), models.Wallet{
+		Address: strings.ToUpper(address),
+		Balance: balance(t, "5"),
+	})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Positive(t, inserted.ID)
+	assert.Equal(t, address, inserted.Address)
+	assertBalanceEqual(t, balance(t, "5"), inserted.Balance)
+
+	snapshot, err := ss.GetLatest(context.Background(), address, time.Now())
+	require.NoError(t, err)
+	assertBalanceEqual(t, balance(t, "5"), snapshot.Balance)
+
+	// balance of existing wallet is never changed
+	existing, created, err := ws.InsertIfMissing(context.Background(), models.Wallet{Address: address, Balance: balance(t, "7.25")})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, inserted.ID, existing.ID)
+	assertBalanceEqual(t, balance(t, "5"), existing.Balance)
+	assertBalanceEqual(t, balance(t, "5"), getBalance(t, ws, inserted.ID))
+
+	_, _, err = ws.InsertIfMissing(context.Background(), models.Wallet{Address: "not-uuid"})
+	assert.True(t, storageLayer.IsExternalErr(err), "expected external error, got %v", err)
+}
+
+func testWalletUpsert(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ss := storages.Wallet, storages.Snapshot
+
+	address := uuid.NewString()
+	inserted, created, err := ws.Upsert(context.Background(), models.Wallet{
+		Address: strings.ToUpper(address),
+		Balance: balance(t, "5"),
+	})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Positive(t, inserted.ID)
+	assert.Equal(t, address, inserted.Address)
+	assertBalanceEqual(t, balance(t, "5"), inserted.Balance)
+
+	// the same balance changes nothing
+	same, created, err := ws.Upsert(context.Background(), models.Wallet{Address: address, Balance: balance(t, "5.00")})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, inserted.ID, same.ID)
+
+	updated, created, err := ws.Upsert(context.Background(), models.Wallet{Address: address, Balance: balance(t, "7.25")})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, inserted.ID, updated.ID)
+	assertBalanceEqual(t, balance(t, "7.25"), updated.Balance)
+	assertBalanceEqual(t, balance(t, "7.25"), getBalance(t, ws, inserted.ID))
+
+	// changed balance is snapshotted to keep balance history consistent
+	snapshot, err := ss.GetLatest(context.Background(), address, time.Now())
+	require.NoError(t, err)
+	assertBalanceEqual(t, balance(t, "7.25"), snapshot.Balance)
+
+	_, _, err = ws.Upsert(context.Background(), models.Wallet{Address: "not-uuid"})
+	assert.True(t, storageLayer.IsExternalErr(err), "expected external error, got %v", err)
+}
+
+func testTransactionInsertAndGet(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	from := insertWallet(t, ws, "1")
+	to := insertWallet(t, ws, "1")
+
+	inserted, err := ts.Insert(context.Background(), models.Transaction{
+		FromAddress: strings.ToUpper(from.Address),
+		ToAddress:   to.Address,
+		Amount:      balance(t, "0.5"),
+		Timestamp:   time.Now().UTC(),
+		Successful:  false,
+	})
+	require.NoError(t, err)
+	assert.Positive(t, inserted.ID)
+	assert.Equal(t, from.Address, inserted.FromAddress, "address must be normalized")
+
+	got, err := ts.GetByID(context.Background(), inserted.ID)
+	require.NoError(t, err)
+	assertTransactionEqual(t, inserted, got)
+
+	// insert doesn't change balances
+	assertBalanceEqual(t, from.Balance, getBalance(t, ws, from.ID))
+	assertBalanceEqual(t, to.Balance, getBalance(t, ws, to.ID))
+
+	_, err = ts.GetByID(context.Background(), -1)
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+}
+
+func testTransactionInsertErrors(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	wallet := insertWallet(t, ws, "1")
+
+	_, err := ts.Insert(context.Background(), models.Transaction{
+		FromAddress: wallet.Address,
+		ToAddress:   uuid.NewString(),
+		Amount:      balance(t, "0.5"),
+		Timestamp:   time.Now().UTC(),
+	})
+	assert.True(t, storageLayer.IsNotFoundErr(err), "transaction must reference existing wallets, got %v", err)
+
+	_, err = ts.Insert(context.Background(), models.Transaction{
+		FromAddress: "wrong",
+		ToAddress:   wallet.Address,
+		Amount:      balance(t, "0.5"),
+		Timestamp:   time.Now().UTC(),
+	})
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testGetLastSuccessful(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	from := insertWallet(t, ws, "1")
+	to := insertWallet(t, ws, "1")
+
+	// far future timestamps put these transactions before ones of other tests
+	base := time.Now().UTC().AddDate(100, 0, 0).Truncate(time.Microsecond)
+	var inserted []models.Transaction
+	for i, successful := range []bool{true, false, true, true} {
+		tr, err := ts.Insert(context.Background(), models.Transaction{
+			FromAddress: from.Address,
+			ToAddress:   to.Address,
+			Amount:      balance(t, "0.1"),
+			Timestamp:   base.Add(time.Duration(i) * time.Second),
+			Successful:  successful,
+		})
+		require.NoError(t, err)
+		inserted = append(inserted, tr)
+	}
+
+	last, err := ts.GetLastSuccessful(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, last, 2)
+	assertTransactionEqual(t, inserted[3], last[0])
+	assertTransactionEqual(t, inserted[2], last[1])
+
+	last, err = ts.GetLastSuccessful(context.Background(), 3)
+	require.NoError(t, err)
+	require.Len(t, last, 3)
+	assertTransactionEqual(t, inserted[0], last[2])
+
+	for i := 1; i < len(last); i++ {
+		assert.False(t, last[i].Timestamp.After(last[i-1].Timestamp), "transactions must be sorted from newest")
+	}
+
+	_, err = ts.GetLastSuccessful(context.Background(), 0)
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testTransfer(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	from := insertWallet(t, ws, "10")
+	to := insertWallet(t, ws, "1.5")
+
+	transferred, balances, err := ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: from.Address,
+		ToAddress:   to.Address,
+		Amount:      balance(t, "9.99"),
+		Timestamp:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	assert.True(t, transferred.Successful, "transfer must insert successful transaction")
+	assertBalanceEqual(t, balance(t, "0.01"), balances.From)
+	assertBalanceEqual(t, balance(t, "11.49"), balances.To)
+
+	got, err := ts.GetByID(context.Background(), transferred.ID)
+	require.NoError(t, err)
+	assertTransactionEqual(t, transferred, got)
+
+	assertBalanceEqual(t, balance(t, "0.01"), getBalance(t, ws, from.ID))
+	assertBalanceEqual(t, balance(t, "11.49"), getBalance(t, ws, to.ID))
+
+	// whole balance can be transferred
+	_, _, err = ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: from.Address,
+		ToAddress:   to.Address,
+		Amount:      balance(t, "0.01"),
+		Timestamp:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	assertBalanceEqual(t, balance(t, "0"), getBalance(t, ws, from.ID))
+}
+
+func testTransferErrors(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	from := insertWallet(t, ws, "1")
+	to := insertWallet(t, ws, "1")
+
+	_, _, err := ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: from.Address,
+		ToAddress:   to.Address,
+		Amount:      balance(t, "1.000001"),
+		Timestamp:   time.Now().UTC(),
+	})
+	assert.True(t, storageLayer.IsInsufficientFundsErr(err), "expected insufficient funds error, got %v", err)
+
+	_, _, err = ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: from.Address,
+		ToAddress:   uuid.NewString(),
+		Amount:      balance(t, "0.5"),
+		Timestamp:   time.Now().UTC(),
+	})
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+
+	// failed transfers change nothing
+	assertBalanceEqual(t, from.Balance, getBalance(t, ws, from.ID))
+	assertBalanceEqual(t, to.Balance, getBalance(t, ws, to.ID))
+}
+
+// testConcurrentTransfers checks, that money is neither lost nor created
+// and balance never becomes negative under concurrent transfers
+func testConcurrentTransfers(t *testing.T, newStorages Factory) {
+	const (
+		wallets   = 4
+		transfers = 100
+	)
+
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	var created []models.Wallet
+	for range wallets {
+		created = append(created, insertWallet(t, ws, "5"))
+	}
+
+	amount := balance(t, "1")
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		successful int
+	)
+	for i := range transfers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// pairs in both directions to provoke deadlocks
+			from, to := created[i%wallets], created[(i+1+i/wallets)%wallets]
+			if from.ID == to.ID {
+				to = created[(i+1)%wallets]
+			}
+
+			_, _, err := ts.Transfer(context.Background(), models.Transaction{
+				FromAddress: from.Address,
+				ToAddress:   to.Address,
+				Amount:      amount,
+				Timestamp:   time.Now().UTC(),
+			})
+			if err != nil {
+				assert.True(t, storageLayer.IsInsufficientFundsErr(err), "unexpected error %v", err)
+				return
+			}
+
+			mu.Lock()
+			successful++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	total := balance(t, "0")
+	for _, wallet := range created {
+		b := getBalance(t, ws, wallet.ID)
+		total = add(t, total, b)
+	}
+	assertBalanceEqual(t, balance(t, "20"), total)
+	assert.Positive(t, successful)
+}
+
+func testWalletList(t *testing.T, newStorages Factory) {
+	ws := newStorages(t).Wallet
+
+	var inserted []models.Wallet
+	for range 3 {
+		inserted = append(inserted, insertWallet(t, ws, "1"))
+	}
+
+	listed, err := ws.List(context.Background(), inserted[0].ID-1, 2)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assertWalletEqual(t, inserted[0], listed[0])
+	assertWalletEqual(t, inserted[1], listed[1])
+
+	listed, err = ws.List(context.Background(), inserted[1].ID, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, listed)
+	assertWalletEqual(t, inserted[2], listed[0])
+
+	_, err = ws.List(context.Background(), 0, 0)
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testGetSuccessfulByAddress(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	a := insertWallet(t, ws, "1")
+	b := insertWallet(t, ws, "1")
+	c := insertWallet(t, ws, "1")
+
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	insert := func(from, to models.Wallet, offset time.Duration, successful bool) models.Transaction {
+		tr, err := ts.Insert(context.Background(), models.Transaction{
+			FromAddress: from.Address,
+			ToAddress:   to.Address,
+			Amount:      balance(t, "0.1"),
+			Timestamp:   base.Add(offset),
+			Successful:  successful,
+		})
+		require.NoError(t, err)
+		return tr
+	}
+	t1 := insert(a, b, time.Second, true)
+	insert(b, a, 2*time.Second, false)
+	t3 := insert(c, a, 3*time.Second, true)
+	insert(b, c, 4*time.Second, true)
+
+	got, err := ts.GetSuccessfulByAddress(context.Background(), a.Address, base, base.Add(3*time.Second))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assertTransactionEqual(t, t1, got[0])
+	assertTransactionEqual(t, t3, got[1])
+
+	// after is exclusive, until is inclusive
+	got, err = ts.GetSuccessfulByAddress(context.Background(), a.Address, base.Add(time.Second), base.Add(4*time.Second))
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assertTransactionEqual(t, t3, got[0])
+
+	got, err = ts.GetSuccessfulByAddress(context.Background(), a.Address, base.Add(3*time.Second), base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = ts.GetSuccessfulByAddress(context.Background(), "wrong", base, base.Add(time.Hour))
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testIterateSuccessfulByAddress(t *testing.T, newStorages Factory) {
+	// more than two batches of cursor based implementations
+	const count = 1001
+
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	a := insertWallet(t, ws, "1")
+	b := insertWallet(t, ws, "1")
+
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	for i := range count {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		_, err := ts.Insert(context.Background(), models.Transaction{
+			FromAddress: from.Address,
+			ToAddress:   to.Address,
+			Amount:      balance(t, "0.1"),
+			Timestamp:   base.Add(time.Duration(count-i) * time.Millisecond),
+			Successful:  true,
+		})
+		require.NoError(t, err)
+	}
+
+	var got []models.Transaction
+	err := ts.IterateSuccessfulByAddress(context.Background(), a.Address, base, base.Add(time.Hour), func(tr models.Transaction) error {
+		got = append(got, tr)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, count)
+	assert.True(t, slices.IsSortedFunc(got, func(x, y models.Transaction) int {
+		return x.Timestamp.Compare(y.Timestamp)
+	}), "transactions must be ordered by timestamp")
+
+	expected, err := ts.GetSuccessfulByAddress(context.Background(), a.Address, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	for i := range expected {
+		assertTransactionEqual(t, expected[i], got[i])
+	}
+
+	// error of yield stops iteration and is returned as is
+	stop := errors.New("stop")
+	yielded := 0
+	err = ts.IterateSuccessfulByAddress(context.Background(), a.Address, base, base.Add(time.Hour), func(models.Transaction) error {
+		yielded++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, yielded)
+
+	err = ts.IterateSuccessfulByAddress(context.Background(), "wrong", base, base.Add(time.Hour), func(models.Transaction) error {
+		return nil
+	})
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testInitialSnapshot(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+
+	before := time.Now()
+	wallet := insertWallet(t, storages.Wallet, "5.5")
+
+	snapshot, err := storages.Snapshot.GetLatest(context.Background(), wallet.Address, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, wallet.Address, snapshot.Address)
+	assertBalanceEqual(t, wallet.Balance, snapshot.Balance)
+	assert.WithinDuration(t, before, snapshot.Timestamp, 5*time.Second)
+
+	_, err = storages.Snapshot.GetLatest(context.Background(), wallet.Address, before.Add(-time.Hour))
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+}
+
+// insertSnapshots inserts snapshots with balances 1, 2, 3... at base + i seconds
+func insertSnapshots(t *testing.T, ss storageLayer.SnapshotStorage, address string, base time.Time, count int) []models.Snapshot {
+	t.Helper()
+
+	var snapshots []models.Snapshot
+	for i := 1; i <= count; i++ {
+		snapshot, err := ss.Insert(context.Background(), models.Snapshot{
+			Address:   address,
+			Balance:   balance(t, strconv.Itoa(i)),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+func testSnapshotGetLatest(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ss := storages.Snapshot
+
+	wallet := insertWallet(t, storages.Wallet, "0")
+	base := time.Now().UTC().Add(time.Minute).Truncate(time.Microsecond)
+	inserted := insertSnapshots(t, ss, strings.ToUpper(wallet.Address), base, 3)
+	assert.Equal(t, wallet.Address, inserted[0].Address, "address must be normalized")
+
+	got, err := ss.GetLatest(context.Background(), wallet.Address, base.Add(2500*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, inserted[1].ID, got.ID)
+	assertBalanceEqual(t, inserted[1].Balance, got.Balance)
+	assert.WithinDuration(t, inserted[1].Timestamp, got.Timestamp, time.Microsecond)
+
+	// moment is inclusive
+	got, err = ss.GetLatest(context.Background(), wallet.Address, base.Add(3*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, inserted[2].ID, got.ID)
+
+	_, err = ss.GetLatest(context.Background(), uuid.NewString(), base.Add(time.Hour))
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+}
+
+func testSnapshotInsertErrors(t *testing.T, newStorages Factory) {
+	ss := newStorages(t).Snapshot
+
+	_, err := ss.Insert(context.Background(), models.Snapshot{
+		Address:   uuid.NewString(),
+		Balance:   balance(t, "1"),
+		Timestamp: time.Now().UTC(),
+	})
+	assert.True(t, storageLayer.IsNotFoundErr(err), "snapshot must reference existing wallet, got %v", err)
+
+	_, err = ss.Insert(context.Background(), models.Snapshot{
+		Address:   "wrong",
+		Balance:   balance(t, "1"),
+		Timestamp: time.Now().UTC(),
+	})
+	assertErrorOfType(t, err, storageLayer.ErrInvalid)
+}
+
+func testSnapshotDeleteBefore(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ss := storages.Snapshot
+
+	wallet := insertWallet(t, storages.Wallet, "0")
+	base := time.Now().UTC().Add(time.Minute).Truncate(time.Microsecond)
+	inserted := insertSnapshots(t, ss, wallet.Address, base, 3)
+
+	// initial and the first inserted snapshots are deleted,
+	// the second is kept as the newest before moment
+	deleted, err := ss.DeleteBefore(context.Background(), base.Add(2500*time.Millisecond))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, 2)
+
+	_, err = ss.GetLatest(context.Background(), wallet.Address, base.Add(1500*time.Millisecond))
+	assert.True(t, storageLayer.IsNotFoundErr(err), "expected not found error, got %v", err)
+
+	got, err := ss.GetLatest(context.Background(), wallet.Address, base.Add(2500*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, inserted[1].ID, got.ID)
+
+	got, err = ss.GetLatest(context.Background(), wallet.Address, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, inserted[2].ID, got.ID)
+}
+
+func testChain(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	a := insertWallet(t, ws, "10")
+	b := insertWallet(t, ws, "10")
+
+	inserted, err := ts.Insert(context.Background(), models.Transaction{
+		FromAddress: a.Address,
+		ToAddress:   b.Address,
+		Amount:      balance(t, "100"),
+		Timestamp:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	head, err := ts.GetChainHead(context.Background())
+	require.NoError(t, err)
+	assertTransactionEqual(t, inserted, head)
+
+	transferred, _, err := ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: a.Address,
+		ToAddress:   b.Address,
+		Amount:      balance(t, "1.50"),
+		Timestamp:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, inserted.Hash, transferred.PrevHash)
+	assert.Len(t, transferred.Hash, 32)
+
+	// stored fields give the same hash
+	for _, tr := range []models.Transaction{inserted, transferred} {
+		stored, err := ts.GetByID(context.Background(), tr.ID)
+		require.NoError(t, err)
+		assertTransactionEqual(t, tr, stored)
+		assert.Equal(t, stored.Hash, stored.ChainHash(stored.PrevHash))
+	}
+
+	head, err = ts.GetChainHead(context.Background())
+	require.NoError(t, err)
+	assertTransactionEqual(t, transferred, head)
+
+	// failed transfer doesn't extend chain
+	_, _, err = ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: a.Address,
+		ToAddress:   b.Address,
+		Amount:      balance(t, "100"),
+		Timestamp:   time.Now(),
+	})
+	assertErrorOfType(t, err, storageLayer.ErrInsufficientFunds)
+
+	head, err = ts.GetChainHead(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, transferred.ID, head.ID)
+}
+
+func testIterateChain(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts := storages.Wallet, storages.Transaction
+
+	a := insertWallet(t, ws, "10")
+	b := insertWallet(t, ws, "10")
+	for range 3 {
+		_, _, err := ts.Transfer(context.Background(), models.Transaction{
+			FromAddress: a.Address,
+			ToAddress:   b.Address,
+			Amount:      balance(t, "1"),
+			Timestamp:   time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	var chain []models.Transaction
+	err := ts.IterateChain(context.Background(), func(tr models.Transaction) error {
+		chain = append(chain, tr)
+		return nil
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(chain), 3)
+
+	for i := 1; i < len(chain); i++ {
+		assert.Less(t, chain[i-1].ID, chain[i].ID)
+		if len(chain[i-1].Hash) > 0 {
+			assert.Equal(t, chain[i-1].Hash, chain[i].PrevHash)
+		}
+	}
+
+	stop := errors.New("stop")
+	err = ts.IterateChain(context.Background(), func(models.Transaction) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func testCheckpoints(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ts, cs := storages.Wallet, storages.Transaction, storages.Checkpoint
+
+	a := insertWallet(t, ws, "10")
+	b := insertWallet(t, ws, "10")
+	tr, _, err := ts.Transfer(context.Background(), models.Transaction{
+		FromAddress: a.Address,
+		ToAddress:   b.Address,
+		Amount:      balance(t, "1"),
+		Timestamp:   time.Now(),
+	})
+	require.NoError(t, err)
+
+	timestamp := time.Now().UTC().Truncate(time.Microsecond)
+	var inserted []models.Checkpoint
+	for i := range 2 {
+		checkpoint, err := cs.Insert(context.Background(), models.Checkpoint{
+			TransactionID: tr.ID,
+			Hash:          tr.Hash,
+			Timestamp:     timestamp.Add(time.Duration(i) * time.Second),
+			Signature:     []byte{byte(i), 1, 2, 3},
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, checkpoint.ID)
+		inserted = append(inserted, checkpoint)
+	}
+
+	latest, err := cs.GetLatest(context.Background())
+	require.NoError(t, err)
+	assertCheckpointEqual(t, inserted[1], latest)
+
+	all, err := cs.GetAll(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(all), 2)
+	assertCheckpointEqual(t, inserted[0], all[len(all)-2])
+	assertCheckpointEqual(t, inserted[1], all[len(all)-1])
+
+	_, err = cs.Insert(context.Background(), models.Checkpoint{
+		TransactionID: tr.ID + 1000000,
+		Hash:          tr.Hash,
+		Timestamp:     timestamp,
+		Signature:     []byte{1},
+	})
+	assertErrorOfType(t, err, storageLayer.ErrNotFound)
+}
+
+func assertCheckpointEqual(t *testing.T, expected, actual models.Checkpoint) {
+	t.Helper()
+
+	assert.Equal(t, expected.ID, actual.ID)
+	assert.Equal(t, expected.TransactionID, actual.TransactionID)
+	assert.Equal(t, expected.Hash, actual.Hash)
+	assert.True(t, expected.Timestamp.Equal(actual.Timestamp), "timestamp %s != %s", expected.Timestamp, actual.Timestamp)
+	assert.Equal(t, expected.Signature, actual.Signature)
+}
+
+// treasuryBalance returns current treasury balance, that is zero before
+// the first operation. Treasury is shared, so tests compare its changes
+func treasuryBalance(t *testing.T, ws storageLayer.WalletStorage) models.Balance {
+	t.Helper()
+
+	treasury, err := ws.GetByAddress(context.Background(), models.TreasuryAddress)
+	if storageLayer.IsNotFoundErr(err) {
+		return models.Balance{}
+	}
+	require.NoError(t, err)
+
+	return treasury.Balance
+}
+
+func testTreasuryApply(t *testing.T, newStorages Factory) {
+	storages := newStorages(t)
+	ws, ss, trs := storages.Wallet, storages.Snapshot, storages.Treasury
+
+	initial := treasuryBalance(t, ws)
+	timestamp := time.Now().UTC().Add(-time.Hour)
+
+	minted, err := trs.Apply(context.Background(), models.TreasuryOperation{
+		Kind:      models.TreasuryMint,
+		Amount:    balance(t, "10.5"),
+		Reason:    "initial issue",
+		Timestamp: timestamp,
+	})
+	require.NoError(t, err)
+	assert.Positive(t, minted.ID)
+	assert.Equal(t, models.TreasuryMint, minted.Kind)
+	assert.Equal(t, "initial issue", minted.Reason)
+	assertBalanceEqual(t, add(t, initial, balance(t, "10.5")), minted.Balance)
+	assertBalanceEqual(t, minted.Balance, treasuryBalance(t, ws))
+
+	// snapshot keeps balance history consistent with treasury balance,
+	// snapshot of treasury creation must not shadow it
+	snapshot, err := ss.GetLatest(context.Background(), models.TreasuryAddress, time.Now())
+	require.NoError(t, err)
+	assertBalanceEqual(t, minted.Balance, snapshot.Balance)
+
+	burned, err := trs.Apply(context.Background(), models.TreasuryOperation{
+		Kind:      models.TreasuryBurn,
+		Amount:    balance(t, "0.5"),
+		Reason:    "correction",
+		Timestamp: timestamp.Add(time.Second),
+	})
+	require.NoError(t, err)
+	assert.Greater(t, burned.ID, minted.ID)
+	assertBalanceEqual(t, add(t, initial, balance(t, "10")), burned.Balance)
+
+	// failed operations change nothing
+	_, err = trs.Apply(context.Background(), models.TreasuryOperation{
+		Kind:      models.TreasuryBurn,
+		Amount:    add(t, burned.Balance, balance(t, "0.000001")),
+		Reason:    "too much",
+		Timestamp: timestamp.Add(2 * time.Second),
+	})
+	assert.True(t, storageLayer.IsInsufficientFundsErr(err), "expected insufficient funds error, got %v", err)
+	_, err = trs.Apply(context.Background(), models.TreasuryOperation{
+		Kind:      "print",
+		Amount:    balance(t, "1"),
+		Timestamp: timestamp.Add(2 * time.Second),
+	})
+	assert.True(t, storageLayer.IsExternalErr(err), "expected external error, got %v", err)
+	assertBalanceEqual(t, burned.Balance, treasuryBalance(t, ws))
+
+	// treasury is regular wallet for transfers
+	wallet := insertWallet(t, ws, "0")
+	_, _, err = storages.Transaction.Transfer(context.Background(), models.Transaction{
+		FromAddress: models.TreasuryAddress,
+		ToAddress:   wallet.Address,
+		Amount:      balance(t, "3"),
+		Timestamp:   time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	assertBalanceEqual(t, balance(t, "3"), getBalance(t, ws, wallet.ID))
+}
+
+func testTreasuryList(t *testing.T, newStorages Factory) {
+	trs := newStorages(t).Treasury
+
+	var applied []models.TreasuryOperation
+	for i := range 3 {
+		operation, err := trs.Apply(context.Background(), models.TreasuryOperation{
+			Kind:      models.TreasuryMint,
+			Amount:    balance(t, "1"),
+			Reason:    "list " + strconv.Itoa(i),
+			Timestamp: time.Now().UTC(),
+		})
+		require.NoError(t, err)
+		applied = append(applied, operation)
+	}
+
+	listed, err := trs.List(context.Background(), applied[0].ID-1, 2)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	for i, operation := range listed {
+		assert.Equal(t, applied[i].ID, operation.ID)
+		assert.Equal(t, applied[i].Reason, operation.Reason)
+		assertBalanceEqual(t, applied[i].Amount, operation.Amount)
+		assertBalanceEqual(t, applied[i].Balance, operation.Balance)
+		assert.WithinDuration(t, applied[i].Timestamp, operation.Timestamp, time.Microsecond)
+	}
+
+	listed, err = trs.List(context.Background(), applied[2].ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	_, err = trs.List(context.Background(), 0, 0)
+	assert.True(t, storageLayer.IsExternalErr(err), "expected external error, got %v", err)
+}
+
+func testAuditInsertAndList(t *testing.T, newStorages Factory) {
+	as := newStorages(t).Audit
+	actor := "actor-" + uuid.NewString()
+
+	record := models.AuditRecord{
+		Timestamp:    time.Now().UTC(),
+		Actor:        actor,
+		SourceIP:     "192.0.2.1",
+		RequestID:    "req-1",
+		Action:       models.AuditTransfer,
+		Wallet:       uuid.NewString(),
+		Counterparty: uuid.NewString(),
+		Before:       models.AuditState{"from_balance": "10", "to_balance": "0"},
+		After:        models.AuditState{"from_balance": "9", "to_balance": "1"},
+		Outcome:      models.AuditSuccess,
+	}
+	inserted, err := as.Insert(context.Background(), record)
+	require.NoError(t, err)
+	assert.Positive(t, inserted.ID)
+
+	failed, err := as.Insert(context.Background(), models.AuditRecord{
+		Timestamp: time.Now().UTC(),
+		Actor:     actor,
+		Action:    models.AuditWalletFreeze,
+		Wallet:    "not-uuid",
+		Outcome:   models.AuditFailure,
+		Error:     "wallet not found",
+	})
+	require.NoError(t, err)
+	assert.Greater(t, failed.ID, inserted.ID)
+
+	listed, err := as.List(context.Background(), models.AuditFilter{Actor: actor}, inserted.ID-1, 10)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	assert.Equal(t, inserted.ID, listed[0].ID)
+	assert.WithinDuration(t, record.Timestamp, listed[0].Timestamp, time.Microsecond)
+	record.ID, record.Timestamp = listed[0].ID, listed[0].Timestamp
+	assert.Equal(t, record, listed[0])
+
+	// empty states and malformed addresses of failed actions are kept as is
+	assert.Equal(t, "not-uuid", listed[1].Wallet)
+	assert.Empty(t, listed[1].Before)
+	assert.Empty(t, listed[1].After)
+	assert.Equal(t, "wallet not found", listed[1].Error)
+
+	listed, err = as.List(context.Background(), models.AuditFilter{Actor: actor}, inserted.ID-1, 1)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, inserted.ID, listed[0].ID)
+
+	listed, err = as.List(context.Background(), models.AuditFilter{Actor: actor}, failed.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	_, err = as.List(context.Background(), models.AuditFilter{}, 0, 0)
+	assert.True(t, storageLayer.IsExternalErr(err), "expected external error, got %v", err)
+}
+
+func testAuditFilters(t *testing.T, newStorages Factory) {
+	as := newStorages(t).Audit
+	actor, other := "actor-"+uuid.NewString(), "actor-"+uuid.NewString()
+	wallet := uuid.NewString()
+	base := time.Now().UTC().Truncate(time.Second)
+
+	insert := func(actor, wallet, counterparty string, at time.Time) models.AuditRecord {
+		record, err := as.Insert(context.Background(), models.AuditRecord{
+			Timestamp:    at,
+			Actor:        actor,
+			Action:       models.AuditTransfer,
+			Wallet:       wallet,
+			Counterparty: counterparty,
+			Outcome:      models.AuditSuccess,
+		})
+		require.NoError(t, err)
+		return record
+	}
+	sent := insert(actor, wallet, uuid.NewString(), base)
+	received := insert(other, uuid.NewString(), wallet, base.Add(time.Minute))
+	later := insert(actor, wallet, uuid.NewString(), base.Add(2*time.Minute))
+	unrelated := insert(actor, uuid.NewString(), uuid.NewString(), base.Add(time.Minute))
+
+	ids := func(filter models.AuditFilter) []int {
+		t.Helper()
+
+		records, err := as.List(context.Background(), filter, sent.ID-1, 100)
+		require.NoError(t, err)
+
+		var ids []int
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int{sent.ID, received.ID, later.ID}, ids(models.AuditFilter{Wallet: wallet}))
+	assert.Equal(t, []int{sent.ID, later.ID, unrelated.ID}, ids(models.AuditFilter{Actor: actor}))
+	assert.Equal(t, []int{sent.ID, later.ID}, ids(models.AuditFilter{Actor: actor, Wallet: wallet}))
+	// period includes start and excludes end
+	assert.Equal(t, []int{received.ID}, ids(models.AuditFilter{
+		Wallet: wallet,
+		From:   base.Add(time.Minute),
+		To:     base.Add(2 * time.Minute),
+	}))
+}
@@ -0,0 +1,185 @@
+// Package testutil contains fixtures over memory storage, that are
+// shared by tests of usecases, http controller and api client
+package testutil
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/config"
+	gincontroller "github.com/lunn06/wallet/internal/delivery/gin"
+	"github.com/lunn06/wallet/internal/domain/models"
+	"github.com/lunn06/wallet/internal/domain/usecase/audit"
+	"github.com/lunn06/wallet/internal/domain/usecase/chain"
+	"github.com/lunn06/wallet/internal/domain/usecase/snapshot"
+	"github.com/lunn06/wallet/internal/domain/usecase/statement"
+	"github.com/lunn06/wallet/internal/domain/usecase/transation"
+	"github.com/lunn06/wallet/internal/domain/usecase/wallet"
+	"github.com/lunn06/wallet/internal/health"
+	"github.com/lunn06/wallet/internal/metrics"
+	"github.com/lunn06/wallet/internal/storage/memory"
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+const (
+	// AdminToken is admin token of Server
+	AdminToken = "admin-token"
+	// MaxAmount is max transfer amount of Server
+	MaxAmount = "1000000"
+)
+
+// USD is currency of fixtures, unless config of Server changes it
+var USD = money.Currency{Code: "USD", Scale: 2}
+
+// Discard is logger of fixtures, that writes nothing
+var Discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// Storage is memory storages sharing the same data, balances are in Currency
+type Storage struct {
+	Currency money.Currency
+
+	Wallet      memory.WalletStorage
+	Transaction memory.TransactionStorage
+	Snapshot    memory.SnapshotStorage
+	Checkpoint  memory.CheckpointStorage
+	Treasury    memory.TreasuryStorage
+	Audit       memory.AuditStorage
+}
+
+// NewStorage creates storages over empty memory storage
+func NewStorage(currency money.Currency) Storage {
+	storage := memory.NewStorage(currency)
+	return Storage{
+		Currency:    currency,
+		Wallet:      memory.WalletStorage{Storage: storage},
+		Transaction: memory.TransactionStorage{Storage: storage},
+		Snapshot:    memory.SnapshotStorage{Storage: storage},
+		Checkpoint:  memory.CheckpointStorage{Storage: storage},
+		Treasury:    memory.TreasuryStorage{Storage: storage},
+		Audit:       memory.AuditStorage{Storage: storage},
+	}
+}
+
+// Balance parses balance in currency of storage
+func (s Storage) Balance(t testing.TB, balance string) models.Balance {
+	t.Helper()
+
+	b, err := models.NewBalanceFromString(balance, s.Currency)
+	require.NoError(t, err)
+
+	return b
+}
+
+// InsertWallet inserts wallet with random address
+func (s Storage) InsertWallet(t testing.TB, balance string) models.Wallet {
+	t.Helper()
+
+	w, err := s.Wallet.Insert(context.Background(), models.Wallet{Address: uuid.NewString(), Balance: s.Balance(t, balance)})
+	require.NoError(t, err)
+
+	return w
+}
+
+// Transfer moves amount between wallets bypassing usecases
+func (s Storage) Transfer(t testing.TB, from, to, amount string, timestamp time.Time) models.Transaction {
+	t.Helper()
+
+	transaction, _, err := s.Transaction.Transfer(context.Background(), models.Transaction{
+		FromAddress: from,
+		ToAddress:   to,
+		Amount:      s.Balance(t, amount),
+		Timestamp:   timestamp,
+	})
+	require.NoError(t, err)
+
+	return transaction
+}
+
+// Server is http controller with real usecases over memory storage
+type Server struct {
+	Storage
+
+	Handler http.Handler
+	// Level is level of controller logger, that is changed by admin endpoint
+	Level *slog.LevelVar
+}
+
+// NewServer creates Server, which config is changed by configure, if it isn't nil
+func NewServer(t testing.TB, configure func(cfg *config.Config)) Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var cfg config.Config
+	cfg.HTTPServer.WriteTimeout = time.Second
+	cfg.Admin.Token = AdminToken
+	cfg.Statement = config.Statement{MaxConcurrent: 1, Timeout: time.Minute}
+	cfg.Transfer = config.Transfer{Currency: USD.Code, MaxScale: USD.Scale, MaxAmount: MaxAmount}
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	storage := NewStorage(money.Currency{Code: cfg.Transfer.Currency, Scale: cfg.Transfer.MaxScale})
+	level := new(slog.LevelVar)
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: level}))
+	appMetrics := metrics.New()
+
+	snapshotUc := snapshot.NewUsecase(storage.Wallet, storage.Transaction, storage.Snapshot, logger)
+	auditUc := audit.NewUsecase(storage.Audit, nil, logger)
+
+	controller := gincontroller.New(
+		cfg,
+		logger,
+		appMetrics,
+		health.NewProbe(logger),
+		wallet.NewUsecase(storage.Wallet, snapshotUc, auditUc, storage.Currency, logger),
+		transation.NewUsecase(storage.Transaction, storage.Wallet, appMetrics, auditUc, storage.Currency),
+		statement.NewUsecase(storage.Transaction, snapshotUc, logger),
+		chain.NewUsecase(storage.Transaction, storage.Checkpoint, nil, logger),
+		auditUc,
+		level,
+	)
+
+	return Server{Storage: storage, Handler: controller.Handler(), Level: level}
+}
+
+// Do serves request with optional json body and returns recorded response
+func (s Server) Do(t testing.TB, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// Listen serves Server over loopback listener until test ends
+func (s Server) Listen(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(s.Handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
@@ -0,0 +1,66 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lunn06/wallet/internal/tracing"
+)
+
+// logRecord logs message with ctx and returns logged json record
+func logRecord(t *testing.T, ctx context.Context, wrap func(slog.Handler) slog.Handler) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.New(wrap(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil))))
+	logger.InfoContext(ctx, "message")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	return record
+}
+
+func TestLogHandler(t *testing.T) {
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	traced := trace.ContextWithSpanContext(context.Background(), spanCtx)
+	noWrap := func(h slog.Handler) slog.Handler { return h }
+
+	t.Run("adds ids of span", func(t *testing.T) {
+		record := logRecord(t, traced, noWrap)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+	})
+	t.Run("skips context without span", func(t *testing.T) {
+		record := logRecord(t, context.Background(), noWrap)
+		assert.NotContains(t, record, "trace_id")
+		assert.NotContains(t, record, "span_id")
+	})
+	t.Run("keeps ids in derived handlers", func(t *testing.T) {
+		record := logRecord(t, traced, func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.String("component", "worker")}).WithGroup("request")
+		})
+		assert.Equal(t, "worker", record["component"])
+		// ids are added to record, so they are in group of handler
+		assert.Equal(t, map[string]any{
+			"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":  "00f067aa0ba902b7",
+		}, record["request"])
+	})
+	t.Run("respects level of next handler", func(t *testing.T) {
+		handler := tracing.NewLogHandler(slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn}))
+		assert.False(t, handler.Enabled(traced, slog.LevelInfo))
+		assert.True(t, handler.Enabled(traced, slog.LevelError))
+	})
+}
@@ -0,0 +1,74 @@
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/lunn06/wallet/internal/config"
+	"github.com/lunn06/wallet/internal/tracing"
+)
+
+// initTracing calls tracing.Init and restores global tracer provider after test
+func initTracing(t *testing.T, cfg config.Tracing) (func(context.Context) error, error) {
+	t.Helper()
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "wallet-test"
+	}
+	cfg.SampleRatio = 1
+
+	return tracing.Init(cfg)
+}
+
+func TestInit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("none", func(t *testing.T) {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+
+		shutdown, err := initTracing(t, config.Tracing{Exporter: tracing.ExporterNone})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(ctx))
+
+		assert.IsType(t, noop.TracerProvider{}, otel.GetTracerProvider())
+		// incoming trace context is still propagated to logs
+		assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+	})
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "traces.json")
+
+		shutdown, err := initTracing(t, config.Tracing{Exporter: tracing.ExporterFile, FilePath: path})
+		require.NoError(t, err)
+		require.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+
+		_, span := otel.Tracer("test").Start(ctx, "exported span")
+		span.End()
+		// spans are batched until shutdown flushes them
+		require.NoError(t, shutdown(ctx))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"Name":"exported span"`)
+		assert.Contains(t, string(data), "wallet-test")
+	})
+	t.Run("otlp", func(t *testing.T) {
+		// exporter connects lazily, so collector isn't needed without spans
+		shutdown, err := initTracing(t, config.Tracing{Exporter: tracing.ExporterOTLP, Endpoint: "127.0.0.1:4318", Insecure: true})
+		require.NoError(t, err)
+		assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+		assert.NoError(t, shutdown(ctx))
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := initTracing(t, config.Tracing{Exporter: "jaeger"})
+		assert.ErrorContains(t, err, `unknown tracing exporter "jaeger"`)
+	})
+}
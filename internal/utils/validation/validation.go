@@ -0,0 +1,122 @@
+// Package validation translates errors of go-playground validator
+// to human-readable messages and has custom rules, that are shared
+// by config and http layer
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// Translator describes failed rules of fields. Custom describes rules of
+// its user (e.g. registered by it) and overrides common ones. Values of
+// fields are shown in messages only with ShowValues, so values of client
+// requests aren't echoed back
+type Translator struct {
+	Custom     map[string]func(validator.FieldError) string
+	ShowValues bool
+}
+
+// Describe returns message of failed rule, that follows field name,
+// e.g. "is required". Unknown rules are described by their tags
+func (t Translator) Describe(fieldErr validator.FieldError) string {
+	if describe, ok := t.Custom[fieldErr.Tag()]; ok {
+		return describe(fieldErr)
+	}
+
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return fmt.Sprintf("is required when %s", param)
+	case "required_with":
+		return fmt.Sprintf("is required with %s", param)
+	case "excluded_without":
+		return fmt.Sprintf("requires %s", param)
+	case "uuid", "uuid4":
+		return "must be uuid" + t.got(fieldErr)
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", param) + t.got(fieldErr)
+	case "min":
+		return fmt.Sprintf("must have at least %s items", param)
+	case "numeric":
+		return "must be numeric" + t.got(fieldErr)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", param) + t.got(fieldErr)
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", param) + t.got(fieldErr)
+	case "lt", "ltfield":
+		return fmt.Sprintf("must be less than %s", param) + t.got(fieldErr)
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", param) + t.got(fieldErr)
+	case "gtfield":
+		return fmt.Sprintf("must be after %s", strings.ToLower(param))
+	case "ip|cidr":
+		return "must be IP address or CIDR" + t.got(fieldErr)
+	case "iso4217":
+		return "must be ISO 4217 currency code" + t.got(fieldErr)
+	case "positive_decimal":
+		return "must be positive decimal number without sign and exponent" + t.got(fieldErr)
+	default:
+		return fmt.Sprintf("failed on %q rule", fieldErr.Tag())
+	}
+}
+
+// got returns value of field to append to message, if values are shown.
+// Strings are quoted to make empty and spaced values visible
+func (t Translator) got(fieldErr validator.FieldError) string {
+	if !t.ShowValues {
+		return ""
+	}
+	if s, ok := fieldErr.Value().(string); ok {
+		return fmt.Sprintf(", got %q", s)
+	}
+	return fmt.Sprintf(", got %v", fieldErr.Value())
+}
+
+// FieldPath returns path of field without root struct name,
+// e.g. "from" of "SendRequest.from"
+func FieldPath(fieldErr validator.FieldError) string {
+	_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+	return path
+}
+
+// TagName returns validator.TagNameFunc, that names fields by struct
+// tag, e.g. yaml or json, as users see them. Fields named "-" are unnamed
+func TagName(tag string) validator.TagNameFunc {
+	return func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	}
+}
+
+// plainDecimal is decimal without sign, exponent, spaces or
+// thousand separators, digits count is bounded to not parse huge numbers
+var plainDecimal = regexp.MustCompile(`^[0-9]{1,40}(\.[0-9]{1,40})?$`)
+
+// ParsePlainDecimal parses decimal without sign, exponent, spaces or
+// thousand separators and reports whether s is such decimal
+func ParsePlainDecimal(s string) (decimal.Decimal, bool) {
+	if !plainDecimal.MatchString(s) {
+		return decimal.Decimal{}, false
+	}
+
+	d, err := decimal.NewFromString(s)
+	return d, err == nil
+}
+
+// PositiveDecimal is "positive_decimal" rule of strings,
+// that are plain decimals (see ParsePlainDecimal) greater than zero
+func PositiveDecimal(fl validator.FieldLevel) bool {
+	d, ok := ParsePlainDecimal(fl.Field().String())
+	return ok && d.IsPositive()
+}
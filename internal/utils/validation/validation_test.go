@@ -0,0 +1,62 @@
+package validation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/utils/validation"
+)
+
+type request struct {
+	Kind   string `json:"kind" validate:"oneof=mint burn"`
+	Count  int    `json:"count" validate:"gte=0"`
+	Secret string `json:"secret" validate:"len=4"`
+}
+
+func fieldErrors(t *testing.T, obj any) validator.ValidationErrors {
+	t.Helper()
+
+	v := validator.New()
+	v.RegisterTagNameFunc(validation.TagName("json"))
+
+	var fieldErrs validator.ValidationErrors
+	require.True(t, errors.As(v.Struct(obj), &fieldErrs))
+
+	return fieldErrs
+}
+
+func TestTranslator_Describe(t *testing.T) {
+	fieldErrs := fieldErrors(t, request{Kind: "print", Count: -1, Secret: "hunter2"})
+	require.Len(t, fieldErrs, 3)
+
+	describe := func(translator validation.Translator) map[string]string {
+		messages := make(map[string]string, len(fieldErrs))
+		for _, fieldErr := range fieldErrs {
+			messages[validation.FieldPath(fieldErr)] = translator.Describe(fieldErr)
+		}
+		return messages
+	}
+
+	assert.Equal(t, map[string]string{
+		"kind":   "must be one of [mint burn]",
+		"count":  "must be greater than or equal to 0",
+		"secret": `failed on "len" rule`,
+	}, describe(validation.Translator{}))
+
+	assert.Equal(t, map[string]string{
+		"kind":   `must be one of [mint burn], got "print"`,
+		"count":  "must be greater than or equal to 0, got -1",
+		"secret": "must have 4 characters",
+	}, describe(validation.Translator{
+		ShowValues: true,
+		Custom: map[string]func(validator.FieldError) string{
+			"len": func(fieldErr validator.FieldError) string {
+				return "must have " + fieldErr.Param() + " characters"
+			},
+		},
+	}))
+}
@@ -0,0 +1,127 @@
+// Package worker runs periodic background jobs of the app
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is one run of worker, ctx is cancelled on worker Close
+type Job func(ctx context.Context) error
+
+// Observer receives result of every run (e.g. metrics.Metrics)
+type Observer interface {
+	ObserveWorkerRun(worker string, duration time.Duration, err error)
+}
+
+// State is reported by health readiness probe
+type State struct {
+	Runs         int       `json:"runs"`
+	LastRun      time.Time `json:"last_run,omitempty"`
+	LastDuration string    `json:"last_duration,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// Worker runs job at start and then every interval until Close
+type Worker struct {
+	name     string
+	interval time.Duration
+	job      Job
+	observer Observer
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	state State
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func New(name string, interval time.Duration, job Job, observer Observer, logger *slog.Logger) *Worker {
+	if job == nil || observer == nil {
+		panic("job and observer can not be nil")
+	}
+	return &Worker{
+		name:     name,
+		interval: interval,
+		job:      job,
+		observer: observer,
+		logger:   logger.With("worker", name),
+	}
+}
+
+// Start runs worker loop in background
+func (w *Worker) Start() {
+	ctx, stop := context.WithCancel(context.Background())
+	w.stop = stop
+	w.done = make(chan struct{})
+
+	go w.loop(ctx)
+
+	w.logger.Info("Worker started", "interval", w.interval)
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context) {
+	start := time.Now()
+	err := w.job(ctx)
+	duration := time.Since(start)
+
+	w.observer.ObserveWorkerRun(w.name, duration, err)
+
+	w.mu.Lock()
+	w.state.Runs++
+	w.state.LastRun = start.UTC()
+	w.state.LastDuration = duration.String()
+	w.state.LastError = ""
+	if err != nil {
+		w.state.LastError = err.Error()
+	}
+	w.mu.Unlock()
+
+	if err != nil {
+		w.logger.Error("Worker run failed", "cause", err.Error())
+	}
+}
+
+// Report implements health.Reporter
+func (w *Worker) Report() any {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.state
+}
+
+// Close stops worker and waits for current run to finish
+func (w *Worker) Close(ctx context.Context) error {
+	if w.stop == nil {
+		return nil
+	}
+	w.stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.done:
+		w.logger.Info("Worker closed")
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/worker"
+)
+
+type observer struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (o *observer) ObserveWorkerRun(_ string, _ time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.errs = append(o.errs, err)
+}
+
+func TestWorker(t *testing.T) {
+	t.Run("runs periodically and reports state", func(t *testing.T) {
+		var runs atomic.Int64
+		obs := &observer{}
+		w := worker.New("test", time.Millisecond, func(ctx context.Context) error {
+			if runs.Add(1) == 1 {
+				return errors.New("first run failed")
+			}
+			return nil
+		}, obs, slog.Default())
+
+		w.Start()
+		require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+		require.NoError(t, w.Close(context.Background()))
+
+		state := w.Report().(worker.State)
+		assert.Equal(t, int(runs.Load()), state.Runs)
+		assert.Empty(t, state.LastError)
+		assert.False(t, state.LastRun.IsZero())
+
+		obs.mu.Lock()
+		defer obs.mu.Unlock()
+		assert.Len(t, obs.errs, state.Runs)
+		assert.EqualError(t, obs.errs[0], "first run failed")
+	})
+	t.Run("close cancels running job", func(t *testing.T) {
+		started := make(chan struct{})
+		w := worker.New("test", time.Hour, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}, &observer{}, slog.Default())
+
+		w.Start()
+		<-started
+		require.NoError(t, w.Close(context.Background()))
+
+		assert.Equal(t, context.Canceled.Error(), w.Report().(worker.State).LastError)
+	})
+	t.Run("close without start", func(t *testing.T) {
+		w := worker.New("test", time.Hour, func(ctx context.Context) error { return nil }, &observer{}, slog.Default())
+
+		assert.NoError(t, w.Close(context.Background()))
+	})
+}
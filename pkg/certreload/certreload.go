@@ -0,0 +1,153 @@
+// Package certreload keeps TLS certificate and client CAs loaded from files
+// up to date. Files are polled rather than watched, so both in-place writes
+// and atomic replacements (e.g. of mounted kubernetes secrets) are noticed
+package certreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Reloader serves the last successfully loaded certificate and client CAs.
+// Failed reload keeps previous ones, e.g. while certificate is already
+// replaced, but its key is not yet
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	logger   *slog.Logger
+
+	loaded atomic.Pointer[loaded]
+}
+
+type loaded struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    []stamp
+}
+
+// stamp identifies version of file
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New loads certificate and key files and optional file of client CAs.
+// Empty caFile means that client certificates aren't requested
+func New(certFile, keyFile, caFile string, logger *slog.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		logger:   logger,
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns server config, that uses the current certificate. With
+// client CAs it requires client certificate verified by one of them
+func (r *Reloader) TLSConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.loaded.Load().cert, nil
+		},
+	}
+	if r.caFile == "" {
+		return cfg
+	}
+
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	// ClientCAs can't be replaced in shared config,
+	// so every handshake gets config with the current ones
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		current := r.loaded.Load()
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{*current.cert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    current.clientCAs,
+			NextProtos:   []string{"h2", "http/1.1"},
+		}, nil
+	}
+
+	return cfg
+}
+
+// Reload loads files if some of them changed since the last
+// load and reports whether new certificate is served
+func (r *Reloader) Reload() (bool, error) {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+
+	stamps := make([]stamp, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, fmt.Errorf("certreload: %w", err)
+		}
+		stamps[i] = stamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	if current := r.loaded.Load(); current != nil && slices.Equal(current.stamps, stamps) {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("certreload: load key pair: %w", err)
+	}
+
+	next := &loaded{cert: &cert, stamps: stamps}
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return false, fmt.Errorf("certreload: %w", err)
+		}
+		next.clientCAs = x509.NewCertPool()
+		if !next.clientCAs.AppendCertsFromPEM(pem) {
+			return false, errors.New("certreload: no certificates in client CA file")
+		}
+	}
+
+	r.loaded.Store(next)
+
+	return true, nil
+}
+
+// Watch reloads changed files every interval until ctx is done
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := r.Reload()
+		switch {
+		case err != nil:
+			r.logger.Warn("Failed to reload TLS certificate, previous one is kept", "cause", err.Error())
+		case reloaded:
+			r.logger.Info("TLS certificate reloaded", "cert_file", r.certFile)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package certreload_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/pkg/certreload"
+)
+
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue creates certificate with serial signed by parent, nil parent means self-signed CA
+func issue(t *testing.T, serial int64, subject pkix.Name, parent *keyPair) keyPair {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer := keyPair{cert: template, key: key}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer = *parent
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer.cert, &key.PublicKey, signer.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return keyPair{cert: cert, key: key}
+}
+
+func (p keyPair) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: p.cert.Raw})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+
+	if keyFile == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(p.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+func (p keyPair) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{p.cert.Raw}, PrivateKey: p.key}
+}
+
+type files struct {
+	cert, key, ca string
+}
+
+func newFiles(t *testing.T) files {
+	dir := t.TempDir()
+	return files{
+		cert: filepath.Join(dir, "server.crt"),
+		key:  filepath.Join(dir, "server.key"),
+		ca:   filepath.Join(dir, "ca.crt"),
+	}
+}
+
+// serve serves tls with cfg and responds with subject of verified client certificate
+func serve(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) > 0 {
+			_, _ = io.WriteString(w, r.TLS.VerifiedChains[0][0].Subject.String())
+		}
+	})}
+	go func() { _ = server.Serve(tls.NewListener(listener, cfg)) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return "https://" + listener.Addr().String()
+}
+
+func newClient(ca *x509.Certificate, certs ...tls.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs},
+		DisableKeepAlives: true,
+	}}
+}
+
+// servedSerial connects to url and returns serial of server certificate
+func servedSerial(t *testing.T, client *http.Client, url string) int64 {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestReloader_Reload(t *testing.T) {
+	f := newFiles(t)
+	ca := issue(t, 1, pkix.Name{CommonName: "ca"}, nil)
+	issue(t, 10, pkix.Name{CommonName: "server"}, &ca).write(t, f.cert, f.key)
+
+	reloader, err := certreload.New(f.cert, f.key, "", discard)
+	require.NoError(t, err)
+
+	url := serve(t, reloader.TLSConfig())
+	client := newClient(ca.cert)
+	assert.Equal(t, int64(10), servedSerial(t, client, url))
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "files are unchanged")
+
+	issue(t, 11, pkix.Name{CommonName: "server"}, &ca).write(t, f.cert, f.key)
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, int64(11), servedSerial(t, client, url))
+
+	t.Run("broken key keeps previous certificate", func(t *testing.T) {
+		issue(t, 12, pkix.Name{CommonName: "server"}, &ca).write(t, f.cert, "")
+
+		_, err := reloader.Reload()
+		assert.Error(t, err)
+		assert.Equal(t, int64(11), servedSerial(t, client, url))
+	})
+}
+
+func TestReloader_Watch(t *testing.T) {
+	f := newFiles(t)
+	ca := issue(t, 1, pkix.Name{CommonName: "ca"}, nil)
+	issue(t, 10, pkix.Name{CommonName: "server"}, &ca).write(t, f.cert, f.key)
+
+	reloader, err := certreload.New(f.cert, f.key, "", discard)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 5*time.Millisecond)
+
+	url := serve(t, reloader.TLSConfig())
+	client := newClient(ca.cert)
+
+	issue(t, 11, pkix.Name{CommonName: "server"}, &ca).write(t, f.cert, f.key)
+	assert.Eventually(t, func() bool {
+		return servedSerial(t, client, url) == 11
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	f := newFiles(t)
+	ca := issue(t, 1, pkix.Name{CommonName: "ca"}, nil)
+	ca.write(t, f.ca, "")
+	issue(t, 10, pkix.Name{CommonName: "server"}, &ca).write(t, f.cert, f.key)
+
+	reloader, err := certreload.New(f.cert, f.key, f.ca, discard)
+	require.NoError(t, err)
+	url := serve(t, reloader.TLSConfig())
+
+	t.Run("client without certificate", func(t *testing.T) {
+		_, err := newClient(ca.cert).Get(url)
+		assert.Error(t, err)
+	})
+	t.Run("client of unknown CA", func(t *testing.T) {
+		other := issue(t, 2, pkix.Name{CommonName: "other"}, nil)
+		client := issue(t, 20, pkix.Name{CommonName: "ops"}, &other)
+
+		_, err := newClient(ca.cert, client.tlsCertificate()).Get(url)
+		assert.Error(t, err)
+	})
+	t.Run("client of CA", func(t *testing.T) {
+		client := issue(t, 20, pkix.Name{CommonName: "ops", Organization: []string{"Wallet"}}, &ca)
+
+		resp, err := newClient(ca.cert, client.tlsCertificate()).Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		subject, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "CN=ops,O=Wallet", string(subject))
+	})
+	t.Run("reloaded CA", func(t *testing.T) {
+		other := issue(t, 2, pkix.Name{CommonName: "other"}, nil)
+		other.write(t, f.ca, "")
+		_, err := reloader.Reload()
+		require.NoError(t, err)
+
+		client := issue(t, 21, pkix.Name{CommonName: "ops"}, &other)
+		_, err = newClient(ca.cert, client.tlsCertificate()).Get(url)
+		assert.NoError(t, err)
+	})
+}
+
+func TestNew(t *testing.T) {
+	f := newFiles(t)
+
+	_, err := certreload.New(f.cert, f.key, "", discard)
+	assert.Error(t, err)
+}
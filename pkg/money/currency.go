@@ -0,0 +1,75 @@
+package money
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// MaxScale is max count of minor unit digits of currency
+const MaxScale = 18
+
+var validCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Currency is ISO 4217 like code with count of minor unit digits,
+// e.g. USD has 2 digits (cents) and JPY has none
+type Currency struct {
+	Code  string
+	Scale int32
+}
+
+// NewCurrency validates code, that must be 3 uppercase letters, and scale
+func NewCurrency(code string, scale int32) (Currency, error) {
+	if !validCode.MatchString(code) {
+		return Currency{}, fmt.Errorf("%w: code %q must be 3 uppercase letters", ErrInvalidCurrency, code)
+	}
+	if scale < 0 || scale > MaxScale {
+		return Currency{}, fmt.Errorf("%w: scale %d must be between 0 and %d", ErrInvalidCurrency, scale, MaxScale)
+	}
+	return Currency{Code: code, Scale: scale}, nil
+}
+
+func (c Currency) String() string {
+	return c.Code
+}
+
+var (
+	registryMu sync.RWMutex
+	// registry resolves currencies of unmarshalled money by code
+	registry = map[string]Currency{
+		"USD": {Code: "USD", Scale: 2},
+		"EUR": {Code: "EUR", Scale: 2},
+		"GBP": {Code: "GBP", Scale: 2},
+		"CHF": {Code: "CHF", Scale: 2},
+		"CNY": {Code: "CNY", Scale: 2},
+		"RUB": {Code: "RUB", Scale: 2},
+		"KZT": {Code: "KZT", Scale: 2},
+		"JPY": {Code: "JPY", Scale: 0},
+		"KRW": {Code: "KRW", Scale: 0},
+		"BHD": {Code: "BHD", Scale: 3},
+		"KWD": {Code: "KWD", Scale: 3},
+	}
+)
+
+// LookupCurrency returns registered currency by code
+func LookupCurrency(code string) (Currency, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	currency, ok := registry[code]
+	return currency, ok
+}
+
+// RegisterCurrency makes currency known to unmarshalling, it replaces
+// registered currency with the same code. Currency must be valid
+func RegisterCurrency(currency Currency) error {
+	if _, err := NewCurrency(currency.Code, currency.Scale); err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[currency.Code] = currency
+	return nil
+}
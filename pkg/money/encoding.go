@@ -0,0 +1,90 @@
+package money
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	_ json.Marshaler   = Money{}
+	_ json.Unmarshaler = (*Money)(nil)
+	_ driver.Valuer    = Money{}
+	_ sql.Scanner      = (*Money)(nil)
+)
+
+// jsonMoney keeps amount as string, so it isn't rounded by float parsers of clients
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes money as {"amount": "10.50", "currency": "USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.currency.Code == "" {
+		return nil, fmt.Errorf("%w: money without currency", ErrInvalidCurrency)
+	}
+	return json.Marshal(jsonMoney{Amount: m.amount.StringFixed(m.currency.Scale), Currency: m.currency.Code})
+}
+
+// UnmarshalJSON decodes money of MarshalJSON, currency must be registered
+// (see LookupCurrency) and amount must fit its scale
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var decoded jsonMoney
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	currency, ok := LookupCurrency(decoded.Currency)
+	if !ok {
+		return fmt.Errorf("%w: unknown code %q", ErrInvalidCurrency, decoded.Currency)
+	}
+	parsed, err := Parse(decoded.Amount, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Value stores amount as decimal string, e.g. to numeric column.
+// Currency isn't stored, it's usually fixed by column or stored in another one
+func (m Money) Value() (driver.Value, error) {
+	if m.currency.Code == "" {
+		return nil, fmt.Errorf("%w: money without currency", ErrInvalidCurrency)
+	}
+	return m.amount.StringFixed(m.currency.Scale), nil
+}
+
+// Scan reads amount stored by Value and keeps currency of m, so m must be
+// created with currency before scan, e.g. by Zero. Floats are rejected as lossy
+func (m *Money) Scan(src any) error {
+	if m.currency.Code == "" {
+		return fmt.Errorf("%w: scan into money without currency", ErrInvalidCurrency)
+	}
+
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		// whole amount, e.g. of integer column in sqlite
+		*m = Money{amount: decimal.NewFromInt(v), currency: m.currency}
+		return nil
+	default:
+		return fmt.Errorf("%w: can't scan %T", ErrInvalidAmount, src)
+	}
+
+	parsed, err := Parse(s, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
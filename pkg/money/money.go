@@ -0,0 +1,233 @@
+// Package money implements exact amounts of money in currency with fixed
+// count of minor unit digits, e.g. 10.50 USD. Amounts are never kept with
+// more digits than currency allows: parsing rejects them, arithmetic that
+// can produce them takes explicit rounding mode
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidCurrency is returned for malformed or unknown currency
+	ErrInvalidCurrency = errors.New("money: invalid currency")
+	// ErrCurrencyMismatch is returned by operations on money in different currencies
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	// ErrInvalidAmount is returned for malformed amount
+	ErrInvalidAmount = errors.New("money: invalid amount")
+	// ErrScale is returned for amount with more digits than currency allows
+	ErrScale = errors.New("money: amount exceeds scale of currency")
+	// ErrInvalidRatios is returned by Allocate for ratios, that can't split money
+	ErrInvalidRatios = errors.New("money: invalid ratios")
+)
+
+var (
+	maxMinor = decimal.NewFromInt(math.MaxInt64)
+	minMinor = decimal.NewFromInt(math.MinInt64)
+)
+
+// RoundingMode selects how amount is rounded to scale of currency
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds half to even digit (banker's rounding), e.g. 2.5 to 2 and 3.5 to 4
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds half away from zero, e.g. 2.5 to 3 and -2.5 to -3
+	RoundHalfUp
+	// RoundDown truncates digits toward zero, e.g. 2.9 to 2 and -2.9 to -2
+	RoundDown
+)
+
+func (mode RoundingMode) round(d decimal.Decimal, scale int32) decimal.Decimal {
+	switch mode {
+	case RoundHalfUp:
+		return d.Round(scale)
+	case RoundDown:
+		return d.Truncate(scale)
+	default:
+		return d.RoundBank(scale)
+	}
+}
+
+// Money is amount in currency. Zero value has no currency,
+// so it must be created by constructors of package
+type Money struct {
+	amount   decimal.Decimal
+	currency Currency
+}
+
+// New rounds amount to scale of currency by mode
+func New(amount decimal.Decimal, currency Currency, mode RoundingMode) Money {
+	return Money{amount: mode.round(amount, currency.Scale), currency: currency}
+}
+
+// Zero returns zero amount in currency
+func Zero(currency Currency) Money {
+	return Money{currency: currency}
+}
+
+// FromMinor returns amount of units of the minor unit, e.g. 1050 cents is 10.50 USD
+func FromMinor(units int64, currency Currency) Money {
+	return Money{amount: decimal.New(units, -currency.Scale), currency: currency}
+}
+
+// Parse parses decimal amount, that must fit scale of currency exactly.
+// Trailing zeros don't count, so "1.500" is valid amount of USD
+func Parse(s string, currency Currency) (Money, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	return FromDecimal(d, currency)
+}
+
+// FromDecimal returns amount, that must fit scale of currency exactly
+func FromDecimal(d decimal.Decimal, currency Currency) (Money, error) {
+	if !d.Equal(d.Truncate(currency.Scale)) {
+		return Money{}, fmt.Errorf("%w: %s has more than %d decimal places", ErrScale, d, currency.Scale)
+	}
+	return Money{amount: d.Truncate(currency.Scale), currency: currency}, nil
+}
+
+// Amount returns decimal amount, it never has more digits than scale of currency
+func (m Money) Amount() decimal.Decimal {
+	return m.amount
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// Minor returns amount in units of the minor unit, it fails if they overflow int64
+func (m Money) Minor() (int64, error) {
+	units := m.amount.Shift(m.currency.Scale)
+	if units.GreaterThan(maxMinor) || units.LessThan(minMinor) {
+		return 0, fmt.Errorf("%w: %s overflows minor units", ErrInvalidAmount, m)
+	}
+	return units.IntPart(), nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount.Add(other.amount), currency: m.currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount.Sub(other.amount), currency: m.currency}, nil
+}
+
+// Mul multiplies amount by factor, e.g. by rate of fee, and rounds result by mode
+func (m Money) Mul(factor decimal.Decimal, mode RoundingMode) Money {
+	return New(m.amount.Mul(factor), m.currency, mode)
+}
+
+func (m Money) Neg() Money {
+	return Money{amount: m.amount.Neg(), currency: m.currency}
+}
+
+// Cmp compares amounts of money in the same currency, result is -1, 0 or 1
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	return m.amount.Cmp(other.amount), nil
+}
+
+// Equal reports whether money has the same currency and amount
+func (m Money) Equal(other Money) bool {
+	return m.currency == other.currency && m.amount.Equal(other.amount)
+}
+
+func (m Money) IsZero() bool {
+	return m.amount.IsZero()
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount.IsNegative()
+}
+
+func (m Money) IsPositive() bool {
+	return m.amount.IsPositive()
+}
+
+// Allocate splits money in proportion to ratios without losing minor units: sum of
+// parts equals money exactly. Units left after proportional split are given one by
+// one to parts with the largest remainders, the first parts win ties. Ratios
+// must not be negative and must have positive sum
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios", ErrInvalidRatios)
+	}
+	total := decimal.Zero
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidRatios, ratio)
+		}
+		total = total.Add(decimal.NewFromInt(ratio))
+	}
+	if total.IsZero() {
+		return nil, fmt.Errorf("%w: zero sum of ratios", ErrInvalidRatios)
+	}
+
+	// integer arithmetic on minor units keeps split exact
+	units := m.amount.Shift(m.currency.Scale)
+	shares := make([]decimal.Decimal, len(ratios))
+	remainders := make([]decimal.Decimal, len(ratios))
+	left := units
+	for i, ratio := range ratios {
+		shares[i], remainders[i] = units.Mul(decimal.NewFromInt(ratio)).QuoRem(total, 0)
+		left = left.Sub(shares[i])
+	}
+
+	// left units have sign of money and are fewer than parts
+	unit := decimal.NewFromInt(int64(left.Sign()))
+	for range left.Abs().IntPart() {
+		largest := 0
+		for i := range remainders {
+			if remainders[i].Abs().GreaterThan(remainders[largest].Abs()) {
+				largest = i
+			}
+		}
+		shares[largest] = shares[largest].Add(unit)
+		remainders[largest] = decimal.Zero
+	}
+
+	parts := make([]Money, len(shares))
+	for i, share := range shares {
+		parts[i] = Money{amount: share.Shift(-m.currency.Scale), currency: m.currency}
+	}
+	return parts, nil
+}
+
+// Split splits money into n parts, that differ by one minor unit at most
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: %d parts", ErrInvalidRatios, n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// String formats money with all digits of scale and code, e.g. "10.50 USD"
+func (m Money) String() string {
+	return m.amount.StringFixed(m.currency.Scale) + " " + m.currency.Code
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/pkg/money"
+)
+
+var (
+	usd = money.Currency{Code: "USD", Scale: 2}
+	jpy = money.Currency{Code: "JPY", Scale: 0}
+	bhd = money.Currency{Code: "BHD", Scale: 3}
+
+	currencies = []money.Currency{usd, jpy, bhd}
+)
+
+// currencyOf picks currency for generated input
+func currencyOf(pick uint8) money.Currency {
+	return currencies[int(pick)%len(currencies)]
+}
+
+func TestProperty_AllocateConservesMoney(t *testing.T) {
+	property := func(units int64, pick uint8, ratios []uint16) bool {
+		m := money.FromMinor(units, currencyOf(pick))
+		converted := make([]int64, 0, len(ratios)+1)
+		for _, ratio := range ratios {
+			converted = append(converted, int64(ratio))
+		}
+		// ratios must have positive sum
+		converted = append(converted, 1)
+
+		parts, err := m.Allocate(converted...)
+		if err != nil || len(parts) != len(converted) {
+			return false
+		}
+
+		sum := money.Zero(m.Currency())
+		total := decimal.Zero
+		for _, ratio := range converted {
+			total = total.Add(decimal.NewFromInt(ratio))
+		}
+		unit := decimal.New(1, -m.Currency().Scale)
+		for i, part := range parts {
+			sum, err = sum.Add(part)
+			if err != nil || part.Currency() != m.Currency() {
+				return false
+			}
+			// every part is less than a unit away from its exact share
+			exact := m.Amount().Mul(decimal.NewFromInt(converted[i])).Div(total)
+			if part.Amount().Sub(exact).Abs().GreaterThanOrEqual(unit) {
+				return false
+			}
+		}
+		return sum.Equal(m)
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestProperty_SplitIsFair(t *testing.T) {
+	property := func(units int64, pick uint8, n uint8) bool {
+		m := money.FromMinor(units, currencyOf(pick))
+		parts, err := m.Split(int(n%32) + 1)
+		if err != nil {
+			return false
+		}
+
+		low, high := parts[0].Amount(), parts[0].Amount()
+		for _, part := range parts {
+			low, high = decimal.Min(low, part.Amount()), decimal.Max(high, part.Amount())
+		}
+		return high.Sub(low).LessThanOrEqual(decimal.New(1, -m.Currency().Scale))
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestProperty_RoundingFitsScale(t *testing.T) {
+	property := func(value int64, exp uint8, pick uint8, mode uint8) bool {
+		d := decimal.New(value, -int32(exp%12))
+		currency := currencyOf(pick)
+		rounding := money.RoundingMode(mode % 3)
+
+		m := money.New(d, currency, rounding)
+		unit := decimal.New(1, -currency.Scale)
+		diff := m.Amount().Sub(d).Abs()
+
+		// rounded amount is parsed back exactly
+		if _, err := money.FromDecimal(m.Amount(), currency); err != nil {
+			return false
+		}
+		if rounding == money.RoundDown {
+			return diff.LessThan(unit) && m.Amount().Abs().LessThanOrEqual(d.Abs())
+		}
+		return diff.LessThanOrEqual(unit.Div(decimal.NewFromInt(2)))
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestProperty_AddSubInverse(t *testing.T) {
+	property := func(a, b int64, pick uint8) bool {
+		currency := currencyOf(pick)
+		x, y := money.FromMinor(a, currency), money.FromMinor(b, currency)
+
+		sum, err := x.Add(y)
+		if err != nil {
+			return false
+		}
+		back, err := sum.Sub(y)
+		return err == nil && back.Equal(x)
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestProperty_EncodingRoundTrip(t *testing.T) {
+	property := func(units int64, pick uint8) bool {
+		m := money.FromMinor(units, currencyOf(pick))
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			return false
+		}
+		var decoded money.Money
+		if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Equal(m) {
+			return false
+		}
+
+		value, err := m.Value()
+		if err != nil {
+			return false
+		}
+		scanned := money.Zero(m.Currency())
+		if err := scanned.Scan(value); err != nil || !scanned.Equal(m) {
+			return false
+		}
+
+		minor, err := m.Minor()
+		return err == nil && minor == units
+	}
+	require.NoError(t, quick.Check(property, nil))
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		amount   string
+		halfEven string
+		halfUp   string
+		down     string
+	}{
+		{amount: "2.345", halfEven: "2.34", halfUp: "2.35", down: "2.34"},
+		{amount: "2.355", halfEven: "2.36", halfUp: "2.36", down: "2.35"},
+		{amount: "2.349", halfEven: "2.35", halfUp: "2.35", down: "2.34"},
+		{amount: "-2.345", halfEven: "-2.34", halfUp: "-2.35", down: "-2.34"},
+		{amount: "0.0000000001", halfEven: "0", halfUp: "0", down: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			d := decimal.RequireFromString(tt.amount)
+
+			assert.Equal(t, tt.halfEven, money.New(d, usd, money.RoundHalfEven).Amount().String())
+			assert.Equal(t, tt.halfUp, money.New(d, usd, money.RoundHalfUp).Amount().String())
+			assert.Equal(t, tt.down, money.New(d, usd, money.RoundDown).Amount().String())
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	m, err := money.Parse("10.500", usd)
+	require.NoError(t, err)
+	assert.Equal(t, "10.50 USD", m.String())
+
+	_, err = money.Parse("0.0000000001", usd)
+	assert.ErrorIs(t, err, money.ErrScale)
+	_, err = money.Parse("1.5", jpy)
+	assert.ErrorIs(t, err, money.ErrScale)
+	_, err = money.Parse("ten", usd)
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+}
+
+func TestMoney_Operations(t *testing.T) {
+	m := money.FromMinor(100, usd)
+
+	parts, err := m.Allocate(1, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.34 USD", "0.33 USD", "0.33 USD"}, []string{parts[0].String(), parts[1].String(), parts[2].String()})
+
+	parts, err = money.FromMinor(5, usd).Allocate(70, 30)
+	require.NoError(t, err)
+	assert.Equal(t, "0.04 USD", parts[0].String())
+	assert.Equal(t, "0.01 USD", parts[1].String())
+
+	for _, ratios := range [][]int64{nil, {0, 0}, {1, -1}} {
+		_, err = m.Allocate(ratios...)
+		assert.ErrorIs(t, err, money.ErrInvalidRatios, "%v", ratios)
+	}
+
+	fee := m.Mul(decimal.RequireFromString("0.015"), money.RoundHalfUp)
+	assert.Equal(t, "0.02 USD", fee.String())
+
+	_, err = m.Add(money.FromMinor(1, jpy))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	_, err = m.Cmp(money.Zero(bhd))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = money.New(decimal.NewFromInt(math.MaxInt64), usd, money.RoundDown).Minor()
+	assert.ErrorIs(t, err, money.ErrInvalidAmount)
+}
+
+func TestEncoding(t *testing.T) {
+	data, err := json.Marshal(money.FromMinor(1050, usd))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": "10.50", "currency": "USD"}`, string(data))
+
+	var m money.Money
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount": "1", "currency": "XXX"}`), &m), money.ErrInvalidCurrency)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount": "1.001", "currency": "USD"}`), &m), money.ErrScale)
+
+	xts, err := money.NewCurrency("XTS", 4)
+	require.NoError(t, err)
+	require.NoError(t, money.RegisterCurrency(xts))
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": "1.0001", "currency": "XTS"}`), &m))
+	assert.Equal(t, xts, m.Currency())
+
+	_, err = money.NewCurrency("usd", 2)
+	assert.ErrorIs(t, err, money.ErrInvalidCurrency)
+
+	assert.Error(t, m.Scan(1.5), "floats are lossy")
+	var noCurrency money.Money
+	assert.ErrorIs(t, noCurrency.Scan("1"), money.ErrInvalidCurrency)
+	_, err = noCurrency.Value()
+	assert.ErrorIs(t, err, money.ErrInvalidCurrency)
+
+	scanned := money.Zero(jpy)
+	require.NoError(t, scanned.Scan(int64(42)))
+	assert.Equal(t, "42 JPY", scanned.String())
+}
@@ -0,0 +1,83 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/pkg/openapi"
+)
+
+type node struct {
+	Name     string            `json:"name"`
+	Created  time.Time         `json:"created"`
+	Weight   float64           `json:"weight,omitempty"`
+	Parent   *node             `json:"parent"`
+	Children []node            `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Raw      []byte            `json:"raw,omitempty"`
+	Skipped  string            `json:"-"`
+	hidden   string
+	embedded
+}
+
+type embedded struct {
+	ID int64 `json:"id"`
+}
+
+func TestDocument_Schema(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "v1"})
+
+	assert.Equal(t, &openapi.Schema{Ref: "#/components/schemas/node"}, doc.Schema(node{}))
+	assert.Equal(t, &openapi.Schema{Ref: "#/components/schemas/node"}, doc.Schema(&node{}))
+	assert.Equal(t, &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "integer"}}, doc.Schema([]int{}))
+
+	nodeRef := &openapi.Schema{Ref: "#/components/schemas/node"}
+	assert.Equal(t, &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"name":     {Type: "string"},
+			"created":  {Type: "string", Format: "date-time"},
+			"weight":   {Type: "number"},
+			"parent":   nodeRef,
+			"children": {Type: "array", Items: nodeRef},
+			"labels":   {Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}},
+			"raw":      {Type: "string", Format: "byte"},
+			"id":       {Type: "integer", Format: "int64"},
+		},
+		Required: []string{"name", "created", "id"},
+	}, doc.Components.Schemas["node"])
+	assert.Len(t, doc.Components.Schemas, 1)
+}
+
+func TestDocument_SchemaNameClash(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "v1"})
+	doc.Schema(node{})
+	assert.NotPanics(t, func() { doc.Schema(node{}) })
+
+	type node struct{}
+	assert.Panics(t, func() { doc.Schema(node{}) })
+}
+
+func TestDocument_AddOperation(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "v1"})
+	doc.AddOperation("GET", "/items/{id}", &openapi.Operation{Summary: "get"})
+	doc.AddOperation("DELETE", "/items/{id}", &openapi.Operation{Summary: "delete"})
+
+	assert.Panics(t, func() { doc.AddOperation("get", "/items/{id}", &openapi.Operation{}) })
+
+	raw, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.3",
+		"info": {"title": "test", "version": "v1"},
+		"paths": {"/items/{id}": {
+			"get": {"summary": "get", "responses": null},
+			"delete": {"summary": "delete", "responses": null}
+		}},
+		"components": {}
+	}`, string(raw))
+}
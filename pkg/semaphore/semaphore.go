@@ -1,24 +1,211 @@
+// Package semaphore implements weighted semaphore,
+// acquisition of which can be cancelled via context
 package semaphore
 
-// Semaphore implements semaphore pattern
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrTooHeavy is returned when acquired weight exceeds semaphore size,
+	// such acquisition could never succeed
+	ErrTooHeavy = errors.New("semaphore: weight exceeds semaphore size")
+	// ErrInvalidWeight is returned when acquired weight isn't positive,
+	// such acquisition would take nothing or give places away
+	ErrInvalidWeight = errors.New("semaphore: weight must be positive")
+)
+
+// Semaphore implements semaphore pattern. Waiters are served in FIFO
+// order, so heavy acquisition isn't starved by stream of light ones.
+// Every acquisition must be released exactly once with the same weight
 type Semaphore struct {
-	semaCh chan struct{}
+	size int64
+
+	mu       sync.Mutex
+	acquired int64
+	holders  int64
+	waiters  list.List // of *waiter
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{} // closed when semaphore is acquired
+}
+
+// Stats is snapshot of semaphore state
+type Stats struct {
+	Size     int64 // Max total weight
+	Acquired int64 // Currently acquired weight
+	Holders  int64 // Count of unreleased acquisitions
+	Waiters  int64 // Count of blocked acquisitions
 }
 
+// New creates semaphore of maxReq places. It panics if maxReq isn't positive,
+// such semaphore could never be acquired
 func New(maxReq int) *Semaphore {
-	return &Semaphore{
-		semaCh: make(chan struct{}, maxReq),
+	if maxReq < 1 {
+		panic("semaphore: size must be positive")
 	}
+	return &Semaphore{size: int64(maxReq)}
 }
 
-// Acquire take place in semaphore channel
-// if channel full method will sleep
-// until channel will be Release
+// Acquire take place in semaphore,
+// if semaphore is full method will sleep
+// until place will be Release
 func (s *Semaphore) Acquire() {
-	s.semaCh <- struct{}{}
+	if err := s.AcquireN(context.Background(), 1); err != nil {
+		// unreachable: context is never done and size is positive
+		panic(err)
+	}
+}
+
+// AcquireContext take place in semaphore or returns
+// ctx.Err() if ctx is done before place is released
+func (s *Semaphore) AcquireContext(ctx context.Context) error {
+	return s.AcquireN(ctx, 1)
+}
+
+// AcquireN takes n places in semaphore, waiting until ctx is done.
+// It returns ErrInvalidWeight if n isn't positive and ErrTooHeavy if n
+// exceeds size, unlike TryAcquireN, which has no error to report them.
+// On failure semaphore stays unchanged
+func (s *Semaphore) AcquireN(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return ErrInvalidWeight
+	}
+	if n > s.size {
+		return ErrTooHeavy
+	}
+
+	s.mu.Lock()
+	if s.size-s.acquired >= n && s.waiters.Len() == 0 {
+		s.acquired += n
+		s.holders++
+		s.mu.Unlock()
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	w := waiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(&w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// acquired right after cancellation, prefer success
+			// to not lose places released to this waiter
+			return nil
+		default:
+		}
+
+		isFront := s.waiters.Front() == elem
+		s.waiters.Remove(elem)
+		// waiters behind the removed front one may fit now
+		if isFront && s.size > s.acquired {
+			s.notifyWaiters()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes place in semaphore without blocking
+// and reports whether it succeeded
+func (s *Semaphore) TryAcquire() bool {
+	return s.TryAcquireN(1)
 }
 
-// Release place in semaphore channel
+// TryAcquireN takes n places in semaphore without blocking
+// and reports whether it succeeded, so n exceeding size just fails.
+// It panics if n isn't positive, as misuse can't be told from busy
+// semaphore by result, while AcquireN returns ErrInvalidWeight
+func (s *Semaphore) TryAcquireN(n int64) bool {
+	if n <= 0 {
+		panic(ErrInvalidWeight)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size-s.acquired < n || s.waiters.Len() > 0 {
+		return false
+	}
+
+	s.acquired += n
+	s.holders++
+
+	return true
+}
+
+// Release place in semaphore
 func (s *Semaphore) Release() {
-	<-s.semaCh
+	s.ReleaseN(1)
+}
+
+// ReleaseN releases n places taken by one acquisition. It panics
+// if n isn't positive or more than held is released, semaphore
+// stays unchanged then
+func (s *Semaphore) ReleaseN(n int64) {
+	if n <= 0 {
+		panic(ErrInvalidWeight)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n > s.acquired || s.holders == 0 {
+		panic("semaphore: released more than held")
+	}
+
+	s.acquired -= n
+	s.holders--
+	s.notifyWaiters()
+}
+
+// Stats returns current state of semaphore
+func (s *Semaphore) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Stats{
+		Size:     s.size,
+		Acquired: s.acquired,
+		Holders:  s.holders,
+		Waiters:  int64(s.waiters.Len()),
+	}
+}
+
+// notifyWaiters wakes waiters in FIFO order while they fit, s.mu must be held
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(*waiter)
+		if s.size-s.acquired < w.n {
+			// keep FIFO order: don't let lighter waiters overtake the front one
+			return
+		}
+
+		s.acquired += w.n
+		s.holders++
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
 }
@@ -0,0 +1,221 @@
+package semaphore_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/pkg/semaphore"
+)
+
+// waitFor polls cond until it is true or test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	require.Eventually(t, cond, time.Second, time.Millisecond)
+}
+
+func TestSemaphore_TryAcquire(t *testing.T) {
+	sem := semaphore.New(2)
+
+	assert.True(t, sem.TryAcquire())
+	assert.True(t, sem.TryAcquire())
+	assert.False(t, sem.TryAcquire())
+
+	sem.Release()
+	assert.True(t, sem.TryAcquire())
+
+	assert.Equal(t, semaphore.Stats{Size: 2, Acquired: 2, Holders: 2}, sem.Stats())
+}
+
+func TestSemaphore_AcquireContext(t *testing.T) {
+	t.Run("acquire after release", func(t *testing.T) {
+		sem := semaphore.New(1)
+		sem.Acquire()
+
+		acquired := make(chan error)
+		go func() {
+			acquired <- sem.AcquireContext(context.Background())
+		}()
+
+		waitFor(t, func() bool { return sem.Stats().Waiters == 1 })
+		sem.Release()
+
+		require.NoError(t, <-acquired)
+		assert.Equal(t, semaphore.Stats{Size: 1, Acquired: 1, Holders: 1}, sem.Stats())
+	})
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		sem := semaphore.New(1)
+		sem.Acquire()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		acquired := make(chan error)
+		go func() {
+			acquired <- sem.AcquireContext(ctx)
+		}()
+
+		waitFor(t, func() bool { return sem.Stats().Waiters == 1 })
+		cancel()
+
+		assert.ErrorIs(t, <-acquired, context.Canceled)
+		assert.Equal(t, semaphore.Stats{Size: 1, Acquired: 1, Holders: 1}, sem.Stats())
+	})
+	t.Run("deadline exceeded", func(t *testing.T) {
+		sem := semaphore.New(1)
+		sem.Acquire()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, sem.AcquireContext(ctx), context.DeadlineExceeded)
+		assert.Equal(t, int64(0), sem.Stats().Waiters)
+	})
+	t.Run("done context with free place", func(t *testing.T) {
+		sem := semaphore.New(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// free place is taken without waiting, like select with ready channel
+		assert.NoError(t, sem.AcquireContext(ctx))
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		assert.Panics(t, func() { semaphore.New(size) })
+	}
+}
+
+func TestSemaphore_AcquireN(t *testing.T) {
+	t.Run("too heavy", func(t *testing.T) {
+		sem := semaphore.New(3)
+
+		assert.ErrorIs(t, sem.AcquireN(context.Background(), 4), semaphore.ErrTooHeavy)
+		assert.False(t, sem.TryAcquireN(4))
+	})
+	t.Run("not positive weight", func(t *testing.T) {
+		sem := semaphore.New(3)
+
+		for _, n := range []int64{0, -1} {
+			assert.ErrorIs(t, sem.AcquireN(context.Background(), n), semaphore.ErrInvalidWeight)
+			assert.Panics(t, func() { sem.TryAcquireN(n) })
+		}
+		assert.Equal(t, semaphore.Stats{Size: 3}, sem.Stats())
+	})
+	t.Run("fifo order", func(t *testing.T) {
+		sem := semaphore.New(3)
+		sem.Acquire()
+		sem.Acquire()
+
+		// heavy waiter comes first and blocks lighter one
+		heavy := make(chan error)
+		go func() {
+			heavy <- sem.AcquireN(context.Background(), 3)
+		}()
+		waitFor(t, func() bool { return sem.Stats().Waiters == 1 })
+
+		assert.False(t, sem.TryAcquire(), "light acquisition must not overtake waiter")
+
+		sem.Release()
+		sem.Release()
+
+		require.NoError(t, <-heavy)
+		assert.Equal(t, semaphore.Stats{Size: 3, Acquired: 3, Holders: 1}, sem.Stats())
+
+		sem.ReleaseN(3)
+		assert.Equal(t, semaphore.Stats{Size: 3}, sem.Stats())
+	})
+	t.Run("cancelled front waiter lets others pass", func(t *testing.T) {
+		sem := semaphore.New(3)
+		sem.AcquireN(context.Background(), 2)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		heavy := make(chan error)
+		go func() {
+			heavy <- sem.AcquireN(ctx, 3)
+		}()
+		waitFor(t, func() bool { return sem.Stats().Waiters == 1 })
+
+		light := make(chan error)
+		go func() {
+			light <- sem.AcquireN(context.Background(), 1)
+		}()
+		waitFor(t, func() bool { return sem.Stats().Waiters == 2 })
+
+		cancel()
+
+		assert.ErrorIs(t, <-heavy, context.Canceled)
+		require.NoError(t, <-light)
+		assert.Equal(t, semaphore.Stats{Size: 3, Acquired: 3, Holders: 2}, sem.Stats())
+	})
+}
+
+func TestSemaphore_Release(t *testing.T) {
+	sem := semaphore.New(2)
+	assert.Panics(t, sem.Release)
+
+	sem.Acquire()
+	assert.Panics(t, func() { sem.ReleaseN(2) })
+	assert.Panics(t, func() { sem.ReleaseN(0) })
+	assert.Panics(t, func() { sem.ReleaseN(-1) })
+
+	// failed releases change nothing
+	assert.Equal(t, semaphore.Stats{Size: 2, Acquired: 1, Holders: 1}, sem.Stats())
+	sem.Release()
+	assert.Equal(t, semaphore.Stats{Size: 2}, sem.Stats())
+}
+
+// TestSemaphore_Concurrent checks with race detector,
+// that acquired weight never exceeds semaphore size
+func TestSemaphore_Concurrent(t *testing.T) {
+	const (
+		size       = 5
+		goroutines = 50
+		iterations = 100
+	)
+
+	sem := semaphore.New(size)
+
+	var (
+		inUse   atomic.Int64
+		maxSeen atomic.Int64
+		wg      sync.WaitGroup
+	)
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			n := int64(g%size + 1)
+			for i := range iterations {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Duration(i%3)*time.Millisecond)
+				err := sem.AcquireN(ctx, n)
+				cancel()
+				if err != nil {
+					continue
+				}
+
+				cur := inUse.Add(n)
+				for {
+					seen := maxSeen.Load()
+					if cur <= seen || maxSeen.CompareAndSwap(seen, cur) {
+						break
+					}
+				}
+
+				inUse.Add(-n)
+				sem.ReleaseN(n)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxSeen.Load(), int64(size))
+	assert.Equal(t, semaphore.Stats{Size: size}, sem.Stats())
+}
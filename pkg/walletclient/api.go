@@ -0,0 +1,345 @@
+package walletclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SendRequest is transfer of Amount from one wallet to another
+type SendRequest struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type Transaction struct {
+	ID        int             `json:"id"`
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Amount    decimal.Decimal `json:"amount"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Statement row types, statement is complete only if it ends with closing row
+const (
+	StatementOpening     = "opening"
+	StatementTransaction = "transaction"
+	StatementClosing     = "closing"
+)
+
+// StatementRow is one row of wallet statement. Amount is signed
+// change of balance by transaction, Balance is balance after it
+type StatementRow struct {
+	Type      string              `json:"type"`
+	ID        int                 `json:"id,omitempty"`
+	Timestamp time.Time           `json:"timestamp"`
+	From      string              `json:"from_address,omitempty"`
+	To        string              `json:"to_address,omitempty"`
+	Amount    decimal.NullDecimal `json:"amount"`
+	Balance   decimal.Decimal     `json:"balance"`
+}
+
+// ChainReport is result of transactions hash chain verification
+type ChainReport struct {
+	Valid               bool        `json:"valid"`
+	Checked             int         `json:"checked"`
+	Unchained           int         `json:"unchained"`
+	CheckpointsVerified int         `json:"checkpoints_verified"`
+	Head                *ChainLink  `json:"head,omitempty"`
+	BrokenLink          *BrokenLink `json:"broken_link,omitempty"`
+}
+
+type ChainLink struct {
+	TransactionID int    `json:"transaction_id"`
+	Hash          string `json:"hash"`
+}
+
+// BrokenLink describes the first transaction or checkpoint, that breaks chain
+type BrokenLink struct {
+	TransactionID int    `json:"transaction_id"`
+	CheckpointID  int    `json:"checkpoint_id,omitempty"`
+	Reason        string `json:"reason"`
+	Expected      string `json:"expected,omitempty"`
+	Actual        string `json:"actual,omitempty"`
+}
+
+// AuditRecord is record of state-changing action. Before and After are
+// changed state, they are empty if state didn't exist or action failed
+type AuditRecord struct {
+	ID           int               `json:"id"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Actor        string            `json:"actor"`
+	SourceIP     string            `json:"source_ip,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	Action       string            `json:"action"`
+	Wallet       string            `json:"wallet,omitempty"`
+	Counterparty string            `json:"counterparty,omitempty"`
+	Before       map[string]string `json:"before,omitempty"`
+	After        map[string]string `json:"after,omitempty"`
+	Outcome      string            `json:"outcome"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// AuditQuery selects audit records, zero fields match any record.
+// Wallet matches counterparty of transfer too, period is [From, To)
+type AuditQuery struct {
+	Actor   string
+	Wallet  string
+	From    time.Time
+	To      time.Time
+	AfterID int
+	Limit   int
+}
+
+// AuditPage is page of audit records, the next page is requested
+// with AfterID set to NextAfterID, that is zero on the last page
+type AuditPage struct {
+	Records     []AuditRecord `json:"records"`
+	NextAfterID int           `json:"next_after_id,omitempty"`
+}
+
+// Levels of app logger
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+// ErrTruncatedStatement is returned when statement stream ends without closing row
+var ErrTruncatedStatement = errors.New("walletclient: statement is truncated")
+
+// Send transfers amount between wallets. Failed errors are *Error,
+// e.g. ErrLackOfCurrency, ErrWalletFrozen or ErrNotFound
+func (c *Client) Send(ctx context.Context, req SendRequest) error {
+	resp, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/send",
+		body:   req,
+		// the same key for every retry of this call
+		header: http.Header{IdempotencyKeyHeader: {newIdempotencyKey()}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var body struct{}
+	return decodeJSON(resp, &body)
+}
+
+// LastTransactions returns count of the last successful transactions
+func (c *Client) LastTransactions(ctx context.Context, count int) ([]Transaction, error) {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/transactions",
+		query:      url.Values{"count": {strconv.Itoa(count)}},
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	if err := decodeJSON(resp, &body); err != nil {
+		return nil, err
+	}
+	return body.Transactions, nil
+}
+
+// Balance returns current balance of wallet
+func (c *Client) Balance(ctx context.Context, address string) (decimal.Decimal, error) {
+	return c.balance(ctx, address, nil)
+}
+
+// BalanceAt returns balance of wallet at moment, it fails with
+// ErrHistoryUnavailable if moment is older than kept history
+func (c *Client) BalanceAt(ctx context.Context, address string, at time.Time) (decimal.Decimal, error) {
+	return c.balance(ctx, address, url.Values{"at": {at.Format(time.RFC3339Nano)}})
+}
+
+func (c *Client) balance(ctx context.Context, address string, query url.Values) (decimal.Decimal, error) {
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/wallet/" + url.PathEscape(address) + "/balance",
+		query:      query,
+		idempotent: true,
+	})
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	var body struct {
+		Balance decimal.Decimal `json:"balance"`
+	}
+	if err := decodeJSON(resp, &body); err != nil {
+		return decimal.Decimal{}, err
+	}
+	return body.Balance, nil
+}
+
+// Statement streams rows of wallet statement for period (from, to] to
+// yield, zero to means now. Stream, that ends without closing row, fails with
+// ErrTruncatedStatement. Error of yield stops streaming and is returned as is
+func (c *Client) Statement(ctx context.Context, address string, from, to time.Time, yield func(StatementRow) error) error {
+	query := url.Values{
+		"from":   {from.Format(time.RFC3339Nano)},
+		"format": {"jsonl"},
+	}
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.RFC3339Nano))
+	}
+
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/wallet/" + url.PathEscape(address) + "/statement",
+		query:      query,
+		idempotent: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	closed := false
+	for decoder.More() {
+		var row StatementRow
+		if err := decoder.Decode(&row); err != nil {
+			return fmt.Errorf("%w: %w", ErrTruncatedStatement, err)
+		}
+		if err := yield(row); err != nil {
+			return err
+		}
+		closed = row.Type == StatementClosing
+	}
+	if !closed {
+		return ErrTruncatedStatement
+	}
+
+	return nil
+}
+
+// VerifyChain verifies transactions hash chain, broken chain is
+// reported by report with Valid false. It requires admin token
+func (c *Client) VerifyChain(ctx context.Context) (ChainReport, error) {
+	if c.adminToken == "" {
+		return ChainReport{}, errNoAdminToken
+	}
+
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/admin/chain/verify",
+		header:     http.Header{"Authorization": {"Bearer " + c.adminToken}},
+		idempotent: true,
+	}, http.StatusConflict)
+	if err != nil {
+		return ChainReport{}, err
+	}
+
+	var report ChainReport
+	if err := decodeJSON(resp, &report); err != nil {
+		return ChainReport{}, err
+	}
+	return report, nil
+}
+
+// Audit returns page of audit records matching query. It requires admin token
+func (c *Client) Audit(ctx context.Context, query AuditQuery) (AuditPage, error) {
+	if c.adminToken == "" {
+		return AuditPage{}, errNoAdminToken
+	}
+
+	values := url.Values{}
+	for key, value := range map[string]string{"actor": query.Actor, "wallet": query.Wallet} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	for key, moment := range map[string]time.Time{"from": query.From, "to": query.To} {
+		if !moment.IsZero() {
+			values.Set(key, moment.Format(time.RFC3339Nano))
+		}
+	}
+	for key, number := range map[string]int{"after_id": query.AfterID, "limit": query.Limit} {
+		if number != 0 {
+			values.Set(key, strconv.Itoa(number))
+		}
+	}
+
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/admin/audit",
+		query:      values,
+		header:     http.Header{"Authorization": {"Bearer " + c.adminToken}},
+		idempotent: true,
+	})
+	if err != nil {
+		return AuditPage{}, err
+	}
+
+	var page AuditPage
+	if err := decodeJSON(resp, &page); err != nil {
+		return AuditPage{}, err
+	}
+	return page, nil
+}
+
+// LogLevel returns level of app logger, e.g. LogLevelInfo. It requires admin token
+func (c *Client) LogLevel(ctx context.Context) (string, error) {
+	if c.adminToken == "" {
+		return "", errNoAdminToken
+	}
+
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/admin/log/level",
+		header:     http.Header{"Authorization": {"Bearer " + c.adminToken}},
+		idempotent: true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var body logLevel
+	if err := decodeJSON(resp, &body); err != nil {
+		return "", err
+	}
+	return body.Level, nil
+}
+
+// SetLogLevel changes level of app logger until its restart. Unknown
+// level fails with ErrInvalidRequest. It requires admin token
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	if c.adminToken == "" {
+		return errNoAdminToken
+	}
+
+	resp, err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   "/admin/log/level",
+		body:   logLevel{Level: level},
+		header: http.Header{"Authorization": {"Bearer " + c.adminToken}},
+		// setting the same level again changes nothing
+		idempotent: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var body logLevel
+	return decodeJSON(resp, &body)
+}
@@ -0,0 +1,256 @@
+// Package walletclient is Go client of wallet api v1.
+//
+// Failed requests are retried with exponential backoff and jitter, requests
+// are retried on transport errors and 5xx or 429 responses. Send carries
+// Idempotency-Key header, which is kept across retries of the same call. Server
+// doesn't deduplicate sends by it yet, so sends are retried only on 429 and 503
+// responses, that guarantee transfer wasn't applied. Error responses are
+// returned as *Error, that matches sentinel errors of problem codes via errors.Is
+package walletclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiPath is path of api version served by client
+const apiPath = "/api/v1"
+
+// Headers of requests
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	readYourWritesHeader = "X-Read-Your-Writes"
+)
+
+// Config describes client. Zero values mean defaults: http.DefaultClient,
+// 3 retries and backoff from 100ms to 2s. Negative MaxRetries disables retries.
+// AdminToken is only required by admin methods
+type Config struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	AdminToken string
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Client is safe for concurrent use
+type Client struct {
+	baseURL    *url.URL
+	http       *http.Client
+	adminToken string
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// New creates client of api served at cfg.BaseURL, e.g. http://localhost:8080
+func New(cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("walletclient: invalid base url: %w", err)
+	}
+	if baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("walletclient: base url %q must be absolute", cfg.BaseURL)
+	}
+
+	client := &Client{
+		baseURL:    baseURL,
+		http:       cfg.HTTPClient,
+		adminToken: cfg.AdminToken,
+		maxRetries: cfg.MaxRetries,
+		minBackoff: cfg.MinBackoff,
+		maxBackoff: cfg.MaxBackoff,
+	}
+	if client.http == nil {
+		client.http = http.DefaultClient
+	}
+	switch {
+	case client.maxRetries == 0:
+		client.maxRetries = 3
+	case client.maxRetries < 0:
+		client.maxRetries = 0
+	}
+	if client.minBackoff <= 0 {
+		client.minBackoff = 100 * time.Millisecond
+	}
+	if client.maxBackoff <= 0 {
+		client.maxBackoff = 2 * time.Second
+	}
+	client.maxBackoff = max(client.maxBackoff, client.minBackoff)
+
+	return client, nil
+}
+
+type readYourWritesKey struct{}
+
+// WithReadYourWrites makes reads of requests with ctx
+// see all committed writes, they are served by primary database
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+// request is api request, path is relative to api version
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	header http.Header
+	// idempotent request is retried on any retryable failure, other
+	// ones only on responses proving that request wasn't applied
+	idempotent bool
+}
+
+// do sends request until it succeeds or retries are exhausted. Response
+// with status below 400 or in accepted is returned with unread body,
+// other responses are returned as errors
+func (c *Client) do(ctx context.Context, r request, accepted ...int) (*http.Response, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, fmt.Errorf("walletclient: encode request: %w", err)
+		}
+	}
+
+	target := c.baseURL.JoinPath(apiPath, r.path)
+	target.RawQuery = r.query.Encode()
+
+	retryOn := isNotApplied
+	if r.idempotent {
+		retryOn = isRetryable
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, r, target.String(), body)
+		if err == nil && (resp.StatusCode < http.StatusBadRequest || slices.Contains(accepted, resp.StatusCode)) {
+			return resp, nil
+		}
+
+		var retryAfter time.Duration
+		if err == nil {
+			retry := retryOn(resp.StatusCode)
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			err = decodeError(resp)
+			if !retry {
+				return nil, err
+			}
+		} else if ctx.Err() != nil || !r.idempotent {
+			// outcome of not idempotent request is unknown after transport error
+			return nil, err
+		}
+
+		if attempt >= c.maxRetries {
+			return nil, err
+		}
+		if err := c.sleep(ctx, attempt, retryAfter); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, r request, target string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("walletclient: %w", err)
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if force, _ := ctx.Value(readYourWritesKey{}).(bool); force {
+		req.Header.Set(readYourWritesHeader, "true")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("walletclient: %s %s: %w", r.method, r.path, err)
+	}
+
+	return resp, nil
+}
+
+// sleep waits before retry, it's Retry-After of response if server sent it
+// or random duration up to exponentially growing backoff
+func (c *Client) sleep(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 {
+		backoff := c.minBackoff << min(attempt, 30)
+		if backoff <= 0 || backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+		delay = c.minBackoff/2 + rand.N(backoff-c.minBackoff/2+1)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isRetryable reports whether request may succeed on retry
+func isRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// isNotApplied reports whether status proves that request wasn't applied
+func isNotApplied(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter parses Retry-After header in seconds or http date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// decodeJSON decodes body of successful response into v and closes it
+func decodeJSON(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("walletclient: decode response: %w", err)
+	}
+	return nil
+}
+
+func newIdempotencyKey() string {
+	return uuid.NewString()
+}
+
+var errNoAdminToken = errors.New("walletclient: admin token isn't configured")
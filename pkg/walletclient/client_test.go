@@ -0,0 +1,265 @@
+package walletclient_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/internal/testutil"
+	"github.com/lunn06/wallet/pkg/walletclient"
+)
+
+func newClient(t *testing.T, cfg walletclient.Config) *walletclient.Client {
+	t.Helper()
+
+	client, err := walletclient.New(cfg)
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestClient_API(t *testing.T) {
+	fx := testutil.NewServer(t, nil)
+	server := fx.Listen(t)
+	client := newClient(t, walletclient.Config{BaseURL: server.URL, AdminToken: testutil.AdminToken})
+	ctx := context.Background()
+
+	from := fx.InsertWallet(t, "100").Address
+	to := fx.InsertWallet(t, "0").Address
+	start := time.Now()
+
+	require.NoError(t, client.Send(ctx, walletclient.SendRequest{From: from, To: to, Amount: decimal.RequireFromString("10.5")}))
+
+	balance, err := client.Balance(ctx, to)
+	require.NoError(t, err)
+	assert.Equal(t, "10.5", balance.String())
+
+	balance, err = client.BalanceAt(walletclient.WithReadYourWrites(ctx), from, start)
+	require.NoError(t, err)
+	assert.Equal(t, "100", balance.String())
+
+	transactions, err := client.LastTransactions(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, from, transactions[0].From)
+	assert.Equal(t, to, transactions[0].To)
+	assert.Equal(t, "10.5", transactions[0].Amount.String())
+
+	var rows []walletclient.StatementRow
+	err = client.Statement(ctx, to, start, time.Time{}, func(row walletclient.StatementRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, walletclient.StatementOpening, rows[0].Type)
+	assert.Equal(t, "10.5", rows[1].Amount.Decimal.String())
+	assert.Equal(t, walletclient.StatementClosing, rows[2].Type)
+	assert.Equal(t, "10.5", rows[2].Balance.String())
+
+	report, err := client.VerifyChain(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 1, report.Checked)
+
+	page, err := client.Audit(ctx, walletclient.AuditQuery{Wallet: from, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Records, 1)
+	assert.Equal(t, "transfer", page.Records[0].Action)
+	assert.Equal(t, "success", page.Records[0].Outcome)
+	assert.Zero(t, page.NextAfterID)
+}
+
+func TestClient_LogLevel(t *testing.T) {
+	fx := testutil.NewServer(t, nil)
+	server := fx.Listen(t)
+	client := newClient(t, walletclient.Config{BaseURL: server.URL, AdminToken: testutil.AdminToken})
+	ctx := context.Background()
+
+	level, err := client.LogLevel(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, walletclient.LogLevelInfo, level)
+
+	require.NoError(t, client.SetLogLevel(ctx, walletclient.LogLevelDebug))
+	assert.Equal(t, slog.LevelDebug, fx.Level.Level())
+
+	level, err = client.LogLevel(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, walletclient.LogLevelDebug, level)
+
+	err = client.SetLogLevel(ctx, "verbose")
+	assert.ErrorIs(t, err, walletclient.ErrInvalidRequest)
+	assert.Equal(t, slog.LevelDebug, fx.Level.Level())
+
+	client = newClient(t, walletclient.Config{BaseURL: server.URL})
+	_, err = client.LogLevel(ctx)
+	assert.Error(t, err)
+	assert.Error(t, client.SetLogLevel(ctx, walletclient.LogLevelError))
+}
+
+func TestClient_Errors(t *testing.T) {
+	fx := testutil.NewServer(t, nil)
+	server := fx.Listen(t)
+	client := newClient(t, walletclient.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	from := fx.InsertWallet(t, "1").Address
+	to := fx.InsertWallet(t, "0").Address
+
+	err := client.Send(ctx, walletclient.SendRequest{From: from, To: to, Amount: decimal.NewFromInt(2)})
+	assert.ErrorIs(t, err, walletclient.ErrLackOfCurrency)
+
+	_, err = client.Balance(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, walletclient.ErrNotFound)
+
+	err = client.Send(ctx, walletclient.SendRequest{From: "wallet", To: to, Amount: decimal.NewFromInt(1)})
+	var apiErr *walletclient.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	assert.Equal(t, []walletclient.FieldError{{Field: "from", Rule: "uuid4", Message: "must be uuid"}}, apiErr.Fields)
+	assert.True(t, errors.Is(err, walletclient.ErrInvalidRequest))
+	assert.False(t, errors.Is(err, walletclient.ErrNotFound))
+
+	_, err = client.VerifyChain(ctx)
+	assert.Error(t, err)
+
+	client = newClient(t, walletclient.Config{BaseURL: server.URL, AdminToken: "wrong"})
+	_, err = client.VerifyChain(ctx)
+	assert.ErrorIs(t, err, walletclient.ErrUnauthorized)
+}
+
+// flakyServer responds with statuses in order and records requests
+type flakyServer struct {
+	mu       sync.Mutex
+	statuses []int
+	keys     []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = append(s.keys, r.Header.Get(walletclient.IdempotencyKeyHeader))
+
+	status := http.StatusOK
+	if len(s.keys) <= len(s.statuses) {
+		status = s.statuses[len(s.keys)-1]
+	}
+	if status == http.StatusOK {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"balance":"1","transactions":[]}`))
+		return
+	}
+	if status == http.StatusBadGateway {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("<html>bad gateway</html>"))
+		return
+	}
+
+	code := map[int]string{
+		http.StatusTooManyRequests:     "TOO_MANY_REQUESTS",
+		http.StatusInternalServerError: "INTERNAL_SERVER_ERROR",
+		http.StatusServiceUnavailable:  "SERVICE_UNAVAILABLE",
+	}[status]
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`{"status":` + strconv.Itoa(status) + `,"code":"` + code + `"}`))
+}
+
+func (s *flakyServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.keys
+}
+
+func newFlakyClient(t *testing.T, statuses ...int) (*walletclient.Client, *flakyServer) {
+	t.Helper()
+
+	flaky := &flakyServer{statuses: statuses}
+	server := httptest.NewServer(flaky)
+	t.Cleanup(server.Close)
+
+	client := newClient(t, walletclient.Config{
+		BaseURL:    server.URL,
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 2 * time.Millisecond,
+	})
+	return client, flaky
+}
+
+func TestClient_Retries(t *testing.T) {
+	ctx := context.Background()
+	send := walletclient.SendRequest{From: uuid.NewString(), To: uuid.NewString(), Amount: decimal.NewFromInt(1)}
+
+	t.Run("read retried on 5xx and 429", func(t *testing.T) {
+		client, flaky := newFlakyClient(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+
+		balance, err := client.Balance(ctx, uuid.NewString())
+		require.NoError(t, err)
+		assert.Equal(t, "1", balance.String())
+		assert.Len(t, flaky.requests(), 3)
+	})
+	t.Run("retries are exhausted", func(t *testing.T) {
+		client, flaky := newFlakyClient(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+
+		_, err := client.LastTransactions(ctx, 1)
+		var apiErr *walletclient.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, &walletclient.Error{Status: http.StatusBadGateway, Title: "Bad Gateway"}, apiErr)
+		assert.Len(t, flaky.requests(), 3)
+	})
+	t.Run("send retried with the same idempotency key", func(t *testing.T) {
+		client, flaky := newFlakyClient(t, http.StatusServiceUnavailable)
+
+		require.NoError(t, client.Send(ctx, send))
+
+		keys := flaky.requests()
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+
+		require.NoError(t, client.Send(ctx, send))
+		assert.NotEqual(t, keys[0], flaky.requests()[2], "every call has own key")
+	})
+	t.Run("send isn't retried when it may be applied", func(t *testing.T) {
+		client, flaky := newFlakyClient(t, http.StatusInternalServerError)
+
+		err := client.Send(ctx, send)
+		assert.ErrorIs(t, err, walletclient.ErrInternal)
+		assert.Len(t, flaky.requests(), 1)
+	})
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		flaky := &flakyServer{statuses: []int{http.StatusServiceUnavailable}}
+		server := httptest.NewServer(flaky)
+		t.Cleanup(server.Close)
+		client := newClient(t, walletclient.Config{BaseURL: server.URL, MinBackoff: time.Hour})
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.Balance(ctx, uuid.NewString())
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Len(t, flaky.requests(), 1)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := walletclient.New(walletclient.Config{BaseURL: "localhost:8080"})
+	assert.Error(t, err)
+
+	_, err = walletclient.New(walletclient.Config{BaseURL: "http://localhost:8080/"})
+	assert.NoError(t, err)
+}
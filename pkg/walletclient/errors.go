@@ -0,0 +1,96 @@
+package walletclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Error is error response of api. Code is machine-readable kind of error,
+// Fields lists invalid fields of request. Responses, that aren't problem
+// details (e.g. of proxy), have only Status and Title
+type Error struct {
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Title     string       `json:"title"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Fields    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes invalid field of request,
+// Rule is name of violated validation rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Sentinel errors of problem codes, use them with errors.Is
+var (
+	ErrInvalidRequest     = &Error{Code: "INVALID_REQUEST_BODY"}
+	ErrInvalidArgument    = &Error{Code: "INVALID_ARGUMENT"}
+	ErrUnauthorized       = &Error{Code: "UNAUTHORIZED"}
+	ErrLackOfCurrency     = &Error{Code: "LACK_OF_CURRENCY"}
+	ErrWalletFrozen       = &Error{Code: "WALLET_FROZEN"}
+	ErrNotFound           = &Error{Code: "NOT_FOUND"}
+	ErrDuplicate          = &Error{Code: "DUPLICATE_ERROR"}
+	ErrPayloadTooLarge    = &Error{Code: "PAYLOAD_TOO_LARGE"}
+	ErrHistoryUnavailable = &Error{Code: "HISTORY_UNAVAILABLE"}
+	ErrInternal           = &Error{Code: "INTERNAL_SERVER_ERROR"}
+	ErrUnavailable        = &Error{Code: "SERVICE_UNAVAILABLE"}
+)
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "walletclient: %d", e.Status)
+	if e.Code != "" {
+		b.WriteString(" " + e.Code)
+	}
+	if e.Detail != "" {
+		b.WriteString(": " + e.Detail)
+	} else if e.Title != "" {
+		b.WriteString(": " + e.Title)
+	}
+	for _, field := range e.Fields {
+		fmt.Fprintf(&b, "; %s %s", field.Field, field.Message)
+	}
+	if e.RequestID != "" {
+		b.WriteString(" (request id " + e.RequestID + ")")
+	}
+	return b.String()
+}
+
+// Is matches errors by code, so errors.Is(err, ErrNotFound)
+// reports whether err is NOT_FOUND error response
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// maxErrorBody bounds read body of error response
+const maxErrorBody = 64 << 10
+
+// decodeError makes *Error of error response and closes its body
+func decodeError(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	apiErr := &Error{Status: resp.StatusCode, Title: http.StatusText(resp.StatusCode)}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "application/problem+json" {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		return apiErr
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(apiErr); err != nil {
+		return &Error{Status: resp.StatusCode, Title: http.StatusText(resp.StatusCode)}
+	}
+	apiErr.Status = resp.StatusCode
+
+	return apiErr
+}
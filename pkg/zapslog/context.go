@@ -0,0 +1,42 @@
+package zapslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+type attrsKey struct{}
+
+// ContextWithAttrs returns ctx, which attrs are added to every record logged
+// with it by logger of Init, e.g. to tag all logs of request with its id
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if existing, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		attrs = append(existing[:len(existing):len(existing)], attrs...)
+	}
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// contextHandler adds attrs of ContextWithAttrs to records
+type contextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps handler to add attrs of ContextWithAttrs to records
+func NewContextHandler(handler slog.Handler) slog.Handler {
+	return contextHandler{Handler: handler}
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
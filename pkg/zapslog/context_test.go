@@ -0,0 +1,28 @@
+package zapslog_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lunn06/wallet/pkg/zapslog"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(zapslog.NewContextHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	ctx := zapslog.ContextWithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = zapslog.ContextWithAttrs(ctx, slog.String("caller", "ops"))
+
+	logger.InfoContext(ctx, "handled")
+	assert.Contains(t, buf.String(), "component=test")
+	assert.Contains(t, buf.String(), "request_id=req-1 caller=ops")
+
+	buf.Reset()
+	logger.Info("without context")
+	assert.NotContains(t, buf.String(), "request_id")
+}
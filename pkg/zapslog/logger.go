@@ -1,8 +1,12 @@
 package zapslog
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/natefinch/lumberjack"
 	"go.uber.org/zap"
@@ -10,51 +14,154 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-func Init(debug bool) (*slog.Logger, func()) {
-	cfg := zapcore.EncoderConfig{
-		MessageKey:     "msg",
-		LevelKey:       "level",
-		TimeKey:        "timestamp",
-		NameKey:        "logger",
-		CallerKey:      "caller",
-		FunctionKey:    zapcore.OmitKey,
-		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.CapitalLevelEncoder,
-		EncodeTime:     zapcore.ISO8601TimeEncoder,
-		EncodeDuration: zapcore.SecondsDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
-	}
+// Formats of records
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
 
-	level := zap.InfoLevel
-	fileName := "logs/app.log"
-	if debug {
-		level = zap.DebugLevel
-		fileName = "logs/debug.log"
-	}
+// Standard streams in outputs of Config, other outputs are paths of files
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
 
-	fileEncoder := zapcore.NewJSONEncoder(cfg)
-	consoleEncoder := zapcore.NewConsoleEncoder(cfg)
+// Config describes logger. Level can be changed at runtime, e.g. by
+// *slog.LevelVar, nil Level means info. Format is "json" or "console",
+// Outputs are standard streams or paths of files rotated by Rotation.
+// Redact masks sensitive attrs and messages (see NewRedactHandler)
+type Config struct {
+	Level    slog.Leveler
+	Format   string
+	Outputs  []string
+	Rotation Rotation
+	Sampling Sampling
+	Redact   bool
+}
 
-	file := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   fileName,
-		MaxSize:    10, // Mb
-		MaxAge:     7,  // days
-		MaxBackups: 3,
-	})
+// Sampling limits records with the same level and message per Tick: the first
+// Initial ones are logged, then every Thereafter one and zero Thereafter drops
+// the rest. Zero Initial disables sampling, zero Tick means a second
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+func Init(c Config) (*slog.Logger, func(), error) {
+	cfg := encoderConfig()
 
-	core := zapcore.NewCore(fileEncoder, file, level)
-	if debug {
-		core = zapcore.NewTee(
-			zapcore.NewCore(fileEncoder, file, level),
-			zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), level),
-		)
+	var encoder zapcore.Encoder
+	switch c.Format {
+	case FormatJSON, "":
+		encoder = zapcore.NewJSONEncoder(cfg)
+	case FormatConsole:
+		encoder = zapcore.NewConsoleEncoder(cfg)
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q", c.Format)
+	}
+
+	if len(c.Outputs) == 0 {
+		return nil, nil, errors.New("no log outputs")
+	}
+	writers := make([]zapcore.WriteSyncer, 0, len(c.Outputs))
+	for _, output := range c.Outputs {
+		switch output {
+		case Stdout:
+			writers = append(writers, zapcore.Lock(os.Stdout))
+		case Stderr:
+			writers = append(writers, zapcore.Lock(os.Stderr))
+		default:
+			writers = append(writers, zapcore.AddSync(c.Rotation.writer(output)))
+		}
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(writers...), levelEnabler(c.Level))
+	if c.Sampling.Initial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, cmp.Or(c.Sampling.Tick, time.Second), c.Sampling.Initial, c.Sampling.Thereafter)
 	}
 
 	logger := zap.New(core)
 
-	sl := slog.New(zapslog.NewHandler(logger.Core()))
+	var handler slog.Handler = zapslog.NewHandler(logger.Core())
+	// attrs of context are redacted too
+	if c.Redact {
+		handler = NewRedactHandler(handler)
+	}
+	sl := slog.New(NewContextHandler(handler))
 
 	return sl, func() {
 		logger.Sync()
+	}, nil
+}
+
+// levelEnabler enables zap levels, that aren't below current level of leveler
+func levelEnabler(leveler slog.Leveler) zapcore.LevelEnabler {
+	if leveler == nil {
+		leveler = slog.LevelInfo
+	}
+
+	return zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		var converted slog.Level
+		switch {
+		case level >= zapcore.ErrorLevel:
+			converted = slog.LevelError
+		case level == zapcore.WarnLevel:
+			converted = slog.LevelWarn
+		case level == zapcore.InfoLevel:
+			converted = slog.LevelInfo
+		default:
+			converted = slog.LevelDebug
+		}
+		return converted >= leveler.Level()
+	})
+}
+
+// Rotation describes rotation of log file. MaxSize is size of file in
+// megabytes, that triggers rotation, MaxAge is count of days rotated files are
+// kept and MaxBackups is count of kept rotated files. Zero values mean 10 Mb,
+// 7 days and 3 files, negative MaxAge or MaxBackups keeps files forever
+type Rotation struct {
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+}
+
+func (r Rotation) writer(file string) *lumberjack.Logger {
+	logger := &lumberjack.Logger{
+		Filename:   file,
+		MaxSize:    cmp.Or(r.MaxSize, 10),
+		MaxAge:     cmp.Or(r.MaxAge, 7),
+		MaxBackups: cmp.Or(r.MaxBackups, 3),
+	}
+	// lumberjack keeps files forever on zero values
+	logger.MaxAge = max(logger.MaxAge, 0)
+	logger.MaxBackups = max(logger.MaxBackups, 0)
+
+	return logger
+}
+
+// NewFileSink creates logger, that writes every record as json line to
+// rotating file. It's separate from logger of Init, e.g. for audit records
+func NewFileSink(file string, rotation Rotation) (*slog.Logger, func() error) {
+	writer := rotation.writer(file)
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig()), zapcore.AddSync(writer), zapcore.DebugLevel)
+
+	return slog.New(zapslog.NewHandler(core)), writer.Close
+}
+
+func encoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		MessageKey:     "msg",
+		LevelKey:       "level",
+		TimeKey:        "timestamp",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		FunctionKey:    zapcore.OmitKey,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 }
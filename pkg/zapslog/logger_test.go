@@ -0,0 +1,85 @@
+package zapslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/pkg/zapslog"
+)
+
+func TestNewFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	sink, closeSink := zapslog.NewFileSink(path, zapslog.Rotation{MaxSize: 1, MaxBackups: -1})
+
+	sink.Info("audit", "action", "transfer", "id", 1)
+	sink.Debug("audit", "action", "wallet.create", "id", 2)
+	require.NoError(t, closeSink())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var lines []map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	for decoder.More() {
+		var line map[string]any
+		require.NoError(t, decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+
+	require.Len(t, lines, 2, "sink must keep records of every level")
+	assert.Equal(t, "transfer", lines[0]["action"])
+	assert.Equal(t, "wallet.create", lines[1]["action"])
+	assert.Contains(t, lines[0], "timestamp")
+}
+
+func TestInit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	level := new(slog.LevelVar)
+
+	logger, sync, err := zapslog.Init(zapslog.Config{
+		Level:    level,
+		Format:   zapslog.FormatJSON,
+		Outputs:  []string{path},
+		Sampling: zapslog.Sampling{Initial: 2, Thereafter: 0, Tick: time.Hour},
+		Redact:   true,
+	})
+	require.NoError(t, err)
+
+	logger.Debug("hidden")
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown", "token", "secret")
+	for range 5 {
+		logger.Info("repeated")
+	}
+	sync()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var messages []string
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	for decoder.More() {
+		var line map[string]any
+		require.NoError(t, decoder.Decode(&line))
+		messages = append(messages, line["msg"].(string))
+	}
+
+	assert.Equal(t, []string{"shown", "repeated", "repeated"}, messages, "level is changed at runtime and repeats are sampled")
+	assert.NotContains(t, string(raw), "secret")
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, _, err := zapslog.Init(zapslog.Config{Format: "text", Outputs: []string{zapslog.Stdout}})
+		assert.Error(t, err)
+
+		_, _, err = zapslog.Init(zapslog.Config{})
+		assert.Error(t, err)
+	})
+}
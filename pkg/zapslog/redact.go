@@ -0,0 +1,149 @@
+package zapslog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces values of sensitive attrs
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are normalized keys of attrs, that hold secrets
+var sensitiveKeys = map[string]struct{}{
+	"authorization":       {},
+	"proxy_authorization": {},
+	"cookie":              {},
+	"set_cookie":          {},
+	"token":               {},
+	"admin_token":         {},
+	"api_key":             {},
+	"apikey":              {},
+	"x_api_key":           {},
+	"x_auth_token":        {},
+	"password":            {},
+	"secret":              {},
+	"signing_key":         {},
+}
+
+// identifierKeys are normalized keys of attrs, that identify records
+// instead of wallets, so they are kept even if they look like addresses
+var identifierKeys = map[string]struct{}{
+	"request_id": {},
+	"trace_id":   {},
+	"span_id":    {},
+}
+
+var (
+	addressPattern     = regexp.MustCompile(`(?i)\b([0-9a-f]{8})-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	credentialsPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[^\s,;"]+`)
+)
+
+// redactHandler masks sensitive data of records
+type redactHandler struct {
+	slog.Handler
+}
+
+// NewRedactHandler wraps handler to mask sensitive data of records. Values of
+// attrs with secret keys (e.g. authorization or api_key) are replaced by
+// Redacted, bearer and basic credentials and wallet addresses in messages and
+// values are masked, addresses keep their first 8 digits to correlate records
+func NewRedactHandler(handler slog.Handler) slog.Handler {
+	return redactHandler{Handler: handler}
+}
+
+func (h redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, RedactString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redacted = append(redacted, redactAttr(attr))
+	}
+	return redactHandler{Handler: h.Handler.WithAttrs(redacted)}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// RedactString masks credentials and wallet addresses in s
+func RedactString(s string) string {
+	s = credentialsPattern.ReplaceAllString(s, "$1 "+Redacted)
+	return addressPattern.ReplaceAllString(s, "$1-****")
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	key := normalizeKey(attr.Key)
+	if _, ok := identifierKeys[key]; ok {
+		return attr
+	}
+	if _, ok := sensitiveKeys[key]; ok {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, 0, len(group))
+		for _, inner := range group {
+			redacted = append(redacted, redactAttr(inner))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		return slog.Any(attr.Key, redactAny(value.Any()))
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
+
+// redactAny redacts values, that are logged by request
+// logger and handlers: errors, params and headers
+func redactAny(value any) any {
+	switch v := value.(type) {
+	case error:
+		return RedactString(v.Error())
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = RedactString(s)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for key, s := range v {
+			redacted[key] = redactAttr(slog.String(key, s)).Value.String()
+		}
+		return redacted
+	case http.Header:
+		return redactAny(map[string][]string(v))
+	case map[string][]string:
+		redacted := make(map[string][]string, len(v))
+		for key, values := range v {
+			if _, ok := sensitiveKeys[normalizeKey(key)]; ok {
+				redacted[key] = []string{Redacted}
+				continue
+			}
+			redacted[key] = redactAny(values).([]string)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// normalizeKey makes header names and attr keys comparable, e.g. X-Api-Key and x_api_key
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
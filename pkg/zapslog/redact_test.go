@@ -0,0 +1,64 @@
+package zapslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lunn06/wallet/pkg/zapslog"
+)
+
+const address = "3f2a1b4c-0d6e-4f8a-9b1c-2d3e4f5a6b7c"
+
+func TestRedactHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(zapslog.NewRedactHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.With("api_key", "key-1").Info(
+		"wallet "+address+" not found",
+		"request_id", address,
+		"path", "/api/v1/wallet/"+address+"/balance",
+		"err", errors.New("lack of currency on "+address),
+		"params", map[string]string{"address": address},
+		slog.Group("request",
+			"header", http.Header{"Authorization": {"Bearer secret-token"}, "X-Api-Key": {"key-2"}, "Accept": {"*/*"}},
+			"note", "token Basic dXNlcjpwYXNz, ok",
+		),
+		"amount", 10,
+	)
+
+	var record struct {
+		Msg       string            `json:"msg"`
+		APIKey    string            `json:"api_key"`
+		RequestID string            `json:"request_id"`
+		Path      string            `json:"path"`
+		Err       string            `json:"err"`
+		Params    map[string]string `json:"params"`
+		Request   struct {
+			Header map[string][]string `json:"header"`
+			Note   string              `json:"note"`
+		} `json:"request"`
+		Amount int `json:"amount"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Equal(t, "wallet 3f2a1b4c-**** not found", record.Msg)
+	assert.Equal(t, zapslog.Redacted, record.APIKey)
+	assert.Equal(t, address, record.RequestID, "identifiers of records are kept")
+	assert.Equal(t, "/api/v1/wallet/3f2a1b4c-****/balance", record.Path)
+	assert.Equal(t, "lack of currency on 3f2a1b4c-****", record.Err)
+	assert.Equal(t, map[string]string{"address": "3f2a1b4c-****"}, record.Params)
+	assert.Equal(t, []string{zapslog.Redacted}, record.Request.Header["Authorization"])
+	assert.Equal(t, []string{zapslog.Redacted}, record.Request.Header["X-Api-Key"])
+	assert.Equal(t, []string{"*/*"}, record.Request.Header["Accept"])
+	assert.Equal(t, "token Basic [REDACTED], ok", record.Request.Note)
+	assert.Equal(t, 10, record.Amount)
+	assert.NotContains(t, buf.String(), "secret-token")
+	assert.NotContains(t, buf.String(), "key-1")
+}